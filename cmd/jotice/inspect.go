@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/ioc"
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+	"github.com/JrMarcco/jotice/internal/repository"
+	"github.com/JrMarcco/jotice/internal/repository/dao"
+	"github.com/JrMarcco/jotice/internal/service/notification"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+)
+
+// inspect decodes the notification ids and prints the notifications fetched from their shards.
+//
+//	jotice inspect [--config config/config.yaml] <id>...
+func inspect(args []string) {
+	flags := pflag.NewFlagSet("inspect", pflag.ExitOnError)
+	configFile := flags.String("config", "config/config.yaml", "Specify config file path")
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		_, _ = fmt.Fprintln(os.Stderr, "usage: jotice inspect [--config config/config.yaml] <id>...")
+		os.Exit(2)
+	}
+
+	ids := make([]uint64, 0, flags.NArg())
+	for _, arg := range flags.Args() {
+		id, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "invalid id %q: %v\n", arg, err)
+			os.Exit(2)
+		}
+		ids = append(ids, id)
+	}
+
+	readConfig(*configFile)
+
+	svc := initInspectService()
+	failed := false
+	for _, id := range ids {
+		res, err := svc.Inspect(context.Background(), id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "inspect %d failed: %v\n", id, err)
+			failed = true
+			continue
+		}
+		printInspection(os.Stdout, res)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// initInspectService creates the notification service reading the sharding dbs only.
+func initInspectService() *notification.DefaultNotifService {
+	cfgs := ioc.LoadShardingConfigs()
+	notifCfg, ok := cfgs["notification"]
+	if !ok {
+		_, _ = fmt.Fprintln(os.Stderr, "sharding.notification is not configured")
+		os.Exit(1)
+	}
+
+	notifStrategy, err := notifCfg.Strategy()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "invalid sharding.notification: %v\n", err)
+		os.Exit(1)
+	}
+	cbLogStrategy, err := cfgs["callback_log"].Strategy()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "invalid sharding.callback_log: %v\n", err)
+		os.Exit(1)
+	}
+
+	// the id generator is never used, nothing is created.
+	notifDAO := dao.NewNotifShardingDAO(
+		ioc.InitShardingDBs(), notifStrategy, cbLogStrategy, snowflake.NewGenerator(), zap.NewNop(),
+	)
+	repo := repository.NewNotificationRepo(notifDAO, zap.NewNop())
+	return notification.NewDefaultNotifService(repo, notifStrategy)
+}
+
+func printInspection(w io.Writer, res domain.IdInspection) {
+	_, _ = fmt.Fprintf(w, "id:         %d\n", res.Id)
+	_, _ = fmt.Fprintf(w, "created at: %s\n", res.CreatedAt.Format(time.RFC3339Nano))
+	_, _ = fmt.Fprintf(w, "hash:       %d\n", res.Hash)
+	_, _ = fmt.Fprintf(w, "worker id:  %d\n", res.WorkerId)
+	_, _ = fmt.Fprintf(w, "sequence:   %d\n", res.Sequence)
+	_, _ = fmt.Fprintf(w, "shard:      %s.%s\n", res.DB, res.Table)
+
+	if !res.Found {
+		_, _ = fmt.Fprintln(w, "row:        not found")
+		_, _ = fmt.Fprintln(w)
+		return
+	}
+
+	row, err := json.MarshalIndent(res.Notification, "", "  ")
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "row:        failed to marshal: %v\n", err)
+		_, _ = fmt.Fprintln(w)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "row:\n%s\n\n", row)
+}
@@ -1,12 +1,25 @@
 package main
 
 import (
+	"os"
+
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"go.uber.org/fx"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			migrate(os.Args[2:])
+			return
+		case "inspect":
+			inspect(os.Args[2:])
+			return
+		}
+	}
+
 	// init config
 	initViper()
 
@@ -17,7 +30,11 @@ func initViper() {
 	configFile := pflag.String("config", "config/config.yaml", "Specify config file path")
 	pflag.Parse()
 
-	viper.SetConfigFile(*configFile)
+	readConfig(*configFile)
+}
+
+func readConfig(configFile string) {
+	viper.SetConfigFile(configFile)
 	if err := viper.ReadInConfig(); err != nil {
 		panic(err)
 	}
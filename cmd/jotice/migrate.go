@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/JrMarcco/jotice/internal/ioc"
+	pkgschema "github.com/JrMarcco/jotice/internal/pkg/schema"
+	"github.com/JrMarcco/jotice/internal/repository/schema"
+	"github.com/spf13/pflag"
+	"gorm.io/gorm"
+)
+
+// defaultDB is the name of the db configured by db.postgres, which holds the tables not sharded.
+const defaultDB = "default"
+
+// migrate creates or upgrades all the tables, the sharded tables are created in every sharding db by the sharding config.
+//
+//	jotice migrate [--config config/config.yaml] [--dry-run]
+func migrate(args []string) {
+	flags := pflag.NewFlagSet("migrate", pflag.ExitOnError)
+	configFile := flags.String("config", "config/config.yaml", "Specify config file path")
+	dryRun := flags.Bool("dry-run", false, "Print the sql of the pending migrations instead of executing it")
+	_ = flags.Parse(args)
+
+	readConfig(*configFile)
+
+	if err := runMigrate(context.Background(), *dryRun); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "migrate failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runMigrate(ctx context.Context, dryRun bool) error {
+	migrations, err := schema.Migrations()
+	if err != nil {
+		return fmt.Errorf("failed to load migrations, cause of: %w", err)
+	}
+
+	targets, err := migrateTargets(migrations, ioc.LoadShardingConfigs())
+	if err != nil {
+		return err
+	}
+
+	dbNames := make([]string, 0, len(targets))
+	for name := range targets {
+		dbNames = append(dbNames, name)
+	}
+	slices.Sort(dbNames)
+
+	dbs := ioc.InitShardingDBs()
+	migrator := pkgschema.NewMigrator(migrations, dryRun, os.Stdout)
+	for _, name := range dbNames {
+		var db *gorm.DB
+		if name == defaultDB {
+			db = ioc.InitDB()
+		} else {
+			var ok bool
+			if db, ok = dbs.Load(name); !ok {
+				return fmt.Errorf("sharding db %s is not configured in db.shards", name)
+			}
+		}
+
+		if err = migrator.Migrate(ctx, db, targets[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateTargets returns the physical tables of each db to migrate.
+// Each db of a sharded table also has the template table named as the table prefix, e.g. notification,
+// which the tables created later are like, see sharding.TableCreateTask.
+func migrateTargets(
+	migrations []pkgschema.Migration, shardingConfigs map[string]ioc.ShardingConfig,
+) (map[string][]pkgschema.Target, error) {
+	var logicals []string
+	for _, m := range migrations {
+		if !slices.Contains(logicals, m.Table) {
+			logicals = append(logicals, m.Table)
+		}
+	}
+
+	targets := make(map[string][]pkgschema.Target)
+	for _, logical := range logicals {
+		cfg, ok := shardingConfigs[logical]
+		if !ok {
+			targets[defaultDB] = append(targets[defaultDB], pkgschema.Target{
+				DB:      defaultDB,
+				Logical: logical,
+				Table:   logical,
+			})
+			continue
+		}
+
+		// the monthly tables of time_range are created up to the upcoming months, the later ones by the task.
+		strategy, err := cfg.Strategy()
+		if err != nil {
+			return nil, err
+		}
+		for _, dst := range strategy.BroadCast() {
+			if !slices.ContainsFunc(targets[dst.DB], func(target pkgschema.Target) bool {
+				return target.Table == cfg.TablePrefix
+			}) {
+				targets[dst.DB] = append(targets[dst.DB], pkgschema.Target{
+					DB:      dst.DB,
+					Logical: logical,
+					Table:   cfg.TablePrefix,
+				})
+			}
+
+			targets[dst.DB] = append(targets[dst.DB], pkgschema.Target{
+				DB:      dst.DB,
+				Logical: logical,
+				Table:   dst.Table,
+			})
+		}
+	}
+	return targets, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/ioc"
+	pkgschema "github.com/JrMarcco/jotice/internal/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateTargets(t *testing.T) {
+	t.Parallel()
+
+	migrations := []pkgschema.Migration{
+		{Version: 1, Table: "biz_config"},
+		{Version: 1, Table: "notification"},
+		{Version: 2, Table: "notification"},
+	}
+	targets, err := migrateTargets(migrations, map[string]ioc.ShardingConfig{
+		"notification": {DBPrefix: "jotice", TablePrefix: "notification", DBSharding: 2, TableSharding: 2},
+	})
+	require.NoError(t, err)
+
+	// the tables not sharded are in the default db.
+	assert.Equal(t, []pkgschema.Target{{DB: defaultDB, Logical: "biz_config", Table: "biz_config"}}, targets[defaultDB])
+
+	// each sharding db has the template table and its sharding tables.
+	for _, db := range []string{"jotice_0", "jotice_1"} {
+		tables := make([]string, 0, len(targets[db]))
+		for _, target := range targets[db] {
+			assert.Equal(t, "notification", target.Logical)
+			tables = append(tables, target.Table)
+		}
+		assert.Equal(t, []string{"notification", "notification_0", "notification_1"}, tables)
+	}
+}
+
+func TestMigrateTargets_Types(t *testing.T) {
+	t.Parallel()
+
+	migrations := []pkgschema.Migration{
+		{Version: 1, Table: "notification"},
+		{Version: 1, Table: "callback_log"},
+	}
+	now := time.Now().UTC()
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	targets, err := migrateTargets(migrations, map[string]ioc.ShardingConfig{
+		"notification": {
+			Type: ioc.ShardingTypeTimeRange, DBPrefix: "jotice", TablePrefix: "notification", DBSharding: 2,
+			Since: since.Format("2006-01"), AheadMonths: 1,
+		},
+		"callback_log": {
+			Type: ioc.ShardingTypeConsistentHash, DBPrefix: "jotice", TablePrefix: "callback_log", TableSharding: 2,
+			Nodes: []ioc.DBNodeConfig{{Suffix: 0, Weight: 1}, {Suffix: 1, Weight: 1}},
+		},
+	})
+	require.NoError(t, err)
+
+	// the monthly tables from the first month to the upcoming month, and the consistent hash tables of each node.
+	want := []string{"notification"}
+	for i := range 3 {
+		want = append(want, "notification_"+since.AddDate(0, i, 0).Format("200601"))
+	}
+	want = append(want, "callback_log", "callback_log_0", "callback_log_1")
+	for _, db := range []string{"jotice_0", "jotice_1"} {
+		tables := make([]string, 0, len(targets[db]))
+		for _, target := range targets[db] {
+			tables = append(tables, target.Table)
+		}
+		assert.Equal(t, want, tables)
+	}
+
+	_, err = migrateTargets(migrations, map[string]ioc.ShardingConfig{
+		"notification": {Type: "range", DBPrefix: "jotice", TablePrefix: "notification"},
+	})
+	assert.Error(t, err)
+}
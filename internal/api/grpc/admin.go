@@ -0,0 +1,383 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	adminv1 "github.com/JrMarcco/jotice/api/gen/admin/v1"
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/service/channel"
+	"github.com/JrMarcco/jotice/internal/service/config"
+	nsvc "github.com/JrMarcco/jotice/internal/service/notification"
+	"github.com/JrMarcco/jotice/internal/service/notification/callback"
+	"github.com/JrMarcco/jotice/internal/service/quota"
+)
+
+var _ adminv1.AdminServiceServer = (*AdminServer)(nil)
+
+// AdminServer serves the admin apis defined in api/proto/admin, which are for the operators only.
+type AdminServer struct {
+	adminv1.UnimplementedAdminServiceServer
+
+	notifSvc      nsvc.Service
+	callbackSvc   callback.Service
+	configSvc     config.Service
+	quotaSvc      quota.Service
+	usageReporter channel.UsageReporter
+}
+
+func (s *AdminServer) ListFailedCallbacks(
+	ctx context.Context, req *adminv1.ListFailedCallbacksRequest,
+) (*adminv1.ListFailedCallbacksResponse, error) {
+	logs, err := s.callbackSvc.ListFailedCallbacks(
+		ctx, req.BizId, req.StartTime, req.EndTime, int(req.Offset), int(req.Limit),
+	)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+
+	resp := &adminv1.ListFailedCallbacksResponse{Logs: make([]*adminv1.CallbackLog, 0, len(logs))}
+	for _, log := range logs {
+		resp.Logs = append(resp.Logs, toApiCallbackLog(log))
+	}
+	return resp, nil
+}
+
+func (s *AdminServer) GetCallbackLog(
+	ctx context.Context, req *adminv1.GetCallbackLogRequest,
+) (*adminv1.GetCallbackLogResponse, error) {
+	log, err := s.callbackSvc.GetCallbackLog(ctx, req.Id)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+	return &adminv1.GetCallbackLogResponse{Log: toApiCallbackLog(log)}, nil
+}
+
+func (s *AdminServer) ReplayCallbacks(
+	ctx context.Context, req *adminv1.ReplayCallbacksRequest,
+) (*adminv1.ReplayCallbacksResponse, error) {
+	replayed, err := s.callbackSvc.Replay(ctx, req.Ids...)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+	return &adminv1.ReplayCallbacksResponse{Replayed: replayed}, nil
+}
+
+func (s *AdminServer) ReplayFailedCallbacks(
+	ctx context.Context, req *adminv1.ReplayFailedCallbacksRequest,
+) (*adminv1.ReplayFailedCallbacksResponse, error) {
+	replayed, err := s.callbackSvc.ReplayFailed(ctx, req.BizId, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+	return &adminv1.ReplayFailedCallbacksResponse{Replayed: replayed}, nil
+}
+
+func toApiCallbackLog(log domain.CallbackLog) *adminv1.CallbackLog {
+	attempts := make([]*adminv1.CallbackAttempt, 0, len(log.Attempts))
+	for _, attempt := range log.Attempts {
+		attempts = append(attempts, &adminv1.CallbackAttempt{
+			RetryTimes: attempt.RetryTimes,
+			AttemptAt:  attempt.AttemptAt,
+			Succeed:    attempt.Succeed,
+			Error:      attempt.Error,
+		})
+	}
+
+	return &adminv1.CallbackLog{
+		Id:             log.Id,
+		NotificationId: log.Notification.Id,
+		BizId:          log.Notification.BizId,
+		BizKey:         log.Notification.BizKey,
+		Status:         log.Status.String(),
+		RetryTimes:     log.RetryTimes,
+		NextRetryAt:    log.NextRetryAt,
+		LastError:      log.LastError,
+		Attempts:       attempts,
+		CreateAt:       log.CreateAt,
+		UpdateAt:       log.UpdateAt,
+	}
+}
+
+func (s *AdminServer) GetBizConfig(
+	ctx context.Context, req *adminv1.GetBizConfigRequest,
+) (*adminv1.GetBizConfigResponse, error) {
+	bc, err := s.configSvc.GetById(ctx, req.BizId)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+
+	apiBc, err := toApiBizConfig(bc)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+	return &adminv1.GetBizConfigResponse{Config: apiBc}, nil
+}
+
+func (s *AdminServer) SaveBizConfig(
+	ctx context.Context, req *adminv1.SaveBizConfigRequest,
+) (*adminv1.SaveBizConfigResponse, error) {
+	if req.Config == nil {
+		return nil, toStatusErr(fmt.Errorf("%w: config should not be empty", errs.ErrInvalidParam))
+	}
+
+	bc, err := toDomainBizConfig(req.Config)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+
+	revision, err := s.configSvc.Save(ctx, bc, req.Author)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+	return &adminv1.SaveBizConfigResponse{Version: revision.Version}, nil
+}
+
+func (s *AdminServer) DeleteBizConfig(
+	ctx context.Context, req *adminv1.DeleteBizConfigRequest,
+) (*adminv1.DeleteBizConfigResponse, error) {
+	if err := s.configSvc.Delete(ctx, req.BizId); err != nil {
+		return nil, toStatusErr(err)
+	}
+	return &adminv1.DeleteBizConfigResponse{}, nil
+}
+
+func (s *AdminServer) ListBizConfigRevisions(
+	ctx context.Context, req *adminv1.ListBizConfigRevisionsRequest,
+) (*adminv1.ListBizConfigRevisionsResponse, error) {
+	revisions, err := s.configSvc.ListRevisions(ctx, req.BizId, int(req.Offset), int(req.Limit))
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+
+	resp := &adminv1.ListBizConfigRevisionsResponse{Revisions: make([]*adminv1.BizConfigRevision, 0, len(revisions))}
+	for _, revision := range revisions {
+		bc, err := toApiBizConfig(revision.Config)
+		if err != nil {
+			return nil, toStatusErr(err)
+		}
+
+		resp.Revisions = append(resp.Revisions, &adminv1.BizConfigRevision{
+			Id:       revision.Id,
+			BizId:    revision.BizId,
+			Version:  revision.Version,
+			Config:   bc,
+			Author:   revision.Author,
+			CreateAt: revision.CreateAt,
+		})
+	}
+	return resp, nil
+}
+
+func (s *AdminServer) DiffBizConfigRevisions(
+	ctx context.Context, req *adminv1.DiffBizConfigRevisionsRequest,
+) (*adminv1.DiffBizConfigRevisionsResponse, error) {
+	diffs, err := s.configSvc.Diff(ctx, req.BizId, req.FromVersion, req.ToVersion)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+
+	resp := &adminv1.DiffBizConfigRevisionsResponse{Diffs: make([]*adminv1.BizConfigDiff, 0, len(diffs))}
+	for _, diff := range diffs {
+		resp.Diffs = append(resp.Diffs, &adminv1.BizConfigDiff{Field: diff.Field, Old: diff.Old, New: diff.New})
+	}
+	return resp, nil
+}
+
+func (s *AdminServer) RollbackBizConfig(
+	ctx context.Context, req *adminv1.RollbackBizConfigRequest,
+) (*adminv1.RollbackBizConfigResponse, error) {
+	revision, err := s.configSvc.Rollback(ctx, req.BizId, req.Version, req.Author)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+	return &adminv1.RollbackBizConfigResponse{Version: revision.Version}, nil
+}
+
+func (s *AdminServer) GetQuotaReport(
+	ctx context.Context, req *adminv1.GetQuotaReportRequest,
+) (*adminv1.GetQuotaReportResponse, error) {
+	reports, err := s.quotaSvc.GetReport(ctx, req.BizId)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+
+	resp := &adminv1.GetQuotaReportResponse{Reports: make([]*adminv1.QuotaReport, 0, len(reports))}
+	for _, report := range reports {
+		resp.Reports = append(resp.Reports, &adminv1.QuotaReport{
+			BizId:            report.BizId,
+			Channel:          string(report.Channel),
+			DailyLimit:       report.DailyLimit,
+			DailyUsed:        report.DailyUsed,
+			DailyRemaining:   report.DailyRemaining,
+			MonthlyLimit:     report.MonthlyLimit,
+			MonthlyUsed:      report.MonthlyUsed,
+			MonthlyRemaining: report.MonthlyRemaining,
+		})
+	}
+	return resp, nil
+}
+
+func (s *AdminServer) ListQuotaDailyUsages(
+	ctx context.Context, req *adminv1.ListQuotaDailyUsagesRequest,
+) (*adminv1.ListQuotaDailyUsagesResponse, error) {
+	month, err := time.ParseInLocation("2006-01", req.Month, time.Local)
+	if err != nil {
+		return nil, toStatusErr(fmt.Errorf("%w: invalid month, cause of: %w", errs.ErrInvalidParam, err))
+	}
+
+	usages, err := s.quotaSvc.ListDailyUsages(ctx, req.BizId, month)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+
+	resp := &adminv1.ListQuotaDailyUsagesResponse{Usages: make([]*adminv1.QuotaUsage, 0, len(usages))}
+	for _, usage := range usages {
+		resp.Usages = append(resp.Usages, &adminv1.QuotaUsage{
+			BizId:   usage.BizId,
+			Channel: string(usage.Channel),
+			Period:  usage.Period,
+			Used:    usage.Used,
+		})
+	}
+	return resp, nil
+}
+
+func (s *AdminServer) ListProviderUsages(
+	ctx context.Context, _ *adminv1.ListProviderUsagesRequest,
+) (*adminv1.ListProviderUsagesResponse, error) {
+	usages, err := s.usageReporter.ProviderUsages(ctx)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+
+	resp := &adminv1.ListProviderUsagesResponse{Usages: make([]*adminv1.ProviderUsage, 0, len(usages))}
+	for _, usage := range usages {
+		resp.Usages = append(resp.Usages, &adminv1.ProviderUsage{
+			ProviderId:     usage.ProviderId,
+			QpsLimit:       usage.QpsLimit,
+			DailyLimit:     usage.DailyLimit,
+			DailyUsed:      usage.DailyUsed,
+			DailyThrottled: usage.DailyThrottled,
+		})
+	}
+	return resp, nil
+}
+
+func (s *AdminServer) InspectNotificationId(
+	ctx context.Context, req *adminv1.InspectNotificationIdRequest,
+) (*adminv1.InspectNotificationIdResponse, error) {
+	res, err := s.notifSvc.Inspect(ctx, req.Id)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+
+	resp := &adminv1.InspectNotificationIdResponse{
+		Id:        res.Id,
+		CreatedAt: res.CreatedAt.UnixMilli(),
+		Hash:      res.Hash,
+		WorkerId:  res.WorkerId,
+		Sequence:  res.Sequence,
+		Db:        res.DB,
+		Table:     res.Table,
+		Found:     res.Found,
+	}
+	if res.Found {
+		resp.BizId = res.Notification.BizId
+		resp.Notification = toApiNotification(res.Notification)
+	}
+	return resp, nil
+}
+
+func toApiBizConfig(bc domain.BizConfig) (*adminv1.BizConfig, error) {
+	res := &adminv1.BizConfig{
+		Id:        bc.Id,
+		OwnerId:   bc.OwnerId,
+		OwnerType: bc.OwnerType,
+		RateLimit: bc.RateLimit,
+		CreateAt:  bc.CreateAt,
+		UpdateAt:  bc.UpdateAt,
+	}
+
+	var err error
+	if res.ChannelConfig, err = marshalSubConfig(bc.ChannelConfig); err != nil {
+		return nil, err
+	}
+	if res.TxNotifConfig, err = marshalSubConfig(bc.TxNotifConfig); err != nil {
+		return nil, err
+	}
+	if res.QuotaConfig, err = marshalSubConfig(bc.QuotaConfig); err != nil {
+		return nil, err
+	}
+	if res.CallbackConfig, err = marshalSubConfig(bc.CallbackConfig); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func toDomainBizConfig(bc *adminv1.BizConfig) (domain.BizConfig, error) {
+	res := domain.BizConfig{
+		Id:        bc.Id,
+		OwnerId:   bc.OwnerId,
+		OwnerType: bc.OwnerType,
+		RateLimit: bc.RateLimit,
+	}
+
+	var err error
+	if res.ChannelConfig, err = unmarshalSubConfig[domain.ChannelConfig]("channel config", bc.ChannelConfig); err != nil {
+		return domain.BizConfig{}, err
+	}
+	if res.TxNotifConfig, err = unmarshalSubConfig[domain.TxNotifConfig]("tx notif config", bc.TxNotifConfig); err != nil {
+		return domain.BizConfig{}, err
+	}
+	if res.QuotaConfig, err = unmarshalSubConfig[domain.QuotaConfig]("quota config", bc.QuotaConfig); err != nil {
+		return domain.BizConfig{}, err
+	}
+	res.CallbackConfig, err = unmarshalSubConfig[domain.CallbackConfig]("callback config", bc.CallbackConfig)
+	if err != nil {
+		return domain.BizConfig{}, err
+	}
+	return res, nil
+}
+
+// marshalSubConfig returns the json of the sub config, empty if the sub config is not configured.
+func marshalSubConfig[T any](subConfig *T) (string, error) {
+	if subConfig == nil {
+		return "", nil
+	}
+
+	jsonBytes, err := json.Marshal(subConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal sub config, cause of: %w", err)
+	}
+	return string(jsonBytes), nil
+}
+
+// unmarshalSubConfig returns nil if the json is empty, and errs.ErrInvalidParam if the json is invalid.
+func unmarshalSubConfig[T any](name, val string) (*T, error) {
+	if val == "" {
+		return nil, nil
+	}
+
+	var subConfig T
+	if err := json.Unmarshal([]byte(val), &subConfig); err != nil {
+		return nil, fmt.Errorf("%w: invalid %s, cause of: %w", errs.ErrInvalidParam, name, err)
+	}
+	return &subConfig, nil
+}
+
+func NewAdminServer(
+	notifSvc nsvc.Service, callbackSvc callback.Service, configSvc config.Service,
+	quotaSvc quota.Service, usageReporter channel.UsageReporter,
+) *AdminServer {
+	return &AdminServer{
+		notifSvc:      notifSvc,
+		callbackSvc:   callbackSvc,
+		configSvc:     configSvc,
+		quotaSvc:      quotaSvc,
+		usageReporter: usageReporter,
+	}
+}
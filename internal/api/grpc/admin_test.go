@@ -0,0 +1,348 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	adminv1 "github.com/JrMarcco/jotice/api/gen/admin/v1"
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/service/config"
+	"github.com/JrMarcco/jotice/internal/service/notification/callback"
+	"github.com/JrMarcco/jotice/internal/service/quota"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeCallbackSvc keeps the callback logs in memory, the send methods are not used.
+type fakeCallbackSvc struct {
+	callback.Service
+	logs map[uint64]domain.CallbackLog
+}
+
+func (f *fakeCallbackSvc) GetCallbackLog(_ context.Context, id uint64) (domain.CallbackLog, error) {
+	log, ok := f.logs[id]
+	if !ok {
+		return domain.CallbackLog{}, fmt.Errorf("%w: id = %d", errs.ErrCallbackLogNotFound, id)
+	}
+	return log, nil
+}
+
+func (f *fakeCallbackSvc) ListFailedCallbacks(
+	_ context.Context, bizId uint64, startTime, endTime int64, _, _ int,
+) ([]domain.CallbackLog, error) {
+	if startTime >= endTime {
+		return nil, fmt.Errorf("%w: start time should be before end time", errs.ErrInvalidParam)
+	}
+
+	var res []domain.CallbackLog
+	for _, log := range f.logs {
+		if log.Notification.BizId == bizId && log.Status == domain.CallbackStatusFailed {
+			res = append(res, log)
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeCallbackSvc) Replay(_ context.Context, ids ...uint64) (int64, error) {
+	var replayed int64
+	for _, id := range ids {
+		if log, ok := f.logs[id]; ok && log.Status == domain.CallbackStatusFailed {
+			replayed++
+		}
+	}
+	return replayed, nil
+}
+
+func TestAdminServer_Callbacks(t *testing.T) {
+	t.Parallel()
+
+	svr := NewAdminServer(nil, &fakeCallbackSvc{
+		logs: map[uint64]domain.CallbackLog{
+			1: {
+				Id:           1,
+				Notification: domain.Notification{Id: 10, BizId: 1, BizKey: "failed"},
+				Status:       domain.CallbackStatusFailed,
+				RetryTimes:   3,
+				LastError:    "timeout",
+				Attempts:     []domain.CallbackAttempt{{RetryTimes: 3, AttemptAt: 100, Error: "timeout"}},
+			},
+			2: {
+				Id:           2,
+				Notification: domain.Notification{Id: 20, BizId: 1, BizKey: "succeed"},
+				Status:       domain.CallbackStatusSucceed,
+			},
+		},
+	}, nil, nil, nil)
+
+	listResp, err := svr.ListFailedCallbacks(t.Context(), &adminv1.ListFailedCallbacksRequest{
+		BizId: 1, StartTime: 0, EndTime: 200, Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, listResp.Logs, 1)
+	log := listResp.Logs[0]
+	assert.Equal(t, uint64(1), log.Id)
+	assert.Equal(t, uint64(10), log.NotificationId)
+	assert.Equal(t, "failed", log.Status)
+	assert.Equal(t, "timeout", log.LastError)
+	require.Len(t, log.Attempts, 1)
+	assert.Equal(t, int64(100), log.Attempts[0].AttemptAt)
+
+	_, err = svr.ListFailedCallbacks(t.Context(), &adminv1.ListFailedCallbacksRequest{BizId: 1, StartTime: 200})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	getResp, err := svr.GetCallbackLog(t.Context(), &adminv1.GetCallbackLogRequest{Id: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "succeed", getResp.Log.Status)
+
+	_, err = svr.GetCallbackLog(t.Context(), &adminv1.GetCallbackLogRequest{Id: 3})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	replayResp, err := svr.ReplayCallbacks(t.Context(), &adminv1.ReplayCallbacksRequest{Ids: []uint64{1, 2}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), replayResp.Replayed)
+}
+
+// fakeConfigSvc keeps the biz configs and their revisions in memory.
+type fakeConfigSvc struct {
+	config.Service
+	configs   map[uint64]domain.BizConfig
+	revisions []domain.BizConfigRevision
+}
+
+func (f *fakeConfigSvc) GetById(_ context.Context, id uint64) (domain.BizConfig, error) {
+	bc, ok := f.configs[id]
+	if !ok {
+		return domain.BizConfig{}, fmt.Errorf("%w: id = %d", errs.ErrBizConfigNotFound, id)
+	}
+	return bc, nil
+}
+
+func (f *fakeConfigSvc) Save(_ context.Context, bc domain.BizConfig, author string) (domain.BizConfigRevision, error) {
+	if err := bc.Validate(); err != nil {
+		return domain.BizConfigRevision{}, err
+	}
+
+	f.configs[bc.Id] = bc
+	revision := domain.BizConfigRevision{
+		BizId: bc.Id, Version: int32(len(f.revisions) + 1), Config: bc, Author: author,
+	}
+	f.revisions = append(f.revisions, revision)
+	return revision, nil
+}
+
+func (f *fakeConfigSvc) getRevision(version int32) (domain.BizConfigRevision, error) {
+	if version <= 0 || int(version) > len(f.revisions) {
+		return domain.BizConfigRevision{}, fmt.Errorf("%w: version = %d", errs.ErrBizConfigRevisionNotFound, version)
+	}
+	return f.revisions[version-1], nil
+}
+
+func (f *fakeConfigSvc) ListRevisions(context.Context, uint64, int, int) ([]domain.BizConfigRevision, error) {
+	res := make([]domain.BizConfigRevision, 0, len(f.revisions))
+	for i := len(f.revisions) - 1; i >= 0; i-- {
+		res = append(res, f.revisions[i])
+	}
+	return res, nil
+}
+
+func (f *fakeConfigSvc) Diff(
+	_ context.Context, _ uint64, fromVersion, toVersion int32,
+) ([]domain.BizConfigDiff, error) {
+	from, err := f.getRevision(fromVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := f.getRevision(toVersion)
+	if err != nil {
+		return nil, err
+	}
+	return from.Config.Diff(to.Config), nil
+}
+
+func (f *fakeConfigSvc) Rollback(
+	ctx context.Context, _ uint64, version int32, author string,
+) (domain.BizConfigRevision, error) {
+	target, err := f.getRevision(version)
+	if err != nil {
+		return domain.BizConfigRevision{}, err
+	}
+	return f.Save(ctx, target.Config, author)
+}
+
+func (f *fakeConfigSvc) Delete(_ context.Context, id uint64) error {
+	delete(f.configs, id)
+	return nil
+}
+
+func TestAdminServer_BizConfigs(t *testing.T) {
+	t.Parallel()
+
+	configSvc := &fakeConfigSvc{configs: map[uint64]domain.BizConfig{}}
+	svr := NewAdminServer(nil, nil, configSvc, nil, nil)
+
+	saveResp, err := svr.SaveBizConfig(t.Context(), &adminv1.SaveBizConfigRequest{
+		Config: &adminv1.BizConfig{
+			Id:          1,
+			OwnerId:     10,
+			OwnerType:   string(domain.OwnerTypePerson),
+			RateLimit:   100,
+			QuotaConfig: `{"daily":{"sms":10}}`,
+		},
+		Author: "admin",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), saveResp.Version)
+
+	// the sub configs are converted from and to json, the not configured ones are empty
+	saved := configSvc.configs[1]
+	require.NotNil(t, saved.QuotaConfig)
+	require.NotNil(t, saved.QuotaConfig.Daily)
+	assert.Equal(t, int32(10), saved.QuotaConfig.Daily.SMS)
+	assert.Nil(t, saved.ChannelConfig)
+
+	getResp, err := svr.GetBizConfig(t.Context(), &adminv1.GetBizConfigRequest{BizId: 1})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), getResp.Config.OwnerId)
+	assert.JSONEq(t, `{"daily":{"sms":10,"email":0},"monthly":null,"alert_thresholds":null}`, getResp.Config.QuotaConfig)
+	assert.Empty(t, getResp.Config.ChannelConfig)
+
+	_, err = svr.SaveBizConfig(t.Context(), &adminv1.SaveBizConfigRequest{
+		Config: &adminv1.BizConfig{Id: 1, OwnerId: 10, OwnerType: "person", RateLimit: 100, QuotaConfig: "{"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = svr.SaveBizConfig(t.Context(), &adminv1.SaveBizConfigRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = svr.DeleteBizConfig(t.Context(), &adminv1.DeleteBizConfigRequest{BizId: 1})
+	require.NoError(t, err)
+
+	_, err = svr.GetBizConfig(t.Context(), &adminv1.GetBizConfigRequest{BizId: 1})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAdminServer_BizConfigRevisions(t *testing.T) {
+	t.Parallel()
+
+	configSvc := &fakeConfigSvc{configs: map[uint64]domain.BizConfig{}}
+	svr := NewAdminServer(nil, nil, configSvc, nil, nil)
+
+	for _, rateLimit := range []int32{100, 200} {
+		_, err := svr.SaveBizConfig(t.Context(), &adminv1.SaveBizConfigRequest{
+			Config: &adminv1.BizConfig{Id: 1, OwnerId: 10, OwnerType: "person", RateLimit: rateLimit},
+			Author: "admin",
+		})
+		require.NoError(t, err)
+	}
+
+	listResp, err := svr.ListBizConfigRevisions(t.Context(), &adminv1.ListBizConfigRevisionsRequest{BizId: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, listResp.Revisions, 2)
+	assert.Equal(t, int32(2), listResp.Revisions[0].Version)
+	assert.Equal(t, int32(200), listResp.Revisions[0].Config.RateLimit)
+	assert.Equal(t, "admin", listResp.Revisions[0].Author)
+
+	diffResp, err := svr.DiffBizConfigRevisions(t.Context(), &adminv1.DiffBizConfigRevisionsRequest{
+		BizId: 1, FromVersion: 1, ToVersion: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, diffResp.Diffs, 1)
+	assert.Equal(t, "rate_limit", diffResp.Diffs[0].Field)
+	assert.Equal(t, "100", diffResp.Diffs[0].Old)
+	assert.Equal(t, "200", diffResp.Diffs[0].New)
+
+	rollbackResp, err := svr.RollbackBizConfig(t.Context(), &adminv1.RollbackBizConfigRequest{
+		BizId: 1, Version: 1, Author: "operator",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), rollbackResp.Version)
+	assert.Equal(t, int32(100), configSvc.configs[1].RateLimit)
+
+	_, err = svr.RollbackBizConfig(t.Context(), &adminv1.RollbackBizConfigRequest{BizId: 1, Version: 9})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// fakeQuotaSvc reports the quota of biz 1 only, the deductions are not used.
+type fakeQuotaSvc struct {
+	quota.Service
+}
+
+func (f *fakeQuotaSvc) GetReport(_ context.Context, bizId uint64) ([]domain.QuotaReport, error) {
+	if bizId != 1 {
+		return nil, nil
+	}
+	return []domain.QuotaReport{
+		{BizId: 1, Channel: domain.ChannelSMS, DailyLimit: 100, DailyUsed: 40, DailyRemaining: 60},
+	}, nil
+}
+
+func (f *fakeQuotaSvc) ListDailyUsages(_ context.Context, bizId uint64, month time.Time) ([]domain.QuotaUsage, error) {
+	return []domain.QuotaUsage{
+		{BizId: bizId, Channel: domain.ChannelSMS, Period: domain.DailyQuotaPeriod(month), Used: 40},
+	}, nil
+}
+
+type fakeUsageReporter struct{}
+
+func (fakeUsageReporter) ProviderUsages(context.Context) ([]domain.ProviderUsage, error) {
+	return []domain.ProviderUsage{{ProviderId: 1, QpsLimit: 10, DailyLimit: 100, DailyUsed: 40, DailyThrottled: 2}}, nil
+}
+
+func TestAdminServer_Usages(t *testing.T) {
+	t.Parallel()
+
+	svr := NewAdminServer(nil, nil, nil, &fakeQuotaSvc{}, fakeUsageReporter{})
+
+	reportResp, err := svr.GetQuotaReport(t.Context(), &adminv1.GetQuotaReportRequest{BizId: 1})
+	require.NoError(t, err)
+	require.Len(t, reportResp.Reports, 1)
+	assert.Equal(t, string(domain.ChannelSMS), reportResp.Reports[0].Channel)
+	assert.Equal(t, int64(60), reportResp.Reports[0].DailyRemaining)
+
+	usagesResp, err := svr.ListQuotaDailyUsages(t.Context(), &adminv1.ListQuotaDailyUsagesRequest{
+		BizId: 1, Month: "2025-01",
+	})
+	require.NoError(t, err)
+	require.Len(t, usagesResp.Usages, 1)
+	assert.Equal(t, "d20250101", usagesResp.Usages[0].Period)
+
+	_, err = svr.ListQuotaDailyUsages(t.Context(), &adminv1.ListQuotaDailyUsagesRequest{BizId: 1, Month: "202501"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	providerResp, err := svr.ListProviderUsages(t.Context(), &adminv1.ListProviderUsagesRequest{})
+	require.NoError(t, err)
+	require.Len(t, providerResp.Usages, 1)
+	assert.Equal(t, int64(2), providerResp.Usages[0].DailyThrottled)
+}
+
+func TestAdminServer_InspectNotificationId(t *testing.T) {
+	t.Parallel()
+
+	svr := NewAdminServer(&fakeNotifSvc{notifs: map[uint64]domain.Notification{
+		1: {Id: 1, BizId: 2, BizKey: "a", Status: domain.SendStatusSuccess},
+	}}, nil, nil, nil, nil)
+
+	resp, err := svr.InspectNotificationId(t.Context(), &adminv1.InspectNotificationIdRequest{Id: 1})
+	require.NoError(t, err)
+	assert.True(t, resp.Found)
+	assert.Equal(t, "jotice_0", resp.Db)
+	assert.Equal(t, "notification_archive_0", resp.Table)
+	assert.Equal(t, uint64(2), resp.BizId)
+	assert.Equal(t, "a", resp.Notification.BizKey)
+
+	// the id not persisted is still decoded
+	resp, err = svr.InspectNotificationId(t.Context(), &adminv1.InspectNotificationIdRequest{Id: 3})
+	require.NoError(t, err)
+	assert.False(t, resp.Found)
+	assert.Equal(t, "notification_0", resp.Table)
+	assert.Nil(t, resp.Notification)
+
+	_, err = svr.InspectNotificationId(t.Context(), &adminv1.InspectNotificationIdRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,24 @@
+package grpc
+
+import (
+	"errors"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatusErr converts the error of the services to a grpc status error.
+func toStatusErr(err error) error {
+	switch {
+	case errors.Is(err, errs.ErrInvalidParam):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, errs.ErrNotificationNotFound),
+		errors.Is(err, errs.ErrCallbackLogNotFound),
+		errors.Is(err, errs.ErrBizConfigNotFound),
+		errors.Is(err, errs.ErrBizConfigRevisionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
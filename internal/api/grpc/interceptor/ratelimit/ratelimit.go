@@ -0,0 +1,163 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	notificationv1 "github.com/JrMarcco/jotice-api/api/notification/v1"
+	"github.com/JrMarcco/jotice/internal/api/grpc/interceptor/jwt"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/ratelimit"
+	"github.com/JrMarcco/jotice/internal/service/config"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const defaultBulkRatio = 0.8
+
+// batchRequest is the request of batch rpc, each notification of it is counted.
+type batchRequest interface {
+	GetNotifications() []*notificationv1.Notification
+}
+
+var (
+	_ batchRequest = (*notificationv1.BatchSendNotificationsRequest)(nil)
+	_ batchRequest = (*notificationv1.BatchSendNotificationsAsyncRequest)(nil)
+)
+
+// InterceptorBuilder builds a grpc interceptor limiting the requests of each biz to BizConfig.RateLimit per second.
+//
+// The batch and async requests are bulk requests, which only take up to bulkRatio of the rate limit,
+// so a runaway bulk job can not starve the single sends like verification codes.
+type InterceptorBuilder struct {
+	limiter   ratelimit.Limiter
+	configSvc config.Service
+	bulkRatio float64
+	burst     time.Duration
+	logger    *zap.Logger
+}
+
+func NewInterceptorBuilder(
+	limiter ratelimit.Limiter, configSvc config.Service, logger *zap.Logger,
+) *InterceptorBuilder {
+	return &InterceptorBuilder{
+		limiter:   limiter,
+		configSvc: configSvc,
+		bulkRatio: defaultBulkRatio,
+		burst:     ratelimit.DefaultBurst,
+		logger:    logger,
+	}
+}
+
+// BulkRatio sets the max ratio of the rate limit the bulk requests can take, in (0, 1].
+func (b *InterceptorBuilder) BulkRatio(ratio float64) *InterceptorBuilder {
+	if ratio > 0 && ratio <= 1 {
+		b.bulkRatio = ratio
+	}
+	return b
+}
+
+// Burst sets how long the tokens are accumulated for bursts, a batch larger than the burst is always rejected.
+func (b *InterceptorBuilder) Burst(burst time.Duration) *InterceptorBuilder {
+	if burst > 0 {
+		b.burst = burst
+	}
+	return b
+}
+
+// Build creates a grpc interceptor for rate limit, it should be chained after the jwt auth interceptor.
+func (b *InterceptorBuilder) Build() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		bizId, ok := ctx.Value(jwt.BizIdKey{}).(int64)
+		if !ok || bizId <= 0 {
+			return handler(ctx, req)
+		}
+
+		rules, err := b.rules(ctx, uint64(bizId), req, info)
+		if err != nil {
+			// fail open, the rate limit should not make the service unavailable.
+			b.logger.Warn("[jotice] failed to get rate limit rules", zap.Int64("biz_id", bizId), zap.Error(err))
+			return handler(ctx, req)
+		}
+
+		if len(rules) == 0 {
+			return handler(ctx, req)
+		}
+
+		res, err := b.limiter.Limit(ctx, cost(req), rules...)
+		if errors.Is(err, errs.ErrCostExceedsBurst) {
+			return nil, status.Errorf(codes.InvalidArgument, "too many notifications in one request: %s", err)
+		}
+		if err != nil {
+			b.logger.Warn("[jotice] failed to limit request", zap.Int64("biz_id", bizId), zap.Error(err))
+			return handler(ctx, req)
+		}
+
+		if !res.Allowed {
+			return nil, exhausted(res)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (b *InterceptorBuilder) rules(
+	ctx context.Context, bizId uint64, req any, info *grpc.UnaryServerInfo,
+) ([]ratelimit.Rule, error) {
+	bizConfig, err := b.configSvc.GetById(ctx, bizId)
+	if err != nil {
+		if errors.Is(err, errs.ErrBizConfigNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if bizConfig.RateLimit <= 0 {
+		return nil, nil
+	}
+
+	// the biz id is used as hash tag, so all the rules of a biz are in the same slot of a redis cluster.
+	rules := []ratelimit.Rule{{
+		Key:   fmt.Sprintf("{%d}:total", bizId),
+		Rate:  int64(bizConfig.RateLimit),
+		Burst: b.burst,
+	}}
+
+	if isBulk(req, info) {
+		rules = append(rules, ratelimit.Rule{
+			Key:   fmt.Sprintf("{%d}:bulk", bizId),
+			Rate:  max(int64(float64(bizConfig.RateLimit)*b.bulkRatio), 1),
+			Burst: b.burst,
+		})
+	}
+	return rules, nil
+}
+
+// cost returns the number of notifications of the request.
+func cost(req any) int64 {
+	if br, ok := req.(batchRequest); ok {
+		return max(int64(len(br.GetNotifications())), 1)
+	}
+	return 1
+}
+
+func isBulk(req any, info *grpc.UnaryServerInfo) bool {
+	if _, ok := req.(batchRequest); ok {
+		return true
+	}
+	return strings.Contains(info.FullMethod, "Async")
+}
+
+func exhausted(res ratelimit.Result) error {
+	st := status.Newf(codes.ResourceExhausted, "rate limit exceeded, retry after %s", res.RetryAfter)
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(res.RetryAfter)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
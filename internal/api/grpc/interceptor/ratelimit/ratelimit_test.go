@@ -0,0 +1,256 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	notificationv1 "github.com/JrMarcco/jotice-api/api/notification/v1"
+	"github.com/JrMarcco/jotice/internal/api/grpc/interceptor/jwt"
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/ratelimit"
+	"github.com/JrMarcco/jotice/internal/service/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeBatchRequest is a batch request with n notifications.
+type fakeBatchRequest struct {
+	n int
+}
+
+func (r fakeBatchRequest) GetNotifications() []*notificationv1.Notification {
+	return make([]*notificationv1.Notification, r.n)
+}
+
+// fakeConfigSvc returns the biz configs in memory, the other methods are not used.
+type fakeConfigSvc struct {
+	config.Service
+	configs map[uint64]domain.BizConfig
+	err     error
+}
+
+func (f *fakeConfigSvc) GetById(_ context.Context, id uint64) (domain.BizConfig, error) {
+	if f.err != nil {
+		return domain.BizConfig{}, f.err
+	}
+	bc, ok := f.configs[id]
+	if !ok {
+		return domain.BizConfig{}, errs.ErrBizConfigNotFound
+	}
+	return bc, nil
+}
+
+// fakeLimiter records the call and returns the given result.
+type fakeLimiter struct {
+	res ratelimit.Result
+	err error
+
+	cost  int64
+	rules []ratelimit.Rule
+}
+
+func (f *fakeLimiter) Limit(_ context.Context, cost int64, rules ...ratelimit.Rule) (ratelimit.Result, error) {
+	f.cost = cost
+	f.rules = rules
+	for _, rule := range rules {
+		if cost > rule.Capacity() {
+			return ratelimit.Result{}, fmt.Errorf("%w: %s", errs.ErrCostExceedsBurst, rule.Key)
+		}
+	}
+	return f.res, f.err
+}
+
+func TestCost(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, int64(1), cost(&notificationv1.SendNotificationRequest{}))
+	assert.Equal(t, int64(3), cost(fakeBatchRequest{n: 3}))
+	// an empty batch still costs one
+	assert.Equal(t, int64(1), cost(fakeBatchRequest{}))
+}
+
+func TestIsBulk(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name   string
+		req    any
+		method string
+		want   bool
+	}{
+		{
+			name:   "single send",
+			req:    &notificationv1.SendNotificationRequest{},
+			method: "/notification.v1.NotificationService/SendNotification",
+			want:   false,
+		}, {
+			name:   "async send",
+			req:    &notificationv1.SendNotificationRequest{},
+			method: "/notification.v1.NotificationService/SendNotificationAsync",
+			want:   true,
+		}, {
+			name:   "batch send",
+			req:    fakeBatchRequest{n: 2},
+			method: "/notification.v1.NotificationService/BatchSendNotifications",
+			want:   true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, isBulk(tc.req, &grpc.UnaryServerInfo{FullMethod: tc.method}))
+		})
+	}
+}
+
+func TestInterceptorBuilder_Build(t *testing.T) {
+	t.Parallel()
+
+	configSvc := &fakeConfigSvc{
+		configs: map[uint64]domain.BizConfig{
+			1: {Id: 1, RateLimit: 10},
+			// biz 2 is not limited
+			2: {Id: 2},
+		},
+	}
+
+	tcs := []struct {
+		name      string
+		bizId     int64
+		req       any
+		method    string
+		limiter   *fakeLimiter
+		configSvc *fakeConfigSvc
+		wantCode  codes.Code
+		wantRules []ratelimit.Rule
+		wantCost  int64
+		wantRetry time.Duration
+	}{
+		{
+			name:     "allowed",
+			bizId:    1,
+			req:      &notificationv1.SendNotificationRequest{},
+			method:   "/notification.v1.NotificationService/SendNotification",
+			limiter:  &fakeLimiter{res: ratelimit.Result{Allowed: true}},
+			wantCode: codes.OK,
+			wantRules: []ratelimit.Rule{
+				{Key: "{1}:total", Rate: 10, Burst: 2 * time.Second},
+			},
+			wantCost: 1,
+		}, {
+			name:     "bulk",
+			bizId:    1,
+			req:      fakeBatchRequest{n: 5},
+			method:   "/notification.v1.NotificationService/BatchSendNotifications",
+			limiter:  &fakeLimiter{res: ratelimit.Result{Allowed: true}},
+			wantCode: codes.OK,
+			wantRules: []ratelimit.Rule{
+				{Key: "{1}:total", Rate: 10, Burst: 2 * time.Second},
+				{Key: "{1}:bulk", Rate: 5, Burst: 2 * time.Second},
+			},
+			wantCost: 5,
+		}, {
+			name:      "denied",
+			bizId:     1,
+			req:       &notificationv1.SendNotificationRequest{},
+			method:    "/notification.v1.NotificationService/SendNotification",
+			limiter:   &fakeLimiter{res: ratelimit.Result{RetryAfter: 100 * time.Millisecond}},
+			wantCode:  codes.ResourceExhausted,
+			wantRetry: 100 * time.Millisecond,
+		}, {
+			// the bulk rule only holds 10 tokens in the 2s burst
+			name:     "cost exceeds burst",
+			bizId:    1,
+			req:      fakeBatchRequest{n: 11},
+			method:   "/notification.v1.NotificationService/BatchSendNotifications",
+			limiter:  &fakeLimiter{res: ratelimit.Result{Allowed: true}},
+			wantCode: codes.InvalidArgument,
+		}, {
+			name:     "fail open on limiter error",
+			bizId:    1,
+			req:      &notificationv1.SendNotificationRequest{},
+			method:   "/notification.v1.NotificationService/SendNotification",
+			limiter:  &fakeLimiter{err: errors.New("connection refused")},
+			wantCode: codes.OK,
+		}, {
+			name:      "fail open on config error",
+			bizId:     1,
+			req:       &notificationv1.SendNotificationRequest{},
+			method:    "/notification.v1.NotificationService/SendNotification",
+			limiter:   &fakeLimiter{},
+			configSvc: &fakeConfigSvc{err: errors.New("connection refused")},
+			wantCode:  codes.OK,
+		}, {
+			name:     "not limited",
+			bizId:    2,
+			req:      &notificationv1.SendNotificationRequest{},
+			method:   "/notification.v1.NotificationService/SendNotification",
+			limiter:  &fakeLimiter{},
+			wantCode: codes.OK,
+		}, {
+			name:     "no biz id",
+			req:      &notificationv1.SendNotificationRequest{},
+			method:   "/notification.v1.NotificationService/SendNotification",
+			limiter:  &fakeLimiter{},
+			wantCode: codes.OK,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := configSvc
+			if tc.configSvc != nil {
+				svc = tc.configSvc
+			}
+			interceptor := NewInterceptorBuilder(tc.limiter, svc, zap.NewNop()).
+				BulkRatio(0.5).
+				Burst(2 * time.Second).
+				Build()
+
+			ctx := t.Context()
+			if tc.bizId > 0 {
+				ctx = context.WithValue(ctx, jwt.BizIdKey{}, tc.bizId)
+			}
+
+			handled := false
+			_, err := interceptor(ctx, tc.req, &grpc.UnaryServerInfo{FullMethod: tc.method}, func(context.Context, any) (any, error) {
+				handled = true
+				return nil, nil
+			})
+
+			st, _ := status.FromError(err)
+			assert.Equal(t, tc.wantCode, st.Code())
+			assert.Equal(t, tc.wantCode == codes.OK, handled)
+
+			if tc.wantRules != nil {
+				assert.Equal(t, tc.wantRules, tc.limiter.rules)
+				assert.Equal(t, tc.wantCost, tc.limiter.cost)
+			}
+
+			var retryInfo *errdetails.RetryInfo
+			for _, detail := range st.Details() {
+				if ri, ok := detail.(*errdetails.RetryInfo); ok {
+					retryInfo = ri
+				}
+			}
+			if tc.wantRetry == 0 {
+				assert.Nil(t, retryInfo)
+				return
+			}
+			require.NotNil(t, retryInfo)
+			assert.Equal(t, tc.wantRetry, retryInfo.GetRetryDelay().AsDuration())
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	queryv1 "github.com/JrMarcco/jotice/api/gen/query/v1"
+	"github.com/JrMarcco/jotice/internal/api/grpc/interceptor/jwt"
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	nsvc "github.com/JrMarcco/jotice/internal/service/notification"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ queryv1.QueryServiceServer = (*QueryServer)(nil)
+
+// QueryServer serves the query apis defined in api/proto/query,
+// the biz can only query its own notifications, which is authenticated by the jwt auth interceptor.
+type QueryServer struct {
+	queryv1.UnimplementedQueryServiceServer
+
+	svc nsvc.Service
+}
+
+func (s *QueryServer) GetNotification(
+	ctx context.Context, req *queryv1.GetNotificationRequest,
+) (*queryv1.GetNotificationResponse, error) {
+	bizId, err := bizIdFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := s.svc.GetById(ctx, req.Id)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+	// the notification of the other biz is not found, so the existence of its id is not leaked.
+	if n.BizId != bizId {
+		return nil, toStatusErr(fmt.Errorf("%w: id = %d", errs.ErrNotificationNotFound, req.Id))
+	}
+	return &queryv1.GetNotificationResponse{Notification: toApiNotification(n)}, nil
+}
+
+func (s *QueryServer) BatchGetNotifications(
+	ctx context.Context, req *queryv1.BatchGetNotificationsRequest,
+) (*queryv1.BatchGetNotificationsResponse, error) {
+	bizId, err := bizIdFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ns, err := s.svc.BatchGetByIds(ctx, req.Ids...)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+
+	resp := &queryv1.BatchGetNotificationsResponse{Notifications: make(map[uint64]*queryv1.Notification, len(ns))}
+	for id, n := range ns {
+		if n.BizId == bizId {
+			resp.Notifications[id] = toApiNotification(n)
+		}
+	}
+	return resp, nil
+}
+
+// bizIdFromCtx returns the biz id set by the jwt auth interceptor.
+func bizIdFromCtx(ctx context.Context) (uint64, error) {
+	bizId, ok := ctx.Value(jwt.BizIdKey{}).(int64)
+	if !ok || bizId <= 0 {
+		return 0, status.Error(codes.Unauthenticated, "biz id is not authenticated")
+	}
+	return uint64(bizId), nil
+}
+
+func toApiNotification(n domain.Notification) *queryv1.Notification {
+	return &queryv1.Notification{
+		Id:                n.Id,
+		BizKey:            n.BizKey,
+		Receivers:         n.Receivers,
+		Channel:           n.Channel.String(),
+		TemplateId:        n.Template.Id,
+		TemplateVersionId: n.Template.VersionId,
+		TemplateParams:    n.Template.Params,
+		Status:            n.Status.String(),
+		ScheduledStart:    n.ScheduledStart.UnixMilli(),
+		ScheduledEnd:      n.ScheduledEnd.UnixMilli(),
+	}
+}
+
+func NewQueryServer(svc nsvc.Service) *QueryServer {
+	return &QueryServer{svc: svc}
+}
@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	queryv1 "github.com/JrMarcco/jotice/api/gen/query/v1"
+	"github.com/JrMarcco/jotice/internal/api/grpc/interceptor/jwt"
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	nsvc "github.com/JrMarcco/jotice/internal/service/notification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeNotifSvc keeps the notifications in memory, the other methods are not used.
+type fakeNotifSvc struct {
+	nsvc.Service
+	notifs map[uint64]domain.Notification
+}
+
+// Inspect reads the notifications from the archive table of notification_0.
+func (f *fakeNotifSvc) Inspect(ctx context.Context, id uint64) (domain.IdInspection, error) {
+	if id == 0 {
+		return domain.IdInspection{}, fmt.Errorf("%w: notification id should not be zero", errs.ErrInvalidParam)
+	}
+
+	res := domain.IdInspection{Id: id, DB: "jotice_0", Table: "notification_0"}
+	n, err := f.GetById(ctx, id)
+	if err == nil {
+		res.Table = "notification_archive_0"
+		res.Found = true
+		res.Notification = n
+	}
+	return res, nil
+}
+
+func (f *fakeNotifSvc) GetById(_ context.Context, id uint64) (domain.Notification, error) {
+	n, ok := f.notifs[id]
+	if !ok {
+		return domain.Notification{}, fmt.Errorf("%w: id = %d", errs.ErrNotificationNotFound, id)
+	}
+	return n, nil
+}
+
+func (f *fakeNotifSvc) BatchGetByIds(_ context.Context, ids ...uint64) (map[uint64]domain.Notification, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: notification ids should not be empty", errs.ErrInvalidParam)
+	}
+
+	res := make(map[uint64]domain.Notification, len(ids))
+	for _, id := range ids {
+		if n, ok := f.notifs[id]; ok {
+			res[id] = n
+		}
+	}
+	return res, nil
+}
+
+func TestQueryServer(t *testing.T) {
+	t.Parallel()
+
+	svr := NewQueryServer(&fakeNotifSvc{notifs: map[uint64]domain.Notification{
+		1: {
+			Id: 1, BizId: 1, BizKey: "a", Receivers: []string{"r"}, Channel: domain.ChannelSMS,
+			Template: domain.Template{Id: 2, VersionId: 3, Params: map[string]string{"k": "v"}},
+			Status:   domain.SendStatusSuccess,
+		},
+		2: {Id: 2, BizId: 1, BizKey: "b", Status: domain.SendStatusPending},
+		3: {Id: 3, BizId: 2, BizKey: "c", Status: domain.SendStatusPending},
+	}})
+	ctx := context.WithValue(t.Context(), jwt.BizIdKey{}, int64(1))
+
+	resp, err := svr.GetNotification(ctx, &queryv1.GetNotificationRequest{Id: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "a", resp.Notification.BizKey)
+	assert.Equal(t, "sms", resp.Notification.Channel)
+	assert.Equal(t, "success", resp.Notification.Status)
+	assert.Equal(t, uint64(3), resp.Notification.TemplateVersionId)
+	assert.Equal(t, map[string]string{"k": "v"}, resp.Notification.TemplateParams)
+
+	// the notification of the other biz is not found
+	_, err = svr.GetNotification(ctx, &queryv1.GetNotificationRequest{Id: 3})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = svr.GetNotification(ctx, &queryv1.GetNotificationRequest{Id: 4})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	batchResp, err := svr.BatchGetNotifications(ctx, &queryv1.BatchGetNotificationsRequest{Ids: []uint64{1, 2, 3, 4}})
+	require.NoError(t, err)
+	assert.Len(t, batchResp.Notifications, 2)
+	assert.Equal(t, "pending", batchResp.Notifications[2].Status)
+
+	_, err = svr.BatchGetNotifications(ctx, &queryv1.BatchGetNotificationsRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the biz id should be authenticated
+	_, err = svr.GetNotification(t.Context(), &queryv1.GetNotificationRequest{Id: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -6,6 +6,7 @@ import (
 	tsvc "github.com/JrMarcco/jotice/internal/service/template"
 )
 
+// NotificationServer serves the notification apis defined in jotice-api.
 type NotificationServer struct {
 	notificationv1.UnimplementedNotificationServiceServer
 	notificationv1.UnimplementedNotificationQueryServiceServer
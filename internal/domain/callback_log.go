@@ -1,5 +1,11 @@
 package domain
 
+import (
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/pkg/retry"
+)
+
 type CallbackStatus string
 
 const (
@@ -13,10 +19,70 @@ func (s CallbackStatus) String() string {
 	return string(s)
 }
 
+// maxCallbackAttempts is the max number of attempts kept in the history of a callback log.
+const maxCallbackAttempts = 16
+
 type CallbackLog struct {
 	Id           uint64
 	Notification Notification
 	RetryTimes   int32
 	NextRetryAt  int64
 	Status       CallbackStatus
+	LastError    string
+	Attempts     []CallbackAttempt
+	CreateAt     int64
+	UpdateAt     int64
+}
+
+// CallbackAttempt is a delivery attempt of the callback.
+type CallbackAttempt struct {
+	RetryTimes int32  `json:"retry_times"`
+	AttemptAt  int64  `json:"attempt_at"`
+	Succeed    bool   `json:"succeed"`
+	Error      string `json:"error"`
+}
+
+// RecordAttempt records a delivery attempt in the history, only the latest attempts are kept.
+func (cl *CallbackLog) RecordAttempt(err error) {
+	attempt := CallbackAttempt{
+		RetryTimes: cl.RetryTimes,
+		AttemptAt:  time.Now().UnixMilli(),
+		Succeed:    err == nil,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		cl.LastError = attempt.Error
+	}
+
+	cl.Attempts = append(cl.Attempts, attempt)
+	if len(cl.Attempts) > maxCallbackAttempts {
+		cl.Attempts = cl.Attempts[len(cl.Attempts)-maxCallbackAttempts:]
+	}
+}
+
+// SetNextRetryAtAndStatus sets the next retry time and status of the callback log after a failed delivery.
+// The callback log will be marked as failed if the retry policy runs out.
+func (cl *CallbackLog) SetNextRetryAtAndStatus(cbConfig *CallbackConfig) {
+	if next, ok := cl.nextRetry(cbConfig); ok {
+		cl.RetryTimes++
+		cl.NextRetryAt = time.Now().Add(next).UnixMilli()
+		cl.Status = CallbackStatusPending
+		return
+	}
+
+	cl.NextRetryAt = 0
+	cl.Status = CallbackStatusFailed
+}
+
+func (cl *CallbackLog) nextRetry(cbConfig *CallbackConfig) (time.Duration, bool) {
+	if cbConfig == nil || cbConfig.RetryPolicy == nil {
+		return 0, false
+	}
+
+	strategy, err := retry.NewRetryStrategy(*cbConfig.RetryPolicy)
+	if err != nil {
+		return 0, false
+	}
+
+	return strategy.NextWithRetried(cl.RetryTimes + 1)
 }
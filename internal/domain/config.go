@@ -1,6 +1,14 @@
 package domain
 
-import "github.com/JrMarcco/jotice/internal/pkg/retry"
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/retry"
+)
 
 type BizConfig struct {
 	Id             uint64
@@ -15,11 +23,126 @@ type BizConfig struct {
 	UpdateAt       int64
 }
 
+// Clone returns a deep copy of the biz config, so the copies do not share the sub configs.
+func (bc BizConfig) Clone() BizConfig {
+	res := bc
+	if bc.ChannelConfig != nil {
+		cc := *bc.ChannelConfig
+		cc.Channels = slices.Clone(cc.Channels)
+		cc.RetryPolicy = cloneRetryPolicy(cc.RetryPolicy)
+		res.ChannelConfig = &cc
+	}
+
+	if bc.TxNotifConfig != nil {
+		tc := *bc.TxNotifConfig
+		tc.RetryPolicy = cloneRetryPolicy(tc.RetryPolicy)
+		res.TxNotifConfig = &tc
+	}
+
+	if bc.QuotaConfig != nil {
+		qc := *bc.QuotaConfig
+		qc.Daily = clonePtr(qc.Daily)
+		qc.Monthly = clonePtr(qc.Monthly)
+		qc.AlertThresholds = slices.Clone(qc.AlertThresholds)
+		res.QuotaConfig = &qc
+	}
+
+	if bc.CallbackConfig != nil {
+		cc := *bc.CallbackConfig
+		cc.Webhook = clonePtr(cc.Webhook)
+		cc.RetryPolicy = cloneRetryPolicy(cc.RetryPolicy)
+		res.CallbackConfig = &cc
+	}
+	return res
+}
+
+func cloneRetryPolicy(c *retry.Config) *retry.Config {
+	if c == nil {
+		return nil
+	}
+
+	res := *c
+	res.FixedInterval = clonePtr(c.FixedInterval)
+	res.ExponentialBackoff = clonePtr(c.ExponentialBackoff)
+	return &res
+}
+
+// clonePtr copies the value of a pointer to a struct without reference fields.
+func clonePtr[T any](val *T) *T {
+	if val == nil {
+		return nil
+	}
+
+	res := *val
+	return &res
+}
+
+// Validate validates the biz config and all its sub configs.
+func (bc BizConfig) Validate() error {
+	if bc.Id <= 0 {
+		return fmt.Errorf("%w: biz id should not be negative or zero", errs.ErrInvalidParam)
+	}
+
+	if bc.OwnerId <= 0 {
+		return fmt.Errorf("%w: owner id should not be negative or zero", errs.ErrInvalidParam)
+	}
+
+	if !OwnerType(bc.OwnerType).Validate() {
+		return fmt.Errorf("%w: invalid owner type", errs.ErrInvalidParam)
+	}
+
+	if bc.RateLimit <= 0 {
+		return fmt.Errorf("%w: rate limit should be greater than 0", errs.ErrInvalidParam)
+	}
+
+	if bc.ChannelConfig != nil {
+		if err := bc.ChannelConfig.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if bc.TxNotifConfig != nil {
+		if err := bc.TxNotifConfig.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if bc.QuotaConfig != nil {
+		if err := bc.QuotaConfig.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if bc.CallbackConfig != nil {
+		if err := bc.CallbackConfig.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type ChannelConfig struct {
 	Channels    []ChannelItem `json:"channels"`
 	RetryPolicy *retry.Config `json:"retry_policy"`
 }
 
+func (c *ChannelConfig) Validate() error {
+	if len(c.Channels) == 0 {
+		return fmt.Errorf("%w: channels should not be empty", errs.ErrInvalidParam)
+	}
+
+	for _, item := range c.Channels {
+		if !Channel(item.Channel).Validate() {
+			return fmt.Errorf("%w: invalid channel %s", errs.ErrInvalidParam, item.Channel)
+		}
+	}
+
+	if c.RetryPolicy != nil {
+		return c.RetryPolicy.Validate()
+	}
+	return nil
+}
+
 type ChannelItem struct {
 	Channel  string `json:"channel"`
 	Priority int32  `json:"priority"`
@@ -32,14 +155,110 @@ type TxNotifConfig struct {
 	RetryPolicy  *retry.Config `json:"retry_policy"`
 }
 
+func (c *TxNotifConfig) Validate() error {
+	if c.ServiceName == "" {
+		return fmt.Errorf("%w: tx notification service name should not be empty", errs.ErrInvalidParam)
+	}
+
+	if c.InitialDelay < 0 {
+		return fmt.Errorf("%w: initial delay should not be negative", errs.ErrInvalidParam)
+	}
+
+	if c.RetryPolicy != nil {
+		return c.RetryPolicy.Validate()
+	}
+	return nil
+}
+
 type CallbackConfig struct {
-	ServiceName string        `json:"service_name"`
-	RetryPolicy *retry.Config `json:"retry_policy"`
+	ServiceName string         `json:"service_name"`
+	Webhook     *WebhookConfig `json:"webhook"`
+	RetryPolicy *retry.Config  `json:"retry_policy"`
 }
 
+func (c *CallbackConfig) Validate() error {
+	if c.ServiceName == "" && !c.IsWebhook() {
+		return fmt.Errorf("%w: callback service name or webhook url should be provided", errs.ErrInvalidParam)
+	}
+
+	if c.Webhook != nil {
+		if err := c.Webhook.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.RetryPolicy != nil {
+		return c.RetryPolicy.Validate()
+	}
+	return nil
+}
+
+// IsWebhook returns true if the result should be delivered by http webhook instead of grpc.
+func (c *CallbackConfig) IsWebhook() bool {
+	return c.Webhook != nil && c.Webhook.Url != ""
+}
+
+type WebhookConfig struct {
+	Url    string `json:"url"`
+	Secret string `json:"secret"`
+	// TimeoutMillis is the timeout of the webhook request in milliseconds, zero means the default timeout.
+	TimeoutMillis int64 `json:"timeout_millis"`
+}
+
+func (c *WebhookConfig) Validate() error {
+	u, err := url.Parse(c.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: invalid webhook url %s", errs.ErrInvalidParam, c.Url)
+	}
+
+	if c.Secret == "" {
+		return fmt.Errorf("%w: webhook secret should not be empty", errs.ErrInvalidParam)
+	}
+
+	if c.TimeoutMillis < 0 {
+		return fmt.Errorf("%w: webhook timeout should not be negative", errs.ErrInvalidParam)
+	}
+	return nil
+}
+
+// Timeout returns the timeout of the webhook request.
+func (c *WebhookConfig) Timeout() time.Duration {
+	return time.Duration(c.TimeoutMillis) * time.Millisecond
+}
+
+// defaultQuotaAlertThresholds alerts when 80% and 100% of the quota is used.
+var defaultQuotaAlertThresholds = []int32{80, 100}
+
 type QuotaConfig struct {
 	Daily   *DailyQuotaConfig   `json:"daily"`
 	Monthly *MonthlyQuotaConfig `json:"monthly"`
+	// AlertThresholds are the percentages of the used quota to alert, default is 80% and 100%.
+	AlertThresholds []int32 `json:"alert_thresholds"`
+}
+
+func (c *QuotaConfig) Validate() error {
+	if c.Daily != nil && (c.Daily.SMS < 0 || c.Daily.Email < 0) {
+		return fmt.Errorf("%w: daily quota should not be negative", errs.ErrInvalidParam)
+	}
+
+	if c.Monthly != nil && (c.Monthly.SMS < 0 || c.Monthly.Email < 0) {
+		return fmt.Errorf("%w: monthly quota should not be negative", errs.ErrInvalidParam)
+	}
+
+	for _, threshold := range c.AlertThresholds {
+		if threshold <= 0 || threshold > 100 {
+			return fmt.Errorf("%w: alert threshold should be in (0, 100]", errs.ErrInvalidParam)
+		}
+	}
+	return nil
+}
+
+// Thresholds returns the alert thresholds in percentage.
+func (c *QuotaConfig) Thresholds() []int32 {
+	if len(c.AlertThresholds) == 0 {
+		return defaultQuotaAlertThresholds
+	}
+	return c.AlertThresholds
 }
 
 type DailyQuotaConfig struct {
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// BizConfigRevision is an immutable snapshot of the biz config created by every save.
+type BizConfigRevision struct {
+	Id       uint64
+	BizId    uint64
+	Version  int32
+	Config   BizConfig
+	Author   string
+	CreateAt int64
+}
+
+// BizConfigDiff describes a changed field between two biz configs.
+// The sub configs are compared and presented as json.
+type BizConfigDiff struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Diff returns the fields changed from bc to other.
+func (bc BizConfig) Diff(other BizConfig) []BizConfigDiff {
+	var diffs []BizConfigDiff
+	add := func(field, oldVal, newVal string) {
+		if oldVal != newVal {
+			diffs = append(diffs, BizConfigDiff{Field: field, Old: oldVal, New: newVal})
+		}
+	}
+
+	add("owner_id", strconv.FormatUint(bc.OwnerId, 10), strconv.FormatUint(other.OwnerId, 10))
+	add("owner_type", bc.OwnerType, other.OwnerType)
+	add("rate_limit", strconv.FormatInt(int64(bc.RateLimit), 10), strconv.FormatInt(int64(other.RateLimit), 10))
+	add("channel_config", subConfigJson(bc.ChannelConfig), subConfigJson(other.ChannelConfig))
+	add("tx_notif_config", subConfigJson(bc.TxNotifConfig), subConfigJson(other.TxNotifConfig))
+	add("quota_config", subConfigJson(bc.QuotaConfig), subConfigJson(other.QuotaConfig))
+	add("callback_config", subConfigJson(bc.CallbackConfig), subConfigJson(other.CallbackConfig))
+	return diffs
+}
+
+// subConfigJson returns the json of the sub config, empty string if the sub config is nil.
+func subConfigJson[T any](val *T) string {
+	if val == nil {
+		return ""
+	}
+
+	// sub configs are plain structs, marshal never fails.
+	jsonBytes, _ := json.Marshal(val)
+	return string(jsonBytes)
+}
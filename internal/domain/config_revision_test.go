@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBizConfig_Diff(t *testing.T) {
+	t.Parallel()
+
+	base := BizConfig{
+		Id:        1,
+		OwnerId:   1,
+		OwnerType: "organization",
+		RateLimit: 100,
+		QuotaConfig: &QuotaConfig{
+			Daily: &DailyQuotaConfig{SMS: 100},
+		},
+	}
+
+	tcs := []struct {
+		name   string
+		change func(bc *BizConfig)
+		want   []BizConfigDiff
+	}{
+		{
+			name:   "no change",
+			change: func(bc *BizConfig) {},
+		}, {
+			name: "plain fields",
+			change: func(bc *BizConfig) {
+				bc.OwnerType = "person"
+				bc.RateLimit = 200
+			},
+			want: []BizConfigDiff{
+				{Field: "owner_type", Old: "organization", New: "person"},
+				{Field: "rate_limit", Old: "100", New: "200"},
+			},
+		}, {
+			name: "changed sub config",
+			change: func(bc *BizConfig) {
+				bc.QuotaConfig = &QuotaConfig{Daily: &DailyQuotaConfig{SMS: 200}}
+			},
+			want: []BizConfigDiff{
+				{
+					Field: "quota_config",
+					Old:   `{"daily":{"sms":100,"email":0},"monthly":null,"alert_thresholds":null}`,
+					New:   `{"daily":{"sms":200,"email":0},"monthly":null,"alert_thresholds":null}`,
+				},
+			},
+		}, {
+			name: "added and removed sub configs",
+			change: func(bc *BizConfig) {
+				bc.QuotaConfig = nil
+				bc.TxNotifConfig = &TxNotifConfig{ServiceName: "order"}
+			},
+			want: []BizConfigDiff{
+				{
+					Field: "tx_notif_config",
+					Old:   "",
+					New:   `{"service_name":"order","initial_delay":0,"retry_policy":null}`,
+				}, {
+					Field: "quota_config",
+					Old:   `{"daily":{"sms":100,"email":0},"monthly":null,"alert_thresholds":null}`,
+					New:   "",
+				},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			other := base.Clone()
+			tc.change(&other)
+			assert.Equal(t, tc.want, base.Diff(other))
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// IdInspection is the decoded notification id and the shard the notification is routed to.
+type IdInspection struct {
+	Id        uint64    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Hash      uint64    `json:"hash"`
+	WorkerId  uint64    `json:"worker_id"`
+	Sequence  uint64    `json:"sequence"`
+
+	// DB and Table are the shard the notification is read from,
+	// Table is the archive table of the shard if the notification has been archived.
+	DB    string `json:"db"`
+	Table string `json:"table"`
+
+	// Found is false when the notification does not exist in the shard, e.g. the id is never persisted.
+	Found        bool         `json:"found"`
+	Notification Notification `json:"notification"`
+}
@@ -25,6 +25,11 @@ func (s SendStatus) String() string {
 	return string(s)
 }
 
+// IsTerminal returns true if the notification will not be sent anymore.
+func (s SendStatus) IsTerminal() bool {
+	return s == SendStatusSuccess || s == SendStatusFailed || s == SendStatusCanceled
+}
+
 // Template domain model of notification template
 type Template struct {
 	Id        uint64            `json:"id"`
@@ -100,3 +100,13 @@ func (p *Provider) Validate() error {
 
 	return nil
 }
+
+// ProviderUsage is the token usage of the provider today.
+type ProviderUsage struct {
+	ProviderId uint64
+	QpsLimit   int32
+	DailyLimit int32
+	DailyUsed  int64
+	// DailyThrottled is the number of requests the provider denied a token at least once.
+	DailyThrottled int64
+}
@@ -1,7 +1,155 @@
 package domain
 
+import "time"
+
 type Quota struct {
 	BizId   uint64
 	Quota   int32
 	Channel Channel
 }
+
+const (
+	quotaPeriodDailyPrefix   = "d"
+	quotaPeriodMonthlyPrefix = "m"
+)
+
+// DailyQuotaPeriod returns the daily quota period of t, e.g. "d20250101".
+func DailyQuotaPeriod(t time.Time) string {
+	return quotaPeriodDailyPrefix + t.Format("20060102")
+}
+
+// DailyQuotaPeriodPrefix returns the prefix of all the daily quota periods in the month of t, e.g. "d202501".
+func DailyQuotaPeriodPrefix(t time.Time) string {
+	return quotaPeriodDailyPrefix + t.Format("200601")
+}
+
+// MonthlyQuotaPeriod returns the monthly quota period of t, e.g. "m202501".
+func MonthlyQuotaPeriod(t time.Time) string {
+	return quotaPeriodMonthlyPrefix + t.Format("200601")
+}
+
+// IsMonthlyQuotaPeriod returns true if the period is a monthly quota period.
+func IsMonthlyQuotaPeriod(period string) bool {
+	return len(period) > 0 && period[:1] == quotaPeriodMonthlyPrefix
+}
+
+// QuotaItem is the quota to deduct from the quota counter of the biz on the channel in the period.
+type QuotaItem struct {
+	BizId   uint64
+	Channel Channel
+	Period  string
+	Limit   int64
+	Amount  int64
+}
+
+// PartialQuotaItems returns the part of the deducted items taken by the notifications,
+// e.g. to refund the notifications failed in a batch.
+func PartialQuotaItems(items []QuotaItem, ns []Notification) []QuotaItem {
+	type groupKey struct {
+		bizId   uint64
+		channel Channel
+	}
+
+	amounts := make(map[groupKey]int64)
+	for _, n := range ns {
+		amounts[groupKey{bizId: n.BizId, channel: n.Channel}] += int64(len(n.Receivers))
+	}
+
+	var res []QuotaItem
+	for _, item := range items {
+		amount := min(amounts[groupKey{bizId: item.BizId, channel: item.Channel}], item.Amount)
+		if amount <= 0 {
+			continue
+		}
+
+		item.Amount = amount
+		res = append(res, item)
+	}
+	return res
+}
+
+// QuotaUsage is the used quota of the biz on the channel in the period.
+type QuotaUsage struct {
+	BizId   uint64
+	Channel Channel
+	Period  string
+	Used    int64
+}
+
+// QuotaReport is the used and remaining quota of the biz on the channel today and this month.
+// The limit is zero if not configured, and the remaining is meaningless then.
+type QuotaReport struct {
+	BizId   uint64
+	Channel Channel
+
+	DailyLimit     int64
+	DailyUsed      int64
+	DailyRemaining int64
+
+	MonthlyLimit     int64
+	MonthlyUsed      int64
+	MonthlyRemaining int64
+}
+
+// QuotaAlertEvent is emitted when the used quota of the biz on the channel reaches the threshold in the period,
+// or a deduction is rejected for exceeding it.
+// The biz id, channel, period and threshold identify the alert, which is emitted once for them.
+type QuotaAlertEvent struct {
+	BizId     uint64  `json:"biz_id"`
+	Channel   Channel `json:"channel"`
+	Period    string  `json:"period"`
+	Threshold int32   `json:"threshold"`
+	Limit     int64   `json:"limit"`
+	Used      int64   `json:"used"`
+	AlertAt   int64   `json:"alert_at"`
+}
+
+// CrossedThresholds returns the thresholds (in percentage) crossed by the used quota going from before to after.
+// The used quota may cross a threshold again after a refund, the caller should dedupe the alerts.
+func CrossedThresholds(thresholds []int32, limit, before, after int64) []int32 {
+	if limit <= 0 {
+		return nil
+	}
+
+	var crossed []int32
+	for _, threshold := range thresholds {
+		line := int64(threshold) * limit
+		if before*100 < line && after*100 >= line {
+			crossed = append(crossed, threshold)
+		}
+	}
+	return crossed
+}
+
+// Limits returns the daily and monthly quota limits of the channel, zero means no limit.
+func (c *QuotaConfig) Limits(channel Channel) (daily int32, monthly int32) {
+	if c.Daily != nil {
+		daily = c.Daily.limit(channel)
+	}
+	if c.Monthly != nil {
+		monthly = c.Monthly.limit(channel)
+	}
+	return daily, monthly
+}
+
+func (c *DailyQuotaConfig) limit(channel Channel) int32 {
+	switch channel {
+	case ChannelSMS:
+		return c.SMS
+	case ChannelEmail:
+		return c.Email
+	default:
+		return 0
+	}
+}
+
+func (c *MonthlyQuotaConfig) limit(channel Channel) int32 {
+	switch channel {
+	case ChannelSMS:
+		return c.SMS
+	case ChannelEmail:
+		return c.Email
+	default:
+		return 0
+	}
+}
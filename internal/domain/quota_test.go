@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartialQuotaItems(t *testing.T) {
+	t.Parallel()
+
+	items := []QuotaItem{
+		{BizId: 1, Channel: ChannelSMS, Period: "d20250101", Limit: 100, Amount: 3},
+		{BizId: 1, Channel: ChannelSMS, Period: "m202501", Limit: 1000, Amount: 3},
+		{BizId: 1, Channel: ChannelEmail, Period: "d20250101", Limit: 100, Amount: 1},
+	}
+
+	got := PartialQuotaItems(items, []Notification{
+		{BizId: 1, Channel: ChannelSMS, Receivers: []string{"a", "b"}},
+		// the channel without quota item is skipped
+		{BizId: 1, Channel: ChannelApp, Receivers: []string{"c"}},
+	})
+	assert.Equal(t, []QuotaItem{
+		{BizId: 1, Channel: ChannelSMS, Period: "d20250101", Limit: 100, Amount: 2},
+		{BizId: 1, Channel: ChannelSMS, Period: "m202501", Limit: 1000, Amount: 2},
+	}, got)
+
+	assert.Empty(t, PartialQuotaItems(items, nil))
+}
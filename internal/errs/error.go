@@ -8,4 +8,17 @@ var (
 	ErrInvalidChannel             = errors.New("[jotice] invalid channel")
 	ErrInvalidSendStrategy        = errors.New("[jotice] invalid send strategy")
 	ErrNoAvailableFailoverService = errors.New("[jotice] no service needs to be take over")
+	ErrBizConfigNotFound          = errors.New("[jotice] biz config not found")
+	ErrBizConfigRevisionNotFound  = errors.New("[jotice] biz config revision not found")
+	ErrQuotaExhausted             = errors.New("[jotice] quota exhausted")
+	ErrCostExceedsBurst           = errors.New("[jotice] cost exceeds rate limit burst")
+	ErrNoAvailableProvider        = errors.New("[jotice] no available provider")
+	ErrProviderSendFailed         = errors.New("[jotice] provider failed to send notification")
+	ErrNotificationNotFound       = errors.New("[jotice] notification not found")
+	ErrCallbackLogNotFound        = errors.New("[jotice] callback log not found")
+	ErrDuplicateNotification      = errors.New("[jotice] duplicate notification")
+	ErrMissingShardingDst         = errors.New("[jotice] missing sharding dst in context")
+	ErrClockMovedBackwards        = errors.New("[jotice] clock moved backwards")
+	ErrWorkerIdLost               = errors.New("[jotice] worker id lost")
+	ErrNoAvailableWorkerId        = errors.New("[jotice] no available worker id")
 )
@@ -1,14 +1,24 @@
 package ioc
 
 import (
+	"context"
 	"time"
 
+	"github.com/JrMarcco/jotice/internal/repository/cache/local"
 	"github.com/patrickmn/go-cache"
+	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 )
 
-var GoCacheFxOpt = fx.Provide(InitGoCache)
+var GoCacheFxOpt = fx.Provide(
+	InitGoCache,
+	fx.Annotate(
+		InitLBizConfigCache,
+		fx.ParamTags(``, ``, ``, `name:"zapLogger"`),
+	),
+)
 
 func InitGoCache() *cache.Cache {
 	type Config struct {
@@ -22,3 +32,24 @@ func InitGoCache() *cache.Cache {
 
 	return cache.New(cfg.DefaultExpiration, cfg.CleanupInterval)
 }
+
+// InitLBizConfigCache creates the local biz config cache,
+// which subscribes the invalidation from the other instances until the app stops.
+func InitLBizConfigCache(
+	lc fx.Lifecycle, c *cache.Cache, rdb redis.UniversalClient, logger *zap.Logger,
+) *local.LBizConfigCache {
+	lbc := local.NewLBizConfigCache(c, rdb, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			lbc.Start(ctx)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+	return lbc
+}
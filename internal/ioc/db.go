@@ -12,9 +12,9 @@ import (
 	"gorm.io/gorm"
 )
 
-var DBFxOpt = fx.Provide(initDB)
+var DBFxOpt = fx.Provide(InitDB)
 
-func initDB() *gorm.DB {
+func InitDB() *gorm.DB {
 	type config struct {
 		DSN string `yaml:"dsn"`
 	}
@@ -36,7 +36,7 @@ func initDB() *gorm.DB {
 }
 
 func waitForDBSetup(dsn string) {
-	sqlDB, err := sql.Open("mysql", dsn)
+	sqlDB, err := sql.Open("pgx", dsn)
 	defer func(sqlDB *sql.DB) { _ = sqlDB.Close() }(sqlDB)
 	if err != nil {
 		panic(err)
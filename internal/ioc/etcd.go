@@ -1,12 +1,15 @@
 package ioc
 
 import (
+	"context"
+
 	"github.com/spf13/viper"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
 	"go.uber.org/fx"
 )
 
-var EtcdFxOpt = fx.Provide(InitEtcdClient)
+var EtcdFxOpt = fx.Provide(InitEtcdClient, InitEtcdSession)
 
 func InitEtcdClient() *clientv3.Client {
 	var cfg clientv3.Config
@@ -20,3 +23,23 @@ func InitEtcdClient() *clientv3.Client {
 	}
 	return client
 }
+
+// InitEtcdSession creates the session of the instance, the keys bound to it are removed when the instance is gone.
+func InitEtcdSession(lc fx.Lifecycle, client *clientv3.Client) *concurrency.Session {
+	var opts []concurrency.SessionOption
+	if ttl := viper.GetInt("etcd.sessionTTL"); ttl > 0 {
+		opts = append(opts, concurrency.WithTTL(ttl))
+	}
+
+	session, err := concurrency.NewSession(client, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return session.Close()
+		},
+	})
+	return session
+}
@@ -7,6 +7,8 @@ import (
 	"encoding/pem"
 
 	notificationv1 "github.com/JrMarcco/jotice-api/api/notification/v1"
+	adminv1 "github.com/JrMarcco/jotice/api/gen/admin/v1"
+	queryv1 "github.com/JrMarcco/jotice/api/gen/query/v1"
 	grpcapi "github.com/JrMarcco/jotice/internal/api/grpc"
 	"github.com/spf13/viper"
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -19,7 +21,10 @@ var GrpcFxOpt = fx.Provide(
 	fx.Invoke(RunGrpcServer),
 )
 
-func NewGrpcServer(server grpcapi.NotificationServer, etcdClient *clientv3.Client) *grpc.Server {
+func NewGrpcServer(
+	server grpcapi.NotificationServer, queryServer *grpcapi.QueryServer, adminServer *grpcapi.AdminServer,
+	etcdClient *clientv3.Client,
+) *grpc.Server {
 	type Config struct {
 		priPem string `yaml:"private"`
 		pubPem string `yaml:"public"`
@@ -37,6 +42,8 @@ func NewGrpcServer(server grpcapi.NotificationServer, etcdClient *clientv3.Clien
 	svr := grpc.NewServer()
 	notificationv1.RegisterNotificationServiceServer(svr, server)
 	notificationv1.RegisterNotificationQueryServiceServer(svr, server)
+	queryv1.RegisterQueryServiceServer(svr, queryServer)
+	adminv1.RegisterAdminServiceServer(svr, adminServer)
 
 	return svr
 }
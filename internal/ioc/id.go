@@ -1,12 +1,55 @@
 package ioc
 
 import (
+	"context"
+
 	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+	"github.com/JrMarcco/jotice/internal/pkg/workerid"
+	"go.etcd.io/etcd/client/v3/concurrency"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 )
 
-var IdGeneratorFxOpt = fx.Provide(InitIdGenerator)
+var IdGeneratorFxOpt = fx.Provide(InitWorkerLease, InitIdGenerator)
+
+// InitWorkerLease leases the worker id of the instance, shared by all the id generators.
+// The instance is shut down once the lease is lost, as the id generators stop issuing ids then,
+// and the worker id may be taken by others, it is left to the restart to acquire a new one.
+func InitWorkerLease(
+	lc fx.Lifecycle, session *concurrency.Session, shutdowner fx.Shutdowner, logger *zap.Logger,
+) *workerid.Lease {
+	lease, err := workerid.Acquire(context.Background(), session, snowflake.MaxWorkerId)
+	if err != nil {
+		panic(err)
+	}
+
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go func() {
+				select {
+				case <-stopped:
+				case <-lease.Done():
+					logger.Error("[jotice] worker id lease lost, shutting down", zap.Uint64("worker_id", lease.Id()))
+					if err := shutdowner.Shutdown(fx.ExitCode(1)); err != nil {
+						logger.Error("[jotice] failed to shut down", zap.Error(err))
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(stopped)
+			return lease.Release(ctx)
+		},
+	})
+	return lease
+}
 
-func InitIdGenerator() *snowflake.Generator {
-	return snowflake.NewGenerator()
+func InitIdGenerator(lease *workerid.Lease) *snowflake.Generator {
+	g, err := snowflake.NewWorkerGenerator(lease.Id(), lease.Done())
+	if err != nil {
+		panic(err)
+	}
+	return g
 }
@@ -6,9 +6,16 @@ import (
 	"go.uber.org/fx"
 )
 
-var RedisFxOpt = fx.Provide(InitRedis)
+var RedisFxOpt = fx.Provide(
+	InitRedis,
+	// the pub/sub is only available on redis.UniversalClient,
+	// the other components depend on redis.Cmdable.
+	func(client redis.UniversalClient) redis.Cmdable {
+		return client
+	},
+)
 
-func InitRedis() redis.Cmdable {
+func InitRedis() redis.UniversalClient {
 	addr := viper.GetString("redis.addr")
 	return redis.NewClient(&redis.Options{
 		Addr: addr,
@@ -0,0 +1,103 @@
+package ioc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/JrMarcco/easy-kit/xsync"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"github.com/spf13/viper"
+	"go.uber.org/fx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var ShardingDBFxOpt = fx.Provide(InitShardingDBs)
+
+const (
+	ShardingTypeHash           = "hash"
+	ShardingTypeConsistentHash = "consistent_hash"
+	ShardingTypeTimeRange      = "time_range"
+)
+
+// ShardingConfig is the sharding config of a logical table, the type decides the strategy and the fields it uses.
+//
+//   - hash (default): dbSharding and tableSharding.
+//   - consistent_hash: nodes, virtualNodes and tableSharding.
+//   - time_range: dbSharding, since, aheadMonths and lookbackMonths, the tables are monthly.
+type ShardingConfig struct {
+	Type          string `yaml:"type"`
+	DBPrefix      string `yaml:"dbPrefix"`
+	TablePrefix   string `yaml:"tablePrefix"`
+	DBSharding    uint64 `yaml:"dbSharding"`
+	TableSharding uint64 `yaml:"tableSharding"`
+
+	// Nodes are the dbs on the ring of consistent_hash.
+	Nodes []DBNodeConfig `yaml:"nodes"`
+	// VirtualNodes is the number of virtual nodes of each weight, 160 if not positive.
+	VirtualNodes int `yaml:"virtualNodes"`
+
+	// Since is the first month of the time_range tables, e.g. 2025-01.
+	Since          string `yaml:"since"`
+	AheadMonths    int    `yaml:"aheadMonths"`
+	LookbackMonths int    `yaml:"lookbackMonths"`
+}
+
+type DBNodeConfig struct {
+	Suffix uint64 `yaml:"suffix"`
+	Weight int    `yaml:"weight"`
+}
+
+// Strategy builds the sharding strategy of the type.
+func (c ShardingConfig) Strategy() (sharding.Strategy, error) {
+	switch c.Type {
+	case "", ShardingTypeHash:
+		return sharding.NewHashStrategy(c.DBPrefix, c.TablePrefix, c.DBSharding, c.TableSharding), nil
+	case ShardingTypeConsistentHash:
+		nodes := make([]sharding.DBNode, 0, len(c.Nodes))
+		for _, node := range c.Nodes {
+			nodes = append(nodes, sharding.DBNode{Suffix: node.Suffix, Weight: node.Weight})
+		}
+		return sharding.NewConsistentHashStrategy(c.DBPrefix, c.TablePrefix, nodes, c.TableSharding, c.VirtualNodes)
+	case ShardingTypeTimeRange:
+		since, err := time.Parse("2006-01", c.Since)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid since %q of %s, cause of: %w", errs.ErrInvalidParam, c.Since, c.TablePrefix, err)
+		}
+		return sharding.NewTimeRangeStrategy(
+			c.DBPrefix, c.TablePrefix, c.DBSharding, since, c.AheadMonths, c.LookbackMonths,
+		), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown sharding type %q of %s", errs.ErrInvalidParam, c.Type, c.TablePrefix)
+	}
+}
+
+// LoadShardingConfigs loads the sharding configs keyed by the logical table.
+func LoadShardingConfigs() map[string]ShardingConfig {
+	var cfgs map[string]ShardingConfig
+	if err := viper.UnmarshalKey("sharding", &cfgs); err != nil {
+		panic(err)
+	}
+	return cfgs
+}
+
+// InitShardingDBs opens the sharding dbs keyed by the db name, e.g. jotice_0.
+func InitShardingDBs() *xsync.Map[string, *gorm.DB] {
+	var dsns map[string]string
+	if err := viper.UnmarshalKey("db.shards", &dsns); err != nil {
+		panic(err)
+	}
+
+	dbs := &xsync.Map[string, *gorm.DB]{}
+	for name, dsn := range dsns {
+		waitForDBSetup(dsn)
+
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		if err != nil {
+			panic(err)
+		}
+		dbs.Store(name, db)
+	}
+	return dbs
+}
@@ -0,0 +1,173 @@
+package idempotent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/cespare/xxhash/v2"
+)
+
+// MaxFalsePositiveRate is the cap of the configurable false positive rate of the bloom filter.
+const MaxFalsePositiveRate = 0.1
+
+// BloomFilter is a time-rotated bloom filter.
+//
+// Time is cut into slots of the window, the keys are added to the generation of the current slot,
+// and tested against the generations of the slots in the retry window.
+// So a key added within the retry window is always found, a negative proves the key is new in the retry window.
+type BloomFilter interface {
+	// TestAndAdd adds the keys and returns whether each key might have been added before.
+	// False means the key is definitely not added in the retry window.
+	TestAndAdd(ctx context.Context, keys ...string) ([]bool, error)
+}
+
+type BloomConfig struct {
+	// Capacity is the expected max number of keys in a generation.
+	// More keys raise the false positive rate, but never cause a false negative.
+	Capacity uint64 `json:"capacity"`
+	// FalsePositiveRate is the max false positive rate, in (0, MaxFalsePositiveRate].
+	FalsePositiveRate float64 `json:"false_positive_rate"`
+	// Window is the duration of a generation, at least 1ms.
+	Window time.Duration `json:"window"`
+	// RetryWindow is the max duration the biz retries a send, e.g. the ttl of the idempotent keys.
+	// The keys added within it are always found by the filter.
+	RetryWindow time.Duration `json:"retry_window"`
+}
+
+func (c BloomConfig) Validate() error {
+	if c.Capacity == 0 {
+		return fmt.Errorf("%w: capacity should be greater than 0", errs.ErrInvalidParam)
+	}
+
+	if c.FalsePositiveRate <= 0 || c.FalsePositiveRate > MaxFalsePositiveRate {
+		return fmt.Errorf("%w: false positive rate should be in (0, %v]", errs.ErrInvalidParam, MaxFalsePositiveRate)
+	}
+
+	if c.Window < time.Millisecond {
+		return fmt.Errorf("%w: window should be at least 1ms", errs.ErrInvalidParam)
+	}
+
+	if c.RetryWindow <= 0 {
+		return fmt.Errorf("%w: retry window should be greater than 0", errs.ErrInvalidParam)
+	}
+	return nil
+}
+
+// generations returns the number of the live generations,
+// the current one and the full ones covering the retry window.
+func (c BloomConfig) generations() int {
+	return int((c.RetryWindow+c.Window-1)/c.Window) + 1
+}
+
+// bloomHasher computes the bit positions of the keys with double hashing.
+type bloomHasher struct {
+	m uint64 // number of bits of a generation
+	k uint64 // number of hash functions
+}
+
+// newBloomHasher sizes the bloom filter by the config.
+// A key is tested against all the live generations,
+// so each generation is sized with an equal share of the false positive rate.
+func newBloomHasher(cfg BloomConfig) bloomHasher {
+	n := float64(cfg.Capacity)
+	p := cfg.FalsePositiveRate / float64(cfg.generations())
+
+	m := math.Ceil(-n * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / n * math.Ln2)
+	return bloomHasher{
+		m: uint64(m),
+		k: uint64(max(k, 1)),
+	}
+}
+
+func (h bloomHasher) positions(key string) []uint64 {
+	sum := xxhash.Sum64String(key)
+	h1, h2 := sum&math.MaxUint32, sum>>32
+
+	positions := make([]uint64, h.k)
+	for i := uint64(0); i < h.k; i++ {
+		positions[i] = (h1 + i*h2) % h.m
+	}
+	return positions
+}
+
+var _ BloomFilter = (*LocalBloomFilter)(nil)
+
+// LocalBloomFilter is a bloom filter in memory, it only knows the keys added by this instance.
+type LocalBloomFilter struct {
+	mu sync.Mutex
+
+	hasher bloomHasher
+	window time.Duration
+	now    func() time.Time
+
+	// gens is a ring of the generations, the generation of slot s is gens[s % len(gens)],
+	// slots[i] is the slot gens[i] is holding.
+	gens  [][]uint64
+	slots []int64
+}
+
+func (f *LocalBloomFilter) TestAndAdd(_ context.Context, keys ...string) ([]bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	slot := f.now().UnixNano() / f.window.Nanoseconds()
+	cur := int(slot % int64(len(f.gens)))
+	if f.slots[cur] != slot {
+		clear(f.gens[cur])
+		f.slots[cur] = slot
+	}
+
+	res := make([]bool, len(keys))
+	for i, key := range keys {
+		positions := f.hasher.positions(key)
+		for j, gen := range f.gens {
+			// the generation of a slot out of the retry window is stale
+			if f.slots[j] <= slot-int64(len(f.gens)) {
+				continue
+			}
+
+			all := true
+			for _, pos := range positions {
+				if gen[pos/64]&(uint64(1)<<(pos%64)) == 0 {
+					all = false
+					break
+				}
+			}
+			if all {
+				res[i] = true
+				break
+			}
+		}
+
+		for _, pos := range positions {
+			f.gens[cur][pos/64] |= uint64(1) << (pos % 64)
+		}
+	}
+	return res, nil
+}
+
+func NewLocalBloomFilter(cfg BloomConfig) (*LocalBloomFilter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	hasher := newBloomHasher(cfg)
+	words := (hasher.m + 63) / 64
+
+	gens := make([][]uint64, cfg.generations())
+	for i := range gens {
+		gens[i] = make([]uint64, words)
+	}
+	return &LocalBloomFilter{
+		hasher: hasher,
+		window: cfg.Window,
+		now:    time.Now,
+		gens:   gens,
+		slots:  make([]int64, len(gens)),
+	}, nil
+}
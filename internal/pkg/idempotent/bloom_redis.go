@@ -0,0 +1,78 @@
+package idempotent
+
+import (
+	"context"
+	_ "embed"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	//go:embed lua/bloom.lua
+	bloomLua string
+
+	bloomScript = redis.NewScript(bloomLua)
+)
+
+var _ BloomFilter = (*RedisBloomFilter)(nil)
+
+// RedisBloomFilter is a bloom filter shared by all the instances, stored as redis bitmaps.
+type RedisBloomFilter struct {
+	client redis.Cmdable
+	name   string
+
+	hasher bloomHasher
+	cfg    BloomConfig
+}
+
+func (f *RedisBloomFilter) TestAndAdd(ctx context.Context, keys ...string) ([]bool, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, 0, 2+len(keys)*int(f.hasher.k))
+	args = append(args, f.cfg.Window.Milliseconds(), f.hasher.k)
+	for _, key := range keys {
+		for _, pos := range f.hasher.positions(key) {
+			args = append(args, pos)
+		}
+	}
+
+	vals, err := bloomScript.Run(ctx, f.client, f.redisKeys(), args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]bool, len(vals))
+	for i, val := range vals {
+		res[i] = val == 1
+	}
+	return res, nil
+}
+
+// redisKeys returns the bitmaps of the generations and the meta of the filter,
+// the hash tag keeps them in the same slot of a redis cluster.
+func (f *RedisBloomFilter) redisKeys() []string {
+	prefix := "idempotent:bloom:{" + f.name + "}:"
+
+	gens := f.cfg.generations()
+	keys := make([]string, 0, gens+1)
+	for i := range gens {
+		keys = append(keys, prefix+"gen:"+strconv.Itoa(i))
+	}
+	return append(keys, prefix+"meta")
+}
+
+func NewRedisBloomFilter(client redis.Cmdable, name string, cfg BloomConfig) (*RedisBloomFilter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &RedisBloomFilter{
+		client: client,
+		name:   name,
+		hasher: newBloomHasher(cfg),
+		cfg:    cfg,
+	}, nil
+}
@@ -0,0 +1,51 @@
+//go:build e2e
+
+package idempotent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisBloomFilter_TestAndAdd(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "192.168.3.3:6379",
+		Password: "<passwd>",
+	})
+
+	filter, err := NewRedisBloomFilter(client, "test", BloomConfig{
+		Capacity:          2,
+		FalsePositiveRate: 0.01,
+		Window:            time.Second,
+		RetryWindow:       time.Second,
+	})
+	require.NoError(t, err)
+
+	defer func() {
+		client.Del(ctx, filter.redisKeys()...)
+		_ = client.Close()
+	}()
+
+	// the keys over the capacity do not rotate the generation
+	res, err := filter.TestAndAdd(ctx, "a", "b", "c")
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, false, false}, res)
+
+	res, err = filter.TestAndAdd(ctx, "c", "a")
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, true}, res)
+
+	// the generation of "a", "b" and "c" is out of the retry window after 2 windows
+	time.Sleep(2 * time.Second)
+	res, err = filter.TestAndAdd(ctx, "b", "d")
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, false}, res)
+}
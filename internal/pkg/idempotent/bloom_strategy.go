@@ -0,0 +1,108 @@
+package idempotent
+
+import (
+	"context"
+	"time"
+)
+
+const markTimeout = time.Second
+
+var _ Strategy = (*BloomStrategy)(nil)
+
+// BloomStrategy layers a bloom filter in front of an exact strategy.
+//
+// The filter finds all the keys added in its retry window, so a bloom negative is a new key of the retry window,
+// which skips the exact check and is marked in the exact strategy asynchronously.
+// Only the keys which might have been seen are checked by the exact strategy,
+// a false positive of the filter only costs an exact check.
+//
+// The filter should see the keys of all the instances, e.g. RedisBloomFilter,
+// a LocalBloomFilter only fits a single instance.
+// The retry window of the filter should cover the retry window of the biz, e.g. the ttl of the exact strategy.
+type BloomStrategy struct {
+	filter BloomFilter
+	exact  Strategy
+}
+
+func (b *BloomStrategy) Exists(ctx context.Context, key string) (bool, error) {
+	maybe, err := b.filter.TestAndAdd(ctx, key)
+	if err != nil {
+		return b.exact.Exists(ctx, key)
+	}
+
+	if !maybe[0] {
+		b.mark([]string{key})
+		return false, nil
+	}
+	return b.exact.Exists(ctx, key)
+}
+
+func (b *BloomStrategy) MultiExists(ctx context.Context, keys []string) (map[string]bool, error) {
+	// the same key in a batch is tested once, the results are keyed by the key.
+	uniqueKeys := make([]string, 0, len(keys))
+	seen := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			uniqueKeys = append(uniqueKeys, key)
+		}
+	}
+
+	maybe, err := b.filter.TestAndAdd(ctx, uniqueKeys...)
+	if err != nil {
+		return b.exact.MultiExists(ctx, uniqueKeys)
+	}
+
+	var newKeys, maybeKeys []string
+	for i, key := range uniqueKeys {
+		if maybe[i] {
+			maybeKeys = append(maybeKeys, key)
+			continue
+		}
+		newKeys = append(newKeys, key)
+	}
+
+	res := make(map[string]bool, len(uniqueKeys))
+	if len(maybeKeys) > 0 {
+		exists, err := b.exact.MultiExists(ctx, maybeKeys)
+		if err != nil {
+			return nil, err
+		}
+		for key, val := range exists {
+			res[key] = val
+		}
+	}
+
+	for _, key := range newKeys {
+		res[key] = false
+	}
+	b.mark(newKeys)
+	return res, nil
+}
+
+// mark marks the new keys in the exact strategy without blocking the caller.
+// The failure is ignored, the retries in the retry window are found by the filter,
+// and the ones after it are caught by the unique constraint of notification.
+func (b *BloomStrategy) mark(keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), markTimeout)
+		defer cancel()
+
+		if len(keys) == 1 {
+			_, _ = b.exact.Exists(ctx, keys[0])
+			return
+		}
+		_, _ = b.exact.MultiExists(ctx, keys)
+	}()
+}
+
+func NewBloomStrategy(filter BloomFilter, exact Strategy) *BloomStrategy {
+	return &BloomStrategy{
+		filter: filter,
+		exact:  exact,
+	}
+}
@@ -0,0 +1,118 @@
+package idempotent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memStrategy is an exact strategy in memory recording the checked keys,
+// the checks are blocked until release is closed if it is not nil.
+type memStrategy struct {
+	mu      sync.Mutex
+	keys    map[string]struct{}
+	checked []string
+	release chan struct{}
+}
+
+func (m *memStrategy) Exists(ctx context.Context, key string) (bool, error) {
+	res, err := m.MultiExists(ctx, []string{key})
+	return res[key], err
+}
+
+func (m *memStrategy) MultiExists(_ context.Context, keys []string) (map[string]bool, error) {
+	if m.release != nil {
+		<-m.release
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		_, ok := m.keys[key]
+		res[key] = ok
+		m.keys[key] = struct{}{}
+		m.checked = append(m.checked, key)
+	}
+	return res, nil
+}
+
+func (m *memStrategy) has(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.keys[key]
+	return ok
+}
+
+func (m *memStrategy) getChecked() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.checked...)
+}
+
+func TestBloomStrategy_MultiExists(t *testing.T) {
+	t.Parallel()
+
+	filter, err := NewLocalBloomFilter(BloomConfig{
+		Capacity: 1000, FalsePositiveRate: 0.01, Window: time.Hour, RetryWindow: time.Hour,
+	})
+	require.NoError(t, err)
+
+	exact := &memStrategy{keys: map[string]struct{}{}}
+	strategy := NewBloomStrategy(filter, exact)
+
+	ctx := t.Context()
+
+	res, err := strategy.MultiExists(ctx, []string{"a", "b", "a"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"a": false, "b": false}, res)
+
+	// the new keys are marked in the exact strategy asynchronously
+	require.Eventually(t, func() bool {
+		return exact.has("a") && exact.has("b")
+	}, time.Second, time.Millisecond)
+
+	res, err = strategy.MultiExists(ctx, []string{"a", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"a": true, "c": false}, res)
+
+	exists, err := strategy.Exists(ctx, "b")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestBloomStrategy_BloomNegative(t *testing.T) {
+	t.Parallel()
+
+	filter, err := NewLocalBloomFilter(BloomConfig{
+		Capacity: 1000, FalsePositiveRate: 0.01, Window: time.Hour, RetryWindow: time.Hour,
+	})
+	require.NoError(t, err)
+
+	// the exact checks are blocked, the bloom negatives return without them
+	exact := &memStrategy{keys: map[string]struct{}{}, release: make(chan struct{})}
+	strategy := NewBloomStrategy(filter, exact)
+
+	ctx := t.Context()
+
+	exists, err := strategy.Exists(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	res, err := strategy.MultiExists(ctx, []string{"b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"b": false, "c": false}, res)
+	assert.Empty(t, exact.getChecked())
+
+	// the new keys are marked once the exact strategy is available
+	close(exact.release)
+	require.Eventually(t, func() bool {
+		return exact.has("a") && exact.has("b") && exact.has("c")
+	}, time.Second, time.Millisecond)
+}
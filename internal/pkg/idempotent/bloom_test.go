@@ -0,0 +1,123 @@
+package idempotent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloomConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name    string
+		cfg     BloomConfig
+		wantErr error
+	}{
+		{
+			name: "valid",
+			cfg:  BloomConfig{Capacity: 1000, FalsePositiveRate: 0.01, Window: time.Minute, RetryWindow: time.Hour},
+		}, {
+			name:    "zero capacity",
+			cfg:     BloomConfig{FalsePositiveRate: 0.01, Window: time.Minute, RetryWindow: time.Hour},
+			wantErr: errs.ErrInvalidParam,
+		}, {
+			name:    "false positive rate exceeds cap",
+			cfg:     BloomConfig{Capacity: 1000, FalsePositiveRate: 0.5, Window: time.Minute, RetryWindow: time.Hour},
+			wantErr: errs.ErrInvalidParam,
+		}, {
+			name:    "window less than 1ms",
+			cfg:     BloomConfig{Capacity: 1000, FalsePositiveRate: 0.01, Window: time.Microsecond, RetryWindow: time.Hour},
+			wantErr: errs.ErrInvalidParam,
+		}, {
+			name:    "zero retry window",
+			cfg:     BloomConfig{Capacity: 1000, FalsePositiveRate: 0.01, Window: time.Minute},
+			wantErr: errs.ErrInvalidParam,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.cfg.Validate()
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestLocalBloomFilter_TestAndAdd(t *testing.T) {
+	t.Parallel()
+
+	const capacity = 10000
+	cfg := BloomConfig{Capacity: capacity, FalsePositiveRate: 0.01, Window: time.Hour, RetryWindow: 3 * time.Hour}
+	filter, err := NewLocalBloomFilter(cfg)
+	require.NoError(t, err)
+
+	keys := make([]string, capacity/2)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("added_%d", i)
+	}
+	_, err = filter.TestAndAdd(t.Context(), keys...)
+	require.NoError(t, err)
+
+	// no false negative
+	res, err := filter.TestAndAdd(t.Context(), keys...)
+	require.NoError(t, err)
+	for i, maybe := range res {
+		assert.True(t, maybe, keys[i])
+	}
+
+	// false positive rate within the configured one
+	newKeys := make([]string, capacity/2)
+	for i := range newKeys {
+		newKeys[i] = fmt.Sprintf("new_%d", i)
+	}
+	res, err = filter.TestAndAdd(t.Context(), newKeys...)
+	require.NoError(t, err)
+
+	falsePositives := 0
+	for _, maybe := range res {
+		if maybe {
+			falsePositives++
+		}
+	}
+	assert.LessOrEqual(t, float64(falsePositives)/float64(len(newKeys)), cfg.FalsePositiveRate)
+}
+
+func TestLocalBloomFilter_Rotate(t *testing.T) {
+	t.Parallel()
+
+	// the retry window is covered by the current generation and 2 full ones
+	filter, err := NewLocalBloomFilter(BloomConfig{
+		Capacity: 2, FalsePositiveRate: 0.01, Window: time.Hour, RetryWindow: 90 * time.Minute,
+	})
+	require.NoError(t, err)
+	require.Len(t, filter.gens, 3)
+
+	now := time.Date(2025, 1, 1, 0, 30, 0, 0, time.UTC)
+	filter.now = func() time.Time { return now }
+
+	ctx := t.Context()
+
+	// the keys over the capacity do not rotate the generation
+	res, err := filter.TestAndAdd(ctx, "a", "b", "c")
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, false, false}, res)
+
+	// "a" is found in the retry window
+	now = now.Add(90 * time.Minute)
+	res, err = filter.TestAndAdd(ctx, "a", "d")
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, res)
+
+	// the generation of "b" is out of the retry window, "d" added later is still found
+	now = now.Add(90 * time.Minute)
+	res, err = filter.TestAndAdd(ctx, "b", "d")
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, true}, res)
+}
@@ -0,0 +1,53 @@
+package idempotent
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// CleanupTask is a background task that deletes the idempotent keys older than the ttl from DBStrategy periodically.
+// The ttl should cover the retry window of the biz, e.g. the ttl of the keys in redis.
+type CleanupTask struct {
+	strategy  *DBStrategy
+	ttl       time.Duration
+	batchSize int
+	interval  time.Duration
+	logger    *zap.Logger
+}
+
+func (t *CleanupTask) Start(ctx context.Context) {
+	go t.loop(ctx)
+}
+
+func (t *CleanupTask) loop(ctx context.Context) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cnt, err := t.strategy.Cleanup(ctx, time.Now().Add(-t.ttl), t.batchSize)
+			if err != nil {
+				t.logger.Error("[jotice] failed to clean up idempotent keys", zap.Int64("deleted", cnt), zap.Error(err))
+				continue
+			}
+			t.logger.Info("[jotice] idempotent keys cleaned up", zap.Int64("deleted", cnt))
+		}
+	}
+}
+
+func NewCleanupTask(
+	strategy *DBStrategy, ttl time.Duration, batchSize int, interval time.Duration, logger *zap.Logger,
+) *CleanupTask {
+	return &CleanupTask{
+		strategy:  strategy,
+		ttl:       ttl,
+		batchSize: batchSize,
+		interval:  interval,
+		logger:    logger,
+	}
+}
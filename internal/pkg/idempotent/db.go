@@ -0,0 +1,289 @@
+package idempotent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JrMarcco/easy-kit/xsync"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// idempotentKey entity definition of the sharded unique key table.
+type idempotentKey struct {
+	BizId     uint64 `gorm:"column:biz_id"`
+	BizKey    string `gorm:"column:biz_key"`
+	CreatedAt int64  `gorm:"column:created_at"`
+}
+
+var _ Strategy = (*DBStrategy)(nil)
+
+// DBStrategy records the keys in the sharded unique key table, the keys should be built by Key.
+// The table is sharded by the same sharding strategy as notification, with its own table prefix.
+//
+// The keys older than the retry window should be deleted by Cleanup (see CleanupTask),
+// the retries after that are caught by the unique constraint of notification.
+type DBStrategy struct {
+	dbs              *xsync.Map[string, *gorm.DB]
+	shardingStrategy sharding.Strategy
+}
+
+func (d *DBStrategy) Exists(ctx context.Context, key string) (bool, error) {
+	bizId, bizKey, ok := parseKey(key)
+	if !ok {
+		return false, fmt.Errorf("%w: invalid idempotent key %s", errs.ErrInvalidParam, key)
+	}
+
+	// a range strategy routes the key to the table of the current time,
+	// so the key recorded earlier is looked up in the recent tables before recording.
+	dst := d.shardingStrategy.Shard(bizId, bizKey)
+	for _, recent := range sharding.ShardRecent(d.shardingStrategy, bizId, bizKey) {
+		if recent == dst {
+			continue
+		}
+
+		exists, err := d.existsIn(ctx, recent, bizId, bizKey)
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+
+	db, ok := d.dbs.Load(dst.DB)
+	if !ok {
+		return false, fmt.Errorf("unknown db: %s", dst.DB)
+	}
+
+	res := db.WithContext(ctx).Table(dst.Table).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&idempotentKey{BizId: bizId, BizKey: bizKey, CreatedAt: time.Now().UnixMilli()})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 0, nil
+}
+
+func (d *DBStrategy) existsIn(ctx context.Context, dst sharding.Dst, bizId uint64, bizKey string) (bool, error) {
+	db, ok := d.dbs.Load(dst.DB)
+	if !ok {
+		return false, fmt.Errorf("unknown db: %s", dst.DB)
+	}
+
+	var cnt int64
+	err := db.WithContext(ctx).Table(dst.Table).
+		Where("biz_id = ? AND biz_key = ?", bizId, bizKey).
+		Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func (d *DBStrategy) MultiExists(ctx context.Context, keys []string) (map[string]bool, error) {
+	// group the keys by shard, and check the shards concurrently.
+	groups := make(map[sharding.Dst][]bizKeyPair)
+	seen := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		bizId, bizKey, ok := parseKey(key)
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid idempotent key %s", errs.ErrInvalidParam, key)
+		}
+
+		if _, ok = seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		dst := d.shardingStrategy.Shard(bizId, bizKey)
+		groups[dst] = append(groups[dst], bizKeyPair{bizId: bizId, bizKey: bizKey})
+	}
+
+	res := xsync.Map[string, bool]{}
+	eg, ctx := errgroup.WithContext(ctx)
+	for dst, pairs := range groups {
+		eg.Go(func() error {
+			exists, err := d.multiExistsIn(ctx, dst, pairs)
+			if err != nil {
+				return err
+			}
+			for pair, val := range exists {
+				res.Store(Key(pair.bizId, pair.bizKey), val)
+			}
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]bool, len(keys))
+	res.Range(func(key string, val bool) bool {
+		m[key] = val
+		return true
+	})
+	return m, nil
+}
+
+type bizKeyPair struct {
+	bizId  uint64
+	bizKey string
+}
+
+// multiExistsIn looks up the keys of the shard in their recent tables, then records the rest in one statement.
+func (d *DBStrategy) multiExistsIn(
+	ctx context.Context, dst sharding.Dst, pairs []bizKeyPair,
+) (map[bizKeyPair]bool, error) {
+	res := make(map[bizKeyPair]bool, len(pairs))
+
+	// a range strategy routes the keys to the table of the current time,
+	// so the keys recorded earlier are looked up in the recent tables before recording.
+	recentGroups := make(map[sharding.Dst][]bizKeyPair)
+	for _, pair := range pairs {
+		for _, recent := range sharding.ShardRecent(d.shardingStrategy, pair.bizId, pair.bizKey) {
+			if recent != dst {
+				recentGroups[recent] = append(recentGroups[recent], pair)
+			}
+		}
+	}
+	for recent, recentPairs := range recentGroups {
+		found, err := d.selectIn(ctx, recent, recentPairs)
+		if err != nil {
+			return nil, err
+		}
+		for _, pair := range found {
+			res[pair] = true
+		}
+	}
+
+	toInsert := make([]bizKeyPair, 0, len(pairs))
+	for _, pair := range pairs {
+		if !res[pair] {
+			toInsert = append(toInsert, pair)
+		}
+	}
+	if len(toInsert) == 0 {
+		return res, nil
+	}
+
+	inserted, err := d.insertIn(ctx, dst, toInsert)
+	if err != nil {
+		return nil, err
+	}
+
+	// the keys not inserted are recorded before
+	insertedSet := make(map[bizKeyPair]struct{}, len(inserted))
+	for _, pair := range inserted {
+		insertedSet[pair] = struct{}{}
+	}
+	for _, pair := range toInsert {
+		_, ok := insertedSet[pair]
+		res[pair] = !ok
+	}
+	return res, nil
+}
+
+// selectIn returns the keys recorded in the table.
+func (d *DBStrategy) selectIn(ctx context.Context, dst sharding.Dst, pairs []bizKeyPair) ([]bizKeyPair, error) {
+	db, ok := d.dbs.Load(dst.DB)
+	if !ok {
+		return nil, fmt.Errorf("unknown db: %s", dst.DB)
+	}
+
+	placeholders, args := pairPlaceholders(pairs, nil)
+	query := fmt.Sprintf(`SELECT biz_id, biz_key FROM "%s" WHERE (biz_id, biz_key) IN (%s)`, dst.Table, placeholders)
+
+	var found []idempotentKey
+	if err := db.WithContext(ctx).Raw(query, args...).Scan(&found).Error; err != nil {
+		return nil, err
+	}
+	return toPairs(found), nil
+}
+
+// insertIn records the keys in the table, returns the keys inserted, the others are recorded before.
+func (d *DBStrategy) insertIn(ctx context.Context, dst sharding.Dst, pairs []bizKeyPair) ([]bizKeyPair, error) {
+	db, ok := d.dbs.Load(dst.DB)
+	if !ok {
+		return nil, fmt.Errorf("unknown db: %s", dst.DB)
+	}
+
+	createdAt := time.Now().UnixMilli()
+	placeholders, args := pairPlaceholders(pairs, &createdAt)
+	query := fmt.Sprintf(
+		`INSERT INTO "%s" (biz_id, biz_key, created_at) VALUES %s ON CONFLICT DO NOTHING RETURNING biz_id, biz_key`,
+		dst.Table, placeholders,
+	)
+
+	var inserted []idempotentKey
+	if err := db.WithContext(ctx).Raw(query, args...).Scan(&inserted).Error; err != nil {
+		return nil, err
+	}
+	return toPairs(inserted), nil
+}
+
+// pairPlaceholders returns the placeholders and args of the keys, e.g. "(?, ?), (?, ?)",
+// with the created_at appended to each key if it is not nil.
+func pairPlaceholders(pairs []bizKeyPair, createdAt *int64) (string, []any) {
+	var sb strings.Builder
+	args := make([]any, 0, len(pairs)*3)
+	for i, pair := range pairs {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		args = append(args, pair.bizId, pair.bizKey)
+		if createdAt == nil {
+			sb.WriteString("(?, ?)")
+			continue
+		}
+		sb.WriteString("(?, ?, ?)")
+		args = append(args, *createdAt)
+	}
+	return sb.String(), args
+}
+
+func toPairs(entities []idempotentKey) []bizKeyPair {
+	pairs := make([]bizKeyPair, 0, len(entities))
+	for _, entity := range entities {
+		pairs = append(pairs, bizKeyPair{bizId: entity.BizId, bizKey: entity.BizKey})
+	}
+	return pairs
+}
+
+// Cleanup deletes the keys created before the time from all the shards in batches,
+// returns the number of keys deleted.
+func (d *DBStrategy) Cleanup(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	var deleted int64
+	for _, dst := range d.shardingStrategy.BroadCast() {
+		db, ok := d.dbs.Load(dst.DB)
+		if !ok {
+			return deleted, fmt.Errorf("unknown db: %s", dst.DB)
+		}
+
+		// delete in batches, so a large backlog does not hold the lock of the table for long.
+		query := fmt.Sprintf(
+			`DELETE FROM "%s" WHERE (biz_id, biz_key) IN (SELECT biz_id, biz_key FROM "%s" WHERE created_at < ? LIMIT ?)`,
+			dst.Table, dst.Table,
+		)
+		for {
+			res := db.WithContext(ctx).Exec(query, before.UnixMilli(), batchSize)
+			if res.Error != nil {
+				return deleted, fmt.Errorf("failed to clean up %s.%s, cause of: %w", dst.DB, dst.Table, res.Error)
+			}
+
+			deleted += res.RowsAffected
+			if res.RowsAffected < int64(batchSize) {
+				break
+			}
+		}
+	}
+	return deleted, nil
+}
+
+func NewDBStrategy(dbs *xsync.Map[string, *gorm.DB], shardingStrategy sharding.Strategy) *DBStrategy {
+	return &DBStrategy{
+		dbs:              dbs,
+		shardingStrategy: shardingStrategy,
+	}
+}
@@ -0,0 +1,160 @@
+package idempotent
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/easy-kit/xsync"
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var errFakeConn = errors.New("fake conn")
+
+// fakePool records the exec queries and returns the affected rows in order, then zero.
+type fakePool struct {
+	mu       sync.Mutex
+	queries  []string
+	args     [][]any
+	affected []int64
+}
+
+func (f *fakePool) PrepareContext(_ context.Context, _ string) (*sql.Stmt, error) {
+	return nil, errFakeConn
+}
+
+func (f *fakePool) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, args)
+
+	var affected int64
+	if len(f.affected) > 0 {
+		affected, f.affected = f.affected[0], f.affected[1:]
+	}
+	return driver.RowsAffected(affected), nil
+}
+
+// QueryContext records the query and fails it.
+func (f *fakePool) QueryContext(_ context.Context, query string, args ...any) (*sql.Rows, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, args)
+	return nil, errFakeConn
+}
+
+func (f *fakePool) QueryRowContext(_ context.Context, _ string, _ ...any) *sql.Row {
+	return nil
+}
+
+func TestDBStrategy_ExistsRecent(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakePool{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: fake}), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	dbs := &xsync.Map[string, *gorm.DB]{}
+	dbs.Store("jotice_0", db)
+
+	shardingStrategy := sharding.NewTimeRangeStrategy(
+		"jotice", "idempotent_key", 1, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 1, 1,
+	)
+	strategy := NewDBStrategy(dbs, shardingStrategy)
+
+	// the key is looked up in the table of the previous month before recording in the current one,
+	// so the failed lookup fails the check without recording the key.
+	_, err = strategy.Exists(t.Context(), Key(1, "biz_key"))
+	require.ErrorIs(t, err, errFakeConn)
+
+	recent := shardingStrategy.ShardRecent(1, "biz_key")
+	require.Len(t, recent, 2)
+	require.Len(t, fake.queries, 1)
+	assert.Contains(t, fake.queries[0], `FROM "`+recent[1].Table+`"`)
+	assert.Equal(t, []any{uint64(1), "biz_key"}, fake.args[0])
+}
+
+func TestDBStrategy_Cleanup(t *testing.T) {
+	t.Parallel()
+
+	// the keys of the first table are deleted in two full batches and a partial one
+	fakes := map[string]*fakePool{
+		"jotice_0": {affected: []int64{2, 2, 1}},
+		"jotice_1": {},
+	}
+
+	dbs := &xsync.Map[string, *gorm.DB]{}
+	for name, fake := range fakes {
+		db, err := gorm.Open(postgres.New(postgres.Config{Conn: fake}), &gorm.Config{})
+		require.NoError(t, err)
+		dbs.Store(name, db)
+	}
+
+	strategy := NewDBStrategy(dbs, sharding.NewHashStrategy("jotice", "idempotent_key", 2, 1))
+
+	before := time.Now().Add(-time.Hour)
+	deleted, err := strategy.Cleanup(t.Context(), before, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), deleted)
+
+	require.Len(t, fakes["jotice_0"].queries, 3)
+	require.Len(t, fakes["jotice_1"].queries, 1)
+	assert.Equal(t,
+		`DELETE FROM "idempotent_key_0" WHERE (biz_id, biz_key) IN `+
+			`(SELECT biz_id, biz_key FROM "idempotent_key_0" WHERE created_at < $1 LIMIT $2)`,
+		fakes["jotice_0"].queries[0],
+	)
+	assert.Equal(t, []any{before.UnixMilli(), 2}, fakes["jotice_0"].args[0])
+}
+
+func TestDBStrategy_MultiExists(t *testing.T) {
+	t.Parallel()
+
+	fakes := map[string]*fakePool{
+		"jotice_0": {},
+		"jotice_1": {},
+	}
+
+	dbs := &xsync.Map[string, *gorm.DB]{}
+	for name, fake := range fakes {
+		db, err := gorm.Open(postgres.New(postgres.Config{Conn: fake}), &gorm.Config{Logger: logger.Discard})
+		require.NoError(t, err)
+		dbs.Store(name, db)
+	}
+
+	shardingStrategy := sharding.NewHashStrategy("jotice", "idempotent_key", 2, 1)
+	strategy := NewDBStrategy(dbs, shardingStrategy)
+
+	keys := []string{Key(1, "a"), Key(1, "b"), Key(1, "c"), Key(1, "d"), Key(1, "a")}
+	_, err := strategy.MultiExists(t.Context(), keys)
+	require.ErrorIs(t, err, errFakeConn)
+
+	// the keys of a shard are recorded in one statement, the same key is recorded once
+	wantArgs := map[string]int{}
+	for _, bizKey := range []string{"a", "b", "c", "d"} {
+		wantArgs[shardingStrategy.Shard(1, bizKey).DB] += 3
+	}
+	for name, fake := range fakes {
+		if wantArgs[name] == 0 {
+			assert.Empty(t, fake.queries)
+			continue
+		}
+
+		require.Len(t, fake.queries, 1, name)
+		assert.Contains(t, fake.queries[0], "ON CONFLICT DO NOTHING RETURNING biz_id, biz_key")
+		assert.Len(t, fake.args[0], wantArgs[name])
+	}
+}
@@ -0,0 +1,136 @@
+package idempotent
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/pkg/ring"
+)
+
+const probeTimeout = time.Second
+
+type FailoverConfig struct {
+	// WindowSize, MinConsecutive and Threshold configure the error rate detector, see ring.NewBitRing.
+	WindowSize     int     `json:"window_size"`
+	MinConsecutive int     `json:"min_consecutive"`
+	Threshold      float64 `json:"threshold"`
+
+	// ProbeInterval is the interval to probe the primary strategy after failover.
+	ProbeInterval time.Duration `json:"probe_interval"`
+	// RecoverAfter is the number of consecutive successful probes to switch back to the primary strategy.
+	RecoverAfter int32 `json:"recover_after"`
+}
+
+var _ Strategy = (*FailoverStrategy)(nil)
+
+// FailoverStrategy uses the primary strategy (e.g. redis), and fails over to the fallback strategy (e.g. db)
+// when the error rate of the primary strategy detected by ring.BitRing triggers.
+//
+// After failover, the primary strategy is probed with the real keys (which also records them in the primary),
+// and it switches back after RecoverAfter consecutive successful probes.
+// The keys recorded only in one of the strategies during switching are caught by the unique constraint of notification.
+type FailoverStrategy struct {
+	primary  Strategy
+	fallback Strategy
+	cfg      FailoverConfig
+
+	detector       atomic.Pointer[ring.BitRing]
+	failedOver     atomic.Bool
+	lastProbe      atomic.Int64
+	probeSuccesses atomic.Int32
+}
+
+func (f *FailoverStrategy) Exists(ctx context.Context, key string) (bool, error) {
+	if f.failedOver.Load() {
+		f.probe(ctx, func(ctx context.Context) error {
+			_, err := f.primary.Exists(ctx, key)
+			return err
+		})
+		return f.fallback.Exists(ctx, key)
+	}
+
+	exists, err := f.primary.Exists(ctx, key)
+	if f.record(err) {
+		return exists, nil
+	}
+	return f.fallback.Exists(ctx, key)
+}
+
+func (f *FailoverStrategy) MultiExists(ctx context.Context, keys []string) (map[string]bool, error) {
+	if f.failedOver.Load() {
+		f.probe(ctx, func(ctx context.Context) error {
+			_, err := f.primary.MultiExists(ctx, keys)
+			return err
+		})
+		return f.fallback.MultiExists(ctx, keys)
+	}
+
+	res, err := f.primary.MultiExists(ctx, keys)
+	if f.record(err) {
+		return res, nil
+	}
+	return f.fallback.MultiExists(ctx, keys)
+}
+
+// FailedOver returns true if the fallback strategy is in use.
+func (f *FailoverStrategy) FailedOver() bool {
+	return f.failedOver.Load()
+}
+
+// record records the result of the primary strategy, returns true if the primary strategy succeeded.
+func (f *FailoverStrategy) record(err error) bool {
+	detector := f.detector.Load()
+	detector.Add(err != nil)
+	if err == nil {
+		return true
+	}
+
+	if detector.ShouldTrigger() && f.failedOver.CompareAndSwap(false, true) {
+		f.probeSuccesses.Store(0)
+		f.lastProbe.Store(time.Now().UnixMilli())
+	}
+	return false
+}
+
+// probe probes the primary strategy asynchronously, at most once per probe interval.
+func (f *FailoverStrategy) probe(ctx context.Context, fn func(ctx context.Context) error) {
+	now := time.Now().UnixMilli()
+	last := f.lastProbe.Load()
+	if now-last < f.cfg.ProbeInterval.Milliseconds() || !f.lastProbe.CompareAndSwap(last, now) {
+		return
+	}
+
+	go func() {
+		probeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), probeTimeout)
+		defer cancel()
+
+		if err := fn(probeCtx); err != nil {
+			f.probeSuccesses.Store(0)
+			return
+		}
+
+		if f.probeSuccesses.Add(1) >= f.cfg.RecoverAfter {
+			f.detector.Store(f.newDetector())
+			f.failedOver.Store(false)
+		}
+	}()
+}
+
+func (f *FailoverStrategy) newDetector() *ring.BitRing {
+	return ring.NewBitRing(f.cfg.WindowSize, f.cfg.MinConsecutive, f.cfg.Threshold)
+}
+
+func NewFailoverStrategy(primary, fallback Strategy, cfg FailoverConfig) *FailoverStrategy {
+	if cfg.RecoverAfter <= 0 {
+		cfg.RecoverAfter = 1
+	}
+
+	f := &FailoverStrategy{
+		primary:  primary,
+		fallback: fallback,
+		cfg:      cfg,
+	}
+	f.detector.Store(f.newDetector())
+	return f
+}
@@ -0,0 +1,78 @@
+package idempotent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyStrategy is a memStrategy which fails while down is set.
+type flakyStrategy struct {
+	memStrategy
+	down atomic.Bool
+}
+
+func (f *flakyStrategy) Exists(ctx context.Context, key string) (bool, error) {
+	if f.down.Load() {
+		return false, errors.New("connection refused")
+	}
+	return f.memStrategy.Exists(ctx, key)
+}
+
+func (f *flakyStrategy) MultiExists(ctx context.Context, keys []string) (map[string]bool, error) {
+	if f.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	return f.memStrategy.MultiExists(ctx, keys)
+}
+
+func TestFailoverStrategy(t *testing.T) {
+	t.Parallel()
+
+	primary := &flakyStrategy{memStrategy: memStrategy{keys: map[string]struct{}{}}}
+	fallback := &memStrategy{keys: map[string]struct{}{}}
+
+	strategy := NewFailoverStrategy(primary, fallback, FailoverConfig{
+		WindowSize:     10,
+		MinConsecutive: 3,
+		Threshold:      0.5,
+		ProbeInterval:  10 * time.Millisecond,
+		RecoverAfter:   2,
+	})
+
+	ctx := t.Context()
+
+	exists, err := strategy.Exists(ctx, "1:a")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.False(t, strategy.FailedOver())
+
+	// the errors of the primary are served by the fallback, and trigger the failover.
+	primary.down.Store(true)
+	for _, key := range []string{"1:b", "1:c", "1:d"} {
+		exists, err = strategy.Exists(ctx, key)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	}
+	assert.True(t, strategy.FailedOver())
+
+	exists, err = strategy.Exists(ctx, "1:b")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// switch back after the primary recovers.
+	primary.down.Store(false)
+	assert.Eventually(t, func() bool {
+		_, _ = strategy.Exists(ctx, "1:e")
+		return !strategy.FailedOver()
+	}, time.Second, 5*time.Millisecond)
+
+	exists, err = strategy.Exists(ctx, "1:a")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
@@ -0,0 +1,25 @@
+package idempotent
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Key returns the idempotent key of the biz key, e.g. "1:order_1".
+func Key(bizId uint64, bizKey string) string {
+	return strconv.FormatUint(bizId, 10) + ":" + bizKey
+}
+
+// parseKey parses the idempotent key returned by Key, the biz key may contain ":".
+func parseKey(key string) (uint64, string, bool) {
+	bizIdStr, bizKey, ok := strings.Cut(key, ":")
+	if !ok {
+		return 0, "", false
+	}
+
+	bizId, err := strconv.ParseUint(bizIdStr, 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return bizId, bizKey, true
+}
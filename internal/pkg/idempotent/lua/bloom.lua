@@ -0,0 +1,69 @@
+-- Time-slotted bloom filter.
+-- KEYS[1..g]: ring of the bitmaps of the generations, the generation of slot s is KEYS[s % g + 1],
+-- KEYS[g + 1]: meta {index: slot}, the slot each bitmap is holding.
+-- ARGV[1]: window in milliseconds, ARGV[2]: number of hash functions (k), ARGV[3...]: k bit positions of each key.
+-- Returns 1 for each key which might have been added in the retry window, 0 for the definitely new one.
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local window = tonumber(ARGV[1])
+local k = tonumber(ARGV[2])
+local n = (#ARGV - 2) / k
+local g = #KEYS - 1
+local meta = KEYS[g + 1]
+
+local slot = math.floor(now / window)
+local cur = slot % g + 1
+
+local fields = {}
+for j = 1, g do
+    fields[j] = j
+end
+local slots = redis.call('HMGET', meta, unpack(fields))
+
+-- the generation of a slot out of the retry window is stale
+local live = {}
+for j = 1, g do
+    local s = tonumber(slots[j])
+    live[j] = s ~= nil and s > slot - g
+end
+
+if tonumber(slots[cur]) ~= slot then
+    redis.call('DEL', KEYS[cur])
+    redis.call('HSET', meta, cur, slot)
+    live[cur] = true
+end
+
+local res = {}
+for i = 0, n - 1 do
+    local found = false
+    for j = 1, g do
+        if live[j] then
+            local all = true
+            for p = 1, k do
+                if redis.call('GETBIT', KEYS[j], tonumber(ARGV[2 + i * k + p])) == 0 then
+                    all = false
+                    break
+                end
+            end
+            if all then
+                found = true
+                break
+            end
+        end
+    end
+
+    for p = 1, k do
+        redis.call('SETBIT', KEYS[cur], tonumber(ARGV[2 + i * k + p]), 1)
+    end
+
+    if found then
+        res[i + 1] = 1
+    else
+        res[i + 1] = 0
+    end
+end
+
+redis.call('PEXPIRE', KEYS[cur], g * window)
+redis.call('PEXPIRE', meta, g * window)
+return res
@@ -0,0 +1,35 @@
+-- GCRA (generic cell rate algorithm) rate limit.
+-- KEYS: theoretical arrival time (in microseconds) of each rule.
+-- ARGV[1]: cost, ARGV[2i]: rate per second and ARGV[2i + 1]: burst in microseconds of KEYS[i].
+-- Returns {1, 0} if allowed, {0, retry_after_in_microseconds} if not.
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local cost = tonumber(ARGV[1])
+
+local new_tats = {}
+local retry_after = 0
+for i, key in ipairs(KEYS) do
+    local interval = 1000000 / tonumber(ARGV[2 * i])
+    local burst = tonumber(ARGV[2 * i + 1])
+
+    local tat = tonumber(redis.call('GET', key) or now)
+    if tat < now then
+        tat = now
+    end
+
+    local new_tat = tat + interval * cost
+    local allow_at = new_tat - burst
+    if allow_at > now then
+        retry_after = math.max(retry_after, allow_at - now)
+    end
+    new_tats[i] = new_tat
+end
+
+if retry_after > 0 then
+    return { 0, math.ceil(retry_after) }
+end
+
+for i, key in ipairs(KEYS) do
+    redis.call('SET', key, string.format('%d', new_tats[i]), 'PX', math.ceil((new_tats[i] - now) / 1000) + 1)
+end
+return { 1, 0 }
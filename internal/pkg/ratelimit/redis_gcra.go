@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	//go:embed lua/gcra.lua
+	gcraLua string
+
+	gcraScript = redis.NewScript(gcraLua)
+)
+
+var _ Limiter = (*RedisGCRALimiter)(nil)
+
+// RedisGCRALimiter is a distributed rate limiter using GCRA in redis.
+// The keys of the rules in one Limit call should be in the same slot if redis is a cluster.
+type RedisGCRALimiter struct {
+	rdb redis.Cmdable
+}
+
+func (r *RedisGCRALimiter) Limit(ctx context.Context, cost int64, rules ...Rule) (Result, error) {
+	if len(rules) == 0 || cost <= 0 {
+		return Result{Allowed: true}, nil
+	}
+
+	keys := make([]string, 0, len(rules))
+	args := make([]any, 0, 2*len(rules)+1)
+	args = append(args, cost)
+	for _, rule := range rules {
+		if rule.Rate <= 0 {
+			return Result{}, fmt.Errorf("rate of %s should be greater than 0", rule.Key)
+		}
+		// the cost over the capacity would be denied forever, reject it instead of hinting a retry.
+		if cost > rule.Capacity() {
+			return Result{}, fmt.Errorf("%w: cost %d, capacity of %s is %d", errs.ErrCostExceedsBurst, cost, rule.Key, rule.Capacity())
+		}
+		keys = append(keys, r.redisKey(rule.Key))
+		args = append(args, rule.Rate, rule.burst().Microseconds())
+	}
+
+	res, err := gcraScript.Run(ctx, r.rdb, keys, args...).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	if res[0] == 1 {
+		return Result{Allowed: true}, nil
+	}
+	return Result{RetryAfter: time.Duration(res[1]) * time.Microsecond}, nil
+}
+
+func (r *RedisGCRALimiter) redisKey(key string) string {
+	return "ratelimit:" + key
+}
+
+func NewRedisGCRALimiter(rdb redis.Cmdable) *RedisGCRALimiter {
+	return &RedisGCRALimiter{
+		rdb: rdb,
+	}
+}
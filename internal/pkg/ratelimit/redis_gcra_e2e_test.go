@@ -0,0 +1,85 @@
+//go:build e2e
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisGCRALimiter_Limit(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "192.168.3.3:6379",
+		Password: "<passwd>",
+	})
+	defer func() {
+		client.Del(ctx, "ratelimit:{test}:total", "ratelimit:{test}:bulk")
+		_ = client.Close()
+	}()
+
+	limiter := NewRedisGCRALimiter(client)
+
+	total := Rule{Key: "{test}:total", Rate: 10}
+	bulk := Rule{Key: "{test}:bulk", Rate: 5}
+
+	// the bulk rule allows a burst of 5
+	res, err := limiter.Limit(ctx, 5, total, bulk)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	res, err = limiter.Limit(ctx, 1, total, bulk)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Positive(t, res.RetryAfter)
+
+	// the total rule still has quota for the other requests
+	res, err = limiter.Limit(ctx, 5, total)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	res, err = limiter.Limit(ctx, 1, total)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+
+	// the cost over the capacity is rejected without a retry hint
+	_, err = limiter.Limit(ctx, 11, total)
+	assert.ErrorIs(t, err, errs.ErrCostExceedsBurst)
+}
+
+func TestRedisGCRALimiter_Burst(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "192.168.3.3:6379",
+		Password: "<passwd>",
+	})
+	defer func() {
+		client.Del(ctx, "ratelimit:{test}:burst")
+		_ = client.Close()
+	}()
+
+	limiter := NewRedisGCRALimiter(client)
+
+	// a burst of 3 seconds holds 30 tokens
+	rule := Rule{Key: "{test}:burst", Rate: 10, Burst: 3 * time.Second}
+
+	res, err := limiter.Limit(ctx, 30, rule)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	res, err = limiter.Limit(ctx, 1, rule)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Positive(t, res.RetryAfter)
+}
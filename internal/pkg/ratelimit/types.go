@@ -0,0 +1,43 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultBurst is the burst of the rule without Burst set.
+const DefaultBurst = time.Second
+
+// Rule limits the requests of the key to Rate per second,
+// allowing a burst of the tokens generated in Burst (DefaultBurst if not set).
+type Rule struct {
+	Key   string
+	Rate  int64
+	Burst time.Duration
+}
+
+// burst returns the burst duration of the rule.
+func (r Rule) burst() time.Duration {
+	if r.Burst <= 0 {
+		return DefaultBurst
+	}
+	return r.Burst
+}
+
+// Capacity returns the max tokens the rule allows at once.
+func (r Rule) Capacity() int64 {
+	return max(r.Rate*int64(r.burst())/int64(time.Second), 1)
+}
+
+type Result struct {
+	Allowed bool
+	// RetryAfter is the duration to wait before retrying, only set if not allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter rate limiter
+type Limiter interface {
+	// Limit takes cost tokens from all the rules or none of them.
+	// It returns errs.ErrCostExceedsBurst if the cost exceeds the capacity of any rule, which is never allowed.
+	Limit(ctx context.Context, cost int64, rules ...Rule) (Result, error)
+}
@@ -0,0 +1,30 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRule_Capacity(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name string
+		rule Rule
+		want int64
+	}{
+		{name: "default burst", rule: Rule{Rate: 10}, want: 10},
+		{name: "longer burst", rule: Rule{Rate: 10, Burst: 3 * time.Second}, want: 30},
+		{name: "shorter burst", rule: Rule{Rate: 10, Burst: 500 * time.Millisecond}, want: 5},
+		{name: "at least one", rule: Rule{Rate: 1, Burst: 100 * time.Millisecond}, want: 1},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, tc.rule.Capacity())
+		})
+	}
+}
@@ -5,6 +5,12 @@ import (
 	"time"
 
 	"github.com/JrMarcco/easy-kit/retry"
+	"github.com/JrMarcco/jotice/internal/errs"
+)
+
+const (
+	TypeFixedInterval      = "fixed_interval"
+	TypeExponentialBackoff = "exponential_backoff"
 )
 
 type Config struct {
@@ -24,11 +30,41 @@ type FixedIntervalConfig struct {
 	MaxTimes int32         `json:"max_times"`
 }
 
+// Validate checks the config has the matching sub config for its type.
+func (c Config) Validate() error {
+	switch c.Type {
+	case TypeFixedInterval:
+		if c.FixedInterval == nil {
+			return fmt.Errorf("%w: fixed interval config should not be nil", errs.ErrInvalidParam)
+		}
+		if c.FixedInterval.Interval <= 0 {
+			return fmt.Errorf("%w: interval should be greater than 0", errs.ErrInvalidParam)
+		}
+	case TypeExponentialBackoff:
+		if c.ExponentialBackoff == nil {
+			return fmt.Errorf("%w: exponential backoff config should not be nil", errs.ErrInvalidParam)
+		}
+		if c.ExponentialBackoff.InitInterval <= 0 {
+			return fmt.Errorf("%w: init interval should be greater than 0", errs.ErrInvalidParam)
+		}
+		if c.ExponentialBackoff.MaxInterval < c.ExponentialBackoff.InitInterval {
+			return fmt.Errorf("%w: max interval should not be less than init interval", errs.ErrInvalidParam)
+		}
+	default:
+		return fmt.Errorf("%w: unknown retry strategy type: %s", errs.ErrInvalidParam, c.Type)
+	}
+	return nil
+}
+
 func NewRetryStrategy(cfg Config) (retry.Strategy, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
 	switch cfg.Type {
-	case "fixed_interval":
+	case TypeFixedInterval:
 		return retry.NewFixedIntervalStrategy(cfg.FixedInterval.Interval, cfg.FixedInterval.MaxTimes)
-	case "exponential_backoff":
+	case TypeExponentialBackoff:
 		return retry.NewExponentialBackoffStrategy(
 			cfg.ExponentialBackoff.InitInterval,
 			cfg.ExponentialBackoff.MaxInterval,
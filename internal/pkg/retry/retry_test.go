@@ -0,0 +1,75 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name    string
+		cfg     Config
+		wantErr error
+	}{
+		{
+			name: "fixed interval",
+			cfg: Config{
+				Type:          TypeFixedInterval,
+				FixedInterval: &FixedIntervalConfig{Interval: time.Second, MaxTimes: 3},
+			},
+		}, {
+			name: "exponential backoff",
+			cfg: Config{
+				Type: TypeExponentialBackoff,
+				ExponentialBackoff: &ExponentialBackoffConfig{
+					InitInterval: time.Second,
+					MaxInterval:  time.Minute,
+					MaxTimes:     3,
+				},
+			},
+		}, {
+			name:    "unknown type",
+			cfg:     Config{Type: "unknown"},
+			wantErr: errs.ErrInvalidParam,
+		}, {
+			name: "mismatched sub config",
+			cfg: Config{
+				Type:          TypeExponentialBackoff,
+				FixedInterval: &FixedIntervalConfig{Interval: time.Second, MaxTimes: 3},
+			},
+			wantErr: errs.ErrInvalidParam,
+		}, {
+			name: "zero interval",
+			cfg: Config{
+				Type:          TypeFixedInterval,
+				FixedInterval: &FixedIntervalConfig{MaxTimes: 3},
+			},
+			wantErr: errs.ErrInvalidParam,
+		}, {
+			name: "max interval less than init interval",
+			cfg: Config{
+				Type: TypeExponentialBackoff,
+				ExponentialBackoff: &ExponentialBackoffConfig{
+					InitInterval: time.Minute,
+					MaxInterval:  time.Second,
+					MaxTimes:     3,
+				},
+			},
+			wantErr: errs.ErrInvalidParam,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.cfg.Validate()
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
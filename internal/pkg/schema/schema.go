@@ -0,0 +1,235 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"gorm.io/gorm"
+)
+
+const versionTable = "schema_migration"
+
+// Migration is a versioned ddl of a logical table, loaded from the file named as "{version}_{logical table}.sql".
+// The ddl is a text/template, {{.Table}} is replaced with the physical table, e.g. notification_3,
+// so the indexes of different physical tables should be named with it too.
+type Migration struct {
+	Version int
+	Table   string
+	SQL     string
+}
+
+// Statements renders the ddl of the physical table and splits it into statements.
+func (m Migration) Statements(table string) ([]string, error) {
+	tpl, err := template.New(fmt.Sprintf("%d_%s", m.Version, m.Table)).Parse(m.SQL)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = tpl.Execute(&buf, struct{ Table string }{Table: table}); err != nil {
+		return nil, err
+	}
+
+	var stmts []string
+	for _, stmt := range strings.Split(buf.String(), ";\n") {
+		if stmt = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(stmt), ";")); stmt != "" && !isComment(stmt) {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts, nil
+}
+
+func isComment(stmt string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+// Load loads the migrations in the dir of fsys, ordered by version.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		versionStr, table, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid migration file name %s", errs.ErrInvalidParam, entry.Name())
+		}
+
+		version, err := strconv.Atoi(versionStr)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("%w: invalid version of migration file %s", errs.ErrInvalidParam, entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Table:   table,
+			SQL:     string(content),
+		})
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		if a.Version != b.Version {
+			return a.Version - b.Version
+		}
+		return strings.Compare(a.Table, b.Table)
+	})
+	return migrations, nil
+}
+
+// Target is a physical table the migrations of the logical table are applied to.
+type Target struct {
+	DB      string
+	Logical string
+	Table   string
+}
+
+// Migrator applies the migrations to the physical tables.
+//
+// The versions applied are recorded for each physical table in the schema_migration table of its db,
+// so it is idempotent, and the physical tables added later, e.g. by adding shards, are migrated from the first version.
+type Migrator struct {
+	migrations []Migration
+	dryRun     bool
+	out        io.Writer
+}
+
+// Migrate applies the pending migrations of the targets, all the targets should be in the db.
+// In dry run mode, the sql of the pending migrations is printed instead.
+func (m *Migrator) Migrate(ctx context.Context, db *gorm.DB, targets []Target) error {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	dbName := targets[0].DB
+	db = db.WithContext(ctx)
+
+	createVersionTable := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s
+(
+    table_name VARCHAR(128) NOT NULL,
+    version    INTEGER      NOT NULL,
+    applied_at BIGINT       NOT NULL,
+    PRIMARY KEY (table_name, version)
+)`, versionTable)
+
+	if m.dryRun {
+		m.print(dbName, versionTable, 0, createVersionTable)
+	} else if err := db.Exec(createVersionTable).Error; err != nil {
+		return fmt.Errorf("failed to create %s, cause of: %w", versionTable, err)
+	}
+
+	applied, err := m.appliedVersions(db)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		for _, migration := range m.migrations {
+			if migration.Table != target.Logical || migration.Version <= applied[target.Table] {
+				continue
+			}
+
+			if err = m.apply(db, target, migration); err != nil {
+				return fmt.Errorf("failed to apply migration %d to %s.%s, cause of: %w",
+					migration.Version, target.DB, target.Table, err)
+			}
+		}
+	}
+	return nil
+}
+
+// appliedVersions returns the latest version applied of each physical table.
+func (m *Migrator) appliedVersions(db *gorm.DB) (map[string]int, error) {
+	type row struct {
+		TableName string
+		Version   int
+	}
+
+	var rows []row
+	err := db.Table(versionTable).
+		Select("table_name, MAX(version) AS version").
+		Group("table_name").
+		Find(&rows).Error
+	if err != nil {
+		if m.dryRun {
+			// the version table is not created in dry run mode.
+			return map[string]int{}, nil
+		}
+		return nil, fmt.Errorf("failed to get applied versions, cause of: %w", err)
+	}
+
+	res := make(map[string]int, len(rows))
+	for _, r := range rows {
+		res[r.TableName] = r.Version
+	}
+	return res, nil
+}
+
+func (m *Migrator) apply(db *gorm.DB, target Target, migration Migration) error {
+	stmts, err := migration.Statements(target.Table)
+	if err != nil {
+		return err
+	}
+
+	if m.dryRun {
+		m.print(target.DB, target.Table, migration.Version, stmts...)
+		return nil
+	}
+
+	// the ddl of postgres is transactional, so a migration is applied entirely or not at all.
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec(
+			fmt.Sprintf("INSERT INTO %s (table_name, version, applied_at) VALUES (?, ?, ?)", versionTable),
+			target.Table, migration.Version, time.Now().UnixMilli(),
+		).Error
+	})
+}
+
+func (m *Migrator) print(db, table string, version int, stmts ...string) {
+	_, _ = fmt.Fprintf(m.out, "-- db: %s, table: %s, version: %d\n", db, table, version)
+	for _, stmt := range stmts {
+		_, _ = fmt.Fprintf(m.out, "%s;\n", stmt)
+	}
+	_, _ = fmt.Fprintln(m.out)
+}
+
+// NewMigrator creates a Migrator, the sql is printed to out instead of executed in dry run mode.
+func NewMigrator(migrations []Migration, dryRun bool, out io.Writer) *Migrator {
+	return &Migrator{
+		migrations: migrations,
+		dryRun:     dryRun,
+		out:        out,
+	}
+}
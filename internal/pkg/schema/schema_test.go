@@ -0,0 +1,91 @@
+package schema
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name     string
+		fsys     fstest.MapFS
+		wantVers []int
+		wantErr  error
+	}{
+		{
+			name: "ordered by version",
+			fsys: fstest.MapFS{
+				"sql/0002_notification.sql": {Data: []byte("ALTER TABLE {{.Table}} ADD COLUMN a INT;")},
+				"sql/0001_notification.sql": {Data: []byte("CREATE TABLE {{.Table}} (id BIGINT);")},
+				"sql/0001_biz_config.sql":   {Data: []byte("CREATE TABLE {{.Table}} (id BIGINT);")},
+				"sql/README.md":             {Data: []byte("ignored")},
+			},
+			wantVers: []int{1, 1, 2},
+		}, {
+			name:    "without version",
+			fsys:    fstest.MapFS{"sql/notification.sql": {Data: []byte("")}},
+			wantErr: errs.ErrInvalidParam,
+		}, {
+			name:    "invalid version",
+			fsys:    fstest.MapFS{"sql/v1_notification.sql": {Data: []byte("")}},
+			wantErr: errs.ErrInvalidParam,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			migrations, err := Load(tc.fsys, "sql")
+			assert.ErrorIs(t, err, tc.wantErr)
+			if err != nil {
+				return
+			}
+
+			vers := make([]int, 0, len(migrations))
+			for _, m := range migrations {
+				vers = append(vers, m.Version)
+			}
+			assert.Equal(t, tc.wantVers, vers)
+			assert.Equal(t, "biz_config", migrations[0].Table)
+		})
+	}
+}
+
+func TestMigration_Statements(t *testing.T) {
+	t.Parallel()
+
+	m := Migration{
+		Version: 1,
+		Table:   "notification",
+		SQL: `-- notification sharding table
+CREATE TABLE IF NOT EXISTS {{.Table}}
+(
+    id     BIGINT PRIMARY KEY, -- snowflake id
+    biz_id BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_{{.Table}}_biz_id ON {{.Table}}(biz_id);
+
+-- trailing comment
+`,
+	}
+
+	stmts, err := m.Statements("notification_3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`-- notification sharding table
+CREATE TABLE IF NOT EXISTS notification_3
+(
+    id     BIGINT PRIMARY KEY, -- snowflake id
+    biz_id BIGINT NOT NULL
+)`,
+		`CREATE INDEX IF NOT EXISTS idx_notification_3_biz_id ON notification_3(biz_id)`,
+	}, stmts)
+}
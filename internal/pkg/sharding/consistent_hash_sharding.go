@@ -0,0 +1,145 @@
+package sharding
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+	"github.com/cespare/xxhash/v2"
+)
+
+var _ Strategy = (*ConsistentHashStrategy)(nil)
+
+const (
+	// hashSlots is the number of distinct hash values embedded in the snowflake id.
+	hashSlots = 1 << 10
+
+	defaultVirtualNodes = 160
+)
+
+// DBNode is a physical db on the consistent hash ring.
+type DBNode struct {
+	Suffix uint64
+	// Weight decides the number of virtual nodes of the db, and so the share of the keys it takes.
+	Weight int
+}
+
+type ringNode struct {
+	hash     uint64
+	dbSuffix uint64
+}
+
+// ConsistentHashStrategy is a consistent hash sharding strategy implementation.
+//
+// The dbs are placed on a hash ring with virtual nodes, so adding a db only moves the keys it takes over.
+// Keys are located on the ring by the 10-bit hash embedded in the snowflake id instead of the full hash,
+// so ShardWithId routes to the same Dst as Shard. The 1024 slots are resolved once when the strategy is created.
+type ConsistentHashStrategy struct {
+	dbPrefix    string
+	tablePrefix string
+
+	dbs           []uint64
+	tableSharding uint64
+
+	slots [hashSlots]Dst
+}
+
+func (c *ConsistentHashStrategy) Shard(bizId uint64, bizKey string) Dst {
+	return c.slots[snowflake.Hash(bizId, bizKey)]
+}
+
+func (c *ConsistentHashStrategy) ShardWithId(id uint64) Dst {
+	return c.slots[snowflake.ExtractHash(id)]
+}
+
+func (c *ConsistentHashStrategy) BroadCast() []Dst {
+	res := make([]Dst, 0, uint64(len(c.dbs))*c.tableSharding)
+	for _, dbSuffix := range c.dbs {
+		for j := uint64(0); j < c.tableSharding; j++ {
+			res = append(res, c.dst(dbSuffix, j))
+		}
+	}
+	return res
+}
+
+func (c *ConsistentHashStrategy) TablePrefix() string {
+	return c.tablePrefix
+}
+
+func (c *ConsistentHashStrategy) dst(dbSuffix, tableSuffix uint64) Dst {
+	return Dst{
+		DBSuffix:    dbSuffix,
+		TableSuffix: tableSuffix,
+		DB:          fmt.Sprintf("%s_%d", c.dbPrefix, dbSuffix),
+		Table:       fmt.Sprintf("%s_%d", c.tablePrefix, tableSuffix),
+	}
+}
+
+// NewConsistentHashStrategy creates a ConsistentHashStrategy,
+// each weight of a db takes virtualNodes virtual nodes on the ring, 160 if virtualNodes is not positive.
+func NewConsistentHashStrategy(
+	dbPrefix, tablePrefix string, nodes []DBNode, tableSharding uint64, virtualNodes int,
+) (*ConsistentHashStrategy, error) {
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%w: db nodes should not be empty", errs.ErrInvalidParam)
+	}
+
+	if tableSharding == 0 {
+		return nil, fmt.Errorf("%w: table sharding should be greater than 0", errs.ErrInvalidParam)
+	}
+
+	if virtualNodes <= 0 {
+		virtualNodes = defaultVirtualNodes
+	}
+
+	dbs := make([]uint64, 0, len(nodes))
+	ring := make([]ringNode, 0, len(nodes)*virtualNodes)
+	for _, node := range nodes {
+		if node.Weight <= 0 {
+			return nil, fmt.Errorf("%w: weight of db %d should be greater than 0", errs.ErrInvalidParam, node.Suffix)
+		}
+
+		if slices.Contains(dbs, node.Suffix) {
+			return nil, fmt.Errorf("%w: duplicate db %d", errs.ErrInvalidParam, node.Suffix)
+		}
+		dbs = append(dbs, node.Suffix)
+
+		for i := 0; i < node.Weight*virtualNodes; i++ {
+			ring = append(ring, ringNode{
+				hash:     xxhash.Sum64String(fmt.Sprintf("%s_%d#%d", dbPrefix, node.Suffix, i)),
+				dbSuffix: node.Suffix,
+			})
+		}
+	}
+
+	slices.Sort(dbs)
+	slices.SortFunc(ring, func(a, b ringNode) int {
+		// the same hash of different dbs, order by db to keep the ring deterministic.
+		return cmp.Or(cmp.Compare(a.hash, b.hash), cmp.Compare(a.dbSuffix, b.dbSuffix))
+	})
+
+	c := &ConsistentHashStrategy{
+		dbPrefix:      dbPrefix,
+		tablePrefix:   tablePrefix,
+		dbs:           dbs,
+		tableSharding: tableSharding,
+	}
+
+	for slot := uint64(0); slot < hashSlots; slot++ {
+		pos := xxhash.Sum64String(strconv.FormatUint(slot, 10))
+		i := sort.Search(len(ring), func(i int) bool {
+			return ring[i].hash >= pos
+		})
+		if i == len(ring) {
+			i = 0
+		}
+
+		// the table is decided by the slot only, so it is not moved when the db changes.
+		c.slots[slot] = c.dst(ring[i].dbSuffix, slot%tableSharding)
+	}
+	return c, nil
+}
@@ -0,0 +1,128 @@
+package sharding
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConsistentHashStrategy(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name          string
+		nodes         []DBNode
+		tableSharding uint64
+		wantErr       error
+	}{
+		{name: "valid", nodes: []DBNode{{Suffix: 0, Weight: 1}, {Suffix: 1, Weight: 2}}, tableSharding: 4},
+		{name: "empty nodes", tableSharding: 4, wantErr: errs.ErrInvalidParam},
+		{name: "zero table sharding", nodes: []DBNode{{Suffix: 0, Weight: 1}}, wantErr: errs.ErrInvalidParam},
+		{name: "zero weight", nodes: []DBNode{{Suffix: 0}}, tableSharding: 4, wantErr: errs.ErrInvalidParam},
+		{
+			name:          "duplicate db",
+			nodes:         []DBNode{{Suffix: 0, Weight: 1}, {Suffix: 0, Weight: 1}},
+			tableSharding: 4,
+			wantErr:       errs.ErrInvalidParam,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewConsistentHashStrategy("jotice", "notification", tc.nodes, tc.tableSharding, 0)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestConsistentHashStrategy_ShardWithId(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewConsistentHashStrategy(
+		"jotice", "notification", []DBNode{{Suffix: 0, Weight: 1}, {Suffix: 1, Weight: 1}, {Suffix: 2, Weight: 2}}, 4, 0,
+	)
+	require.NoError(t, err)
+
+	g := snowflake.NewGenerator()
+	for i := 0; i < 1000; i++ {
+		bizId := uint64(i%7 + 1)
+		bizKey := "biz_key_" + strconv.Itoa(i)
+
+		id, err := g.NextId(bizId, bizKey)
+		require.NoError(t, err)
+		assert.Equal(t, s.Shard(bizId, bizKey), s.ShardWithId(id))
+	}
+}
+
+func TestConsistentHashStrategy_BroadCast(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewConsistentHashStrategy(
+		"jotice", "notification", []DBNode{{Suffix: 2, Weight: 1}, {Suffix: 0, Weight: 3}}, 3, 0,
+	)
+	require.NoError(t, err)
+
+	dsts := s.BroadCast()
+	assert.Len(t, dsts, 6)
+
+	set := make(map[Dst]struct{}, len(dsts))
+	for _, dst := range dsts {
+		set[dst] = struct{}{}
+	}
+
+	// every dst routed to is in the broadcast.
+	for slot := uint64(0); slot < hashSlots; slot++ {
+		_, ok := set[s.slots[slot]]
+		assert.True(t, ok)
+	}
+}
+
+func TestConsistentHashStrategy_AddDB(t *testing.T) {
+	t.Parallel()
+
+	nodes := []DBNode{{Suffix: 0, Weight: 1}, {Suffix: 1, Weight: 1}, {Suffix: 2, Weight: 1}}
+	before, err := NewConsistentHashStrategy("jotice", "notification", nodes, 4, 0)
+	require.NoError(t, err)
+
+	after, err := NewConsistentHashStrategy("jotice", "notification", append(nodes, DBNode{Suffix: 3, Weight: 1}), 4, 0)
+	require.NoError(t, err)
+
+	moved := 0
+	for slot := uint64(0); slot < hashSlots; slot++ {
+		if before.slots[slot] == after.slots[slot] {
+			continue
+		}
+
+		moved++
+		// keys are only moved to the new db, and stay in the same table.
+		assert.Equal(t, uint64(3), after.slots[slot].DBSuffix)
+		assert.Equal(t, before.slots[slot].TableSuffix, after.slots[slot].TableSuffix)
+	}
+
+	// about 1/4 of the keys are expected to move, far less than the modulo sharding.
+	assert.Greater(t, moved, hashSlots/8)
+	assert.Less(t, moved, hashSlots*3/8)
+}
+
+func TestConsistentHashStrategy_Weight(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewConsistentHashStrategy(
+		"jotice", "notification", []DBNode{{Suffix: 0, Weight: 1}, {Suffix: 1, Weight: 3}}, 4, 0,
+	)
+	require.NoError(t, err)
+
+	counts := make(map[uint64]int)
+	for slot := uint64(0); slot < hashSlots; slot++ {
+		counts[s.slots[slot].DBSuffix]++
+	}
+
+	// the db with 3 times the weight takes about 3/4 of the keys.
+	assert.InDelta(t, hashSlots*3/4, counts[1], hashSlots/10)
+}
@@ -0,0 +1,196 @@
+package sharding
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	_ gorm.ConnPool         = (*ConnPool)(nil)
+	_ gorm.ConnPoolBeginner = (*ConnPool)(nil)
+	_ gorm.Plugin           = (*Plugin)(nil)
+)
+
+// ConnPool is a gorm.ConnPool choosing the connection pool of the db by the Dst in context.
+//
+// It should be used with Plugin, which rewrites the logical table to the sharding table,
+// so the DAO can be written as plain gorm code with a context carrying the Dst:
+//
+//	ctx = sharding.ContextWitDst(ctx, strategy.Shard(bizId, bizKey))
+//	db.WithContext(ctx).Where("biz_key = ?", bizKey).First(&notification)
+type ConnPool struct {
+	pools map[string]gorm.ConnPool
+}
+
+func (p *ConnPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	pool, err := p.selectPool(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pool.PrepareContext(ctx, query)
+}
+
+func (p *ConnPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	pool, err := p.selectPool(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pool.ExecContext(ctx, query, args...)
+}
+
+func (p *ConnPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	pool, err := p.selectPool(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pool.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext returns a *sql.Row carrying the error if the pool can not be chosen, e.g. no Dst in context,
+// the error is returned by its Scan.
+func (p *ConnPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	pool, err := p.selectPool(ctx)
+	if err != nil {
+		return errRow(ctx, err)
+	}
+	return pool.QueryRowContext(ctx, query, args...)
+}
+
+// errRow builds a *sql.Row carrying the error, which can not be built outside database/sql,
+// so it is queried from a db of which the connection always fails with the error.
+func errRow(ctx context.Context, err error) *sql.Row {
+	db := sql.OpenDB(errConnector{err: err})
+	defer func() { _ = db.Close() }()
+	return db.QueryRowContext(ctx, "")
+}
+
+// errConnector is a driver.Connector failing all the connections with err.
+type errConnector struct {
+	err error
+}
+
+func (c errConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, c.err
+}
+
+func (c errConnector) Driver() driver.Driver {
+	return errDriver(c)
+}
+
+type errDriver errConnector
+
+func (d errDriver) Open(string) (driver.Conn, error) {
+	return nil, d.err
+}
+
+// BeginTx begins a local transaction in the db of the Dst in context.
+// All the statements in the transaction run in that db, no matter what Dst they carry.
+func (p *ConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	pool, err := p.selectPool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch beginner := pool.(type) {
+	case gorm.TxBeginner:
+		return beginner.BeginTx(ctx, opts)
+	case gorm.ConnPoolBeginner:
+		return beginner.BeginTx(ctx, opts)
+	default:
+		return nil, gorm.ErrInvalidTransaction
+	}
+}
+
+func (p *ConnPool) selectPool(ctx context.Context) (gorm.ConnPool, error) {
+	dst, ok := DstFromContext(ctx)
+	if !ok {
+		return nil, errs.ErrMissingShardingDst
+	}
+
+	pool, ok := p.pools[dst.DB]
+	if !ok {
+		return nil, fmt.Errorf("unknown db: %s", dst.DB)
+	}
+	return pool, nil
+}
+
+// NewConnPool creates a ConnPool, the pools are keyed by the db name of Dst.
+func NewConnPool(pools map[string]gorm.ConnPool) *ConnPool {
+	return &ConnPool{
+		pools: pools,
+	}
+}
+
+// Plugin is a gorm plugin rewriting the table of each statement to the Dst.Table in context,
+// so the table is named by the strategy, e.g. the table prefix of HashStrategy or the month of TimeRangeStrategy,
+// not by the logical table of the model. The Dst should be routed by the strategy of the statement's table.
+// Statements without a Dst in context fail with errs.ErrMissingShardingDst.
+//
+// Raw sql is not rewritten, only its connection is chosen by ConnPool.
+type Plugin struct{}
+
+const routedTableKey = "jotice:sharding:routed_table"
+
+func (p *Plugin) Name() string {
+	return "jotice:sharding"
+}
+
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	const callbackName = "jotice:sharding"
+
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register(callbackName, p.route); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register(callbackName, p.route); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register(callbackName, p.route); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register(callbackName, p.route); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register(callbackName, p.route); err != nil {
+		return err
+	}
+	return cb.Raw().Before("gorm:raw").Register(callbackName, p.route)
+}
+
+func (p *Plugin) route(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+
+	dst, ok := DstFromContext(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(errs.ErrMissingShardingDst)
+		return
+	}
+
+	stmt := db.Statement
+	if stmt.Table == "" {
+		return
+	}
+
+	// the statement may be executed more than once, e.g. Count then Find, do not rewrite it twice.
+	if routed, ok := stmt.Settings.Load(routedTableKey); ok && routed == stmt.Table {
+		return
+	}
+
+	stmt.Table = dst.Table
+	if stmt.TableExpr != nil {
+		stmt.TableExpr = &clause.Expr{SQL: stmt.Quote(stmt.Table)}
+	}
+	stmt.Settings.Store(routedTableKey, stmt.Table)
+}
+
+func NewPlugin() *Plugin {
+	return &Plugin{}
+}
@@ -0,0 +1,177 @@
+package sharding
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var errFakeConn = errors.New("fake conn")
+
+type testNotification struct {
+	Id     uint64
+	BizKey string
+}
+
+func (testNotification) TableName() string {
+	return "notification"
+}
+
+// fakePool records the queries and fails all of them.
+type fakePool struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (f *fakePool) record(query string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = append(f.queries, query)
+}
+
+func (f *fakePool) PrepareContext(_ context.Context, query string) (*sql.Stmt, error) {
+	f.record(query)
+	return nil, errFakeConn
+}
+
+func (f *fakePool) ExecContext(_ context.Context, query string, _ ...any) (sql.Result, error) {
+	f.record(query)
+	return nil, errFakeConn
+}
+
+func (f *fakePool) QueryContext(_ context.Context, query string, _ ...any) (*sql.Rows, error) {
+	f.record(query)
+	return nil, errFakeConn
+}
+
+func (f *fakePool) QueryRowContext(_ context.Context, query string, _ ...any) *sql.Row {
+	f.record(query)
+	return nil
+}
+
+func (f *fakePool) BeginTx(_ context.Context, _ *sql.TxOptions) (gorm.ConnPool, error) {
+	return f, nil
+}
+
+func (f *fakePool) Commit() error {
+	return nil
+}
+
+func (f *fakePool) Rollback() error {
+	return nil
+}
+
+func newTestDB(t *testing.T) (*gorm.DB, map[string]*fakePool) {
+	fakes := map[string]*fakePool{"jotice_0": {}, "jotice_1": {}}
+	pools := make(map[string]gorm.ConnPool, len(fakes))
+	for name, fake := range fakes {
+		pools[name] = fake
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: NewConnPool(pools)}), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(NewPlugin()))
+	return db, fakes
+}
+
+func TestPlugin(t *testing.T) {
+	t.Parallel()
+
+	dst := Dst{DBSuffix: 1, TableSuffix: 2, DB: "jotice_1", Table: "notification_2"}
+
+	tcs := []struct {
+		name      string
+		ctx       context.Context
+		exec      func(db *gorm.DB) error
+		wantErr   error
+		wantQuery string
+	}{
+		{
+			name: "query",
+			ctx:  ContextWitDst(context.Background(), dst),
+			exec: func(db *gorm.DB) error {
+				var n testNotification
+				return db.Where("biz_key = ?", "key").First(&n).Error
+			},
+			wantErr:   errFakeConn,
+			wantQuery: `SELECT * FROM "notification_2" WHERE biz_key = $1 ORDER BY "notification_2"."id" LIMIT $2`,
+		}, {
+			name: "explicit logical table",
+			ctx:  ContextWitDst(context.Background(), dst),
+			exec: func(db *gorm.DB) error {
+				var ns []testNotification
+				return db.Table("notification").Where("id = ?", 1).Find(&ns).Error
+			},
+			wantErr:   errFakeConn,
+			wantQuery: `SELECT * FROM "notification_2" WHERE id = $1`,
+		}, {
+			name: "create in transaction",
+			ctx:  ContextWitDst(context.Background(), dst),
+			exec: func(db *gorm.DB) error {
+				return db.Transaction(func(tx *gorm.DB) error {
+					return tx.Create(&testNotification{Id: 1, BizKey: "key"}).Error
+				})
+			},
+			wantErr:   errFakeConn,
+			wantQuery: `INSERT INTO "notification_2" ("biz_key","id") VALUES ($1,$2) RETURNING "id"`,
+		}, {
+			name: "table prefix differs from the model",
+			// jotice_1.notif_1
+			ctx: ContextWitDst(context.Background(), NewHashStrategy("jotice", "notif", 2, 2).BroadCast()[3]),
+			exec: func(db *gorm.DB) error {
+				var n testNotification
+				return db.Where("biz_key = ?", "key").First(&n).Error
+			},
+			wantErr:   errFakeConn,
+			wantQuery: `SELECT * FROM "notif_1" WHERE biz_key = $1 ORDER BY "notif_1"."id" LIMIT $2`,
+		}, {
+			name: "missing dst",
+			ctx:  context.Background(),
+			exec: func(db *gorm.DB) error {
+				var n testNotification
+				return db.First(&n).Error
+			},
+			wantErr: errs.ErrMissingShardingDst,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, fakes := newTestDB(t)
+			err := tc.exec(db.WithContext(tc.ctx))
+			assert.ErrorIs(t, err, tc.wantErr)
+
+			assert.Empty(t, fakes["jotice_0"].queries)
+			if tc.wantQuery == "" {
+				assert.Empty(t, fakes["jotice_1"].queries)
+				return
+			}
+			assert.Equal(t, []string{tc.wantQuery}, fakes["jotice_1"].queries)
+		})
+	}
+}
+
+func TestConnPool_QueryRowContext(t *testing.T) {
+	t.Parallel()
+
+	pool := NewConnPool(map[string]gorm.ConnPool{"jotice_0": &fakePool{}})
+
+	// the row carries the error instead of panicking
+	var id uint64
+	err := pool.QueryRowContext(context.Background(), "SELECT id FROM notification").Scan(&id)
+	assert.ErrorIs(t, err, errs.ErrMissingShardingDst)
+
+	ctx := ContextWitDst(context.Background(), Dst{DB: "jotice_2", Table: "notification_0"})
+	err = pool.QueryRowContext(ctx, "SELECT id FROM notification").Scan(&id)
+	assert.ErrorContains(t, err, "unknown db: jotice_2")
+}
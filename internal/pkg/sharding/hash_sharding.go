@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
-	"github.com/cespare/xxhash/v2"
 )
 
 var _ Strategy = (*HashStrategy)(nil)
@@ -20,7 +19,7 @@ type HashStrategy struct {
 }
 
 func (h HashStrategy) Shard(bizId uint64, bizKey string) Dst {
-	hashVal := xxhash.Sum64String(snowflake.HashKey(bizId, bizKey))
+	hashVal := snowflake.Hash(bizId, bizKey)
 	dbSuffix := hashVal % h.dbSharding
 	tableSuffix := (hashVal / h.dbSharding) % h.tableSharding
 	return Dst{
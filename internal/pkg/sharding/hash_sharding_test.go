@@ -0,0 +1,41 @@
+package sharding
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashStrategy_ShardWithId(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name          string
+		dbSharding    uint64
+		tableSharding uint64
+	}{
+		{name: "2 dbs and 4 tables", dbSharding: 2, tableSharding: 4},
+		{name: "3 dbs and 5 tables", dbSharding: 3, tableSharding: 5},
+		{name: "single db and table", dbSharding: 1, tableSharding: 1},
+	}
+
+	g := snowflake.NewGenerator()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := NewHashStrategy("jotice", "notification", tc.dbSharding, tc.tableSharding)
+			for i := 0; i < 1000; i++ {
+				bizId := uint64(i%7 + 1)
+				bizKey := "biz_key_" + strconv.Itoa(i)
+
+				id, err := g.NextId(bizId, bizKey)
+				require.NoError(t, err)
+				assert.Equal(t, s.Shard(bizId, bizKey), s.ShardWithId(id))
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package migration
+
+import (
+	"encoding/binary"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// summary is the row count and the order independent checksum of a set of rows.
+type summary struct {
+	count    int64
+	checksum uint64
+}
+
+func (s summary) add(id uint64, updatedAt int64) summary {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], id)
+	binary.BigEndian.PutUint64(buf[8:], uint64(updatedAt))
+
+	return summary{
+		count:    s.count + 1,
+		checksum: s.checksum + xxhash.Sum64(buf[:]),
+	}
+}
+
+func (s summary) merge(other summary) summary {
+	return summary{
+		count:    s.count + other.count,
+		checksum: s.checksum + other.checksum,
+	}
+}
@@ -0,0 +1,135 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const keyPrefix = "/config/sharding"
+
+var _ Store = (*EtcdStore)(nil)
+
+// EtcdStore is the etcd implementation of Store, the keys are:
+//   - /config/sharding/{name}/phase
+//   - /config/sharding/{name}/checkpoint/{db}/{table}
+type EtcdStore struct {
+	client *clientv3.Client
+	name   string
+}
+
+func (s *EtcdStore) Phase(ctx context.Context) (Phase, error) {
+	resp, err := s.client.Get(ctx, s.phaseKey())
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.Kvs) == 0 {
+		return PhaseOld, nil
+	}
+	return Phase(resp.Kvs[0].Value), nil
+}
+
+func (s *EtcdStore) CompareAndSwapPhase(ctx context.Context, old, new Phase) (bool, error) {
+	key := s.phaseKey()
+	put := clientv3.OpPut(key, string(new))
+	swap := []clientv3.Cmp{clientv3.Compare(clientv3.Value(key), "=", string(old))}
+
+	if old != PhaseOld {
+		resp, err := s.client.Txn(ctx).If(swap...).Then(put).Commit()
+		if err != nil {
+			return false, err
+		}
+		return resp.Succeeded, nil
+	}
+
+	// the phase is old if it has not been set, or has been set to old explicitly.
+	resp, err := s.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(put).
+		Else(clientv3.OpTxn(swap, []clientv3.Op{put}, nil)).
+		Commit()
+	if err != nil {
+		return false, err
+	}
+
+	if resp.Succeeded {
+		return true, nil
+	}
+	return resp.Responses[0].GetResponseTxn().Succeeded, nil
+}
+
+func (s *EtcdStore) WatchPhase(ctx context.Context) <-chan Phase {
+	watchChan := s.client.Watch(ctx, s.phaseKey())
+	phaseChan := make(chan Phase)
+
+	go func() {
+		defer close(phaseChan)
+
+		for resp := range watchChan {
+			if resp.Canceled {
+				return
+			}
+
+			if err := resp.Err(); err != nil {
+				continue
+			}
+
+			for _, event := range resp.Events {
+				phase := PhaseOld
+				if event.Type == clientv3.EventTypePut {
+					phase = Phase(event.Kv.Value)
+				}
+
+				select {
+				case phaseChan <- phase:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return phaseChan
+}
+
+func (s *EtcdStore) Checkpoint(ctx context.Context, dst sharding.Dst) (uint64, error) {
+	resp, err := s.client.Get(ctx, s.checkpointKey(dst))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(resp.Kvs) == 0 {
+		return 0, nil
+	}
+	return strconv.ParseUint(string(resp.Kvs[0].Value), 10, 64)
+}
+
+func (s *EtcdStore) SaveCheckpoint(ctx context.Context, dst sharding.Dst, id uint64) error {
+	_, err := s.client.Put(ctx, s.checkpointKey(dst), strconv.FormatUint(id, 10))
+	return err
+}
+
+func (s *EtcdStore) ResetCheckpoints(ctx context.Context) error {
+	_, err := s.client.Delete(ctx, fmt.Sprintf("%s/%s/checkpoint/", keyPrefix, s.name), clientv3.WithPrefix())
+	return err
+}
+
+func (s *EtcdStore) phaseKey() string {
+	return fmt.Sprintf("%s/%s/phase", keyPrefix, s.name)
+}
+
+func (s *EtcdStore) checkpointKey(dst sharding.Dst) string {
+	return fmt.Sprintf("%s/%s/checkpoint/%s/%s", keyPrefix, s.name, dst.DB, dst.Table)
+}
+
+// NewEtcdStore creates an EtcdStore, the name identifies the resharding, e.g. "notification".
+func NewEtcdStore(client *clientv3.Client, name string) *EtcdStore {
+	return &EtcdStore{
+		client: client,
+		name:   name,
+	}
+}
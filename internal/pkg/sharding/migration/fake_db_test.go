@@ -0,0 +1,244 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/JrMarcco/easy-kit/xsync"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	errFakeDriver = errors.New("fake driver")
+
+	selectRegexp = regexp.MustCompile(`^SELECT (.+) FROM "(\w+)" WHERE id > \$1 ORDER BY id ASC LIMIT \$2$`)
+	insertRegexp = regexp.MustCompile(`^INSERT INTO "(\w+)" \(([^)]+)\) VALUES .+ ON CONFLICT \("id"\) DO UPDATE SET`)
+	deleteRegexp = regexp.MustCompile(`^DELETE FROM "(\w+)" WHERE id IN \(.+\)$`)
+)
+
+// fakeRow is a row of the tables of fakeDB, which has the id, updated_at and status columns.
+type fakeRow struct {
+	id        int64
+	updatedAt int64
+	status    string
+}
+
+// fakeDB is an in memory database/sql driver of the tables,
+// which runs the batch reads and the upserts guarded by updated_at of Migrator.
+type fakeDB struct {
+	mu     sync.Mutex
+	tables map[string]map[int64]fakeRow
+}
+
+func (f *fakeDB) put(table string, row fakeRow) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.tables[table] == nil {
+		f.tables[table] = make(map[int64]fakeRow)
+	}
+	f.tables[table][row.id] = row
+}
+
+func (f *fakeDB) remove(table string, id int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.tables[table], id)
+}
+
+func (f *fakeDB) get(table string, id int64) (fakeRow, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	row, ok := f.tables[table][id]
+	return row, ok
+}
+
+func (f *fakeDB) query(query string, args []driver.NamedValue) ([]string, [][]driver.Value, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if matches := selectRegexp.FindStringSubmatch(query); matches != nil {
+		return f.find(matches[1], matches[2], args[0].Value.(int64), args[1].Value.(int64))
+	}
+
+	if matches := insertRegexp.FindStringSubmatch(query); matches != nil {
+		return nil, nil, f.upsert(matches[1], matches[2], args)
+	}
+	return nil, nil, fmt.Errorf("%w: unexpected query %s", errFakeDriver, query)
+}
+
+// exec runs the deletes, and the queries of which the rows are discarded.
+func (f *fakeDB) exec(query string, args []driver.NamedValue) (int64, error) {
+	matches := deleteRegexp.FindStringSubmatch(query)
+	if matches == nil {
+		_, _, err := f.query(query, args)
+		return 0, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var deleted int64
+	for _, arg := range args {
+		if _, ok := f.tables[matches[1]][arg.Value.(int64)]; ok {
+			delete(f.tables[matches[1]], arg.Value.(int64))
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// find returns the rows after the start id, with the columns selected of id, updated_at and status in order.
+func (f *fakeDB) find(selected, table string, startId, limit int64) ([]string, [][]driver.Value, error) {
+	columns := []string{"id", "updated_at", "status"}
+	if selected != "*" {
+		columns = columns[:len(strings.Split(selected, ","))]
+	}
+
+	var rows [][]driver.Value
+	for _, id := range slices.Sorted(maps.Keys(f.tables[table])) {
+		if id <= startId || int64(len(rows)) == limit {
+			continue
+		}
+
+		row := f.tables[table][id]
+		rows = append(rows, []driver.Value{row.id, row.updatedAt, row.status}[:len(columns)])
+	}
+	return columns, rows, nil
+}
+
+func (f *fakeDB) upsert(table, quoted string, args []driver.NamedValue) error {
+	columns := strings.Split(strings.ReplaceAll(quoted, `"`, ""), ",")
+	if f.tables[table] == nil {
+		f.tables[table] = make(map[int64]fakeRow)
+	}
+
+	for i := 0; i+len(columns) <= len(args); i += len(columns) {
+		var row fakeRow
+		for j, column := range columns {
+			switch val := args[i+j].Value; column {
+			case "id":
+				row.id = val.(int64)
+			case "updated_at":
+				row.updatedAt = val.(int64)
+			case "status":
+				row.status = val.(string)
+			}
+		}
+
+		if old, ok := f.tables[table][row.id]; ok && old.updatedAt >= row.updatedAt {
+			continue
+		}
+		f.tables[table][row.id] = row
+	}
+	return nil
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{db: f}, nil
+}
+
+func (f *fakeDB) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errFakeDriver
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errFakeDriver
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+// Begin begins a transaction doing nothing, the statements are applied at once.
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	affected, err := c.db.exec(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(affected), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	columns, rows, err := c.db.query(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: columns, rows: rows}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// newFakeDBs opens a gorm db on a fakeDB for each name.
+func newFakeDBs(t *testing.T, names ...string) (*xsync.Map[string, *gorm.DB], map[string]*fakeDB) {
+	dbs := &xsync.Map[string, *gorm.DB]{}
+	fakes := make(map[string]*fakeDB, len(names))
+	for _, name := range names {
+		fake := &fakeDB{tables: make(map[string]map[int64]fakeRow)}
+		db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(fake)}), &gorm.Config{Logger: logger.Discard})
+		require.NoError(t, err)
+
+		dbs.Store(name, db)
+		fakes[name] = fake
+	}
+	return dbs, fakes
+}
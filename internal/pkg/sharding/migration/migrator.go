@@ -0,0 +1,536 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/JrMarcco/easy-kit/xsync"
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errPhaseChanged = errors.New("resharding phase has been changed by others")
+	errNotVerified  = errors.New("resharding is not verified")
+	errUnknownShard = errors.New("unknown shard")
+)
+
+const defaultBatchSize = 1000
+
+// Config is the config of the data copy, each batch is followed by an interval to limit the load of the dbs.
+// BatchSize defaults to 1000.
+type Config struct {
+	BatchSize int
+	Interval  time.Duration
+}
+
+// Layout is a table in resharding, routed by From before the switch and by To after it.
+type Layout struct {
+	From sharding.Strategy
+	To   sharding.Strategy
+}
+
+// Migrator moves the rows of the tables from their old layouts to the new ones.
+//
+// A resharding goes through:
+//  1. StartDoubleWrite, the new rows are written to both layouts from now on.
+//  2. Copy, the existing rows are copied to the new layout, it resumes from the checkpoints after a crash.
+//     ResetCheckpoints makes it start over, and Recopy copies a single shard again.
+//  3. Verify, the row counts and checksums of each new shard are compared with the rows of the old layout.
+//  4. Switch, the routing is switched to the new layout if verified.
+//  5. Purge, the rows left in the old layout are deleted.
+//
+// The tables of a resharding are switched together, e.g. notification and callback_log which should stay
+// in the same db, so their SwitchableStrategy should share the Store of the Migrator.
+// The tables should have the "id" primary key of snowflake id and the "updated_at" column.
+type Migrator struct {
+	dbs *xsync.Map[string, *gorm.DB]
+
+	layouts []Layout
+
+	store  Store
+	cfg    Config
+	logger *zap.Logger
+}
+
+// StartDoubleWrite must be done before Copy, so the rows written during the copy are not missed.
+func (m *Migrator) StartDoubleWrite(ctx context.Context) error {
+	return m.swapPhase(ctx, PhaseOld, PhaseDoubleWrite)
+}
+
+// Abort switches the routing back to the old layout, the rows copied are left in the new layout.
+func (m *Migrator) Abort(ctx context.Context) error {
+	return m.swapPhase(ctx, PhaseDoubleWrite, PhaseOld)
+}
+
+// Copy copies the rows of all the old shards to the new layout in parallel, each shard in batches ordered by id.
+// The rows already in the new layout are overwritten only if they are older,
+// so the rows missed or outdated by the double write are reconciled.
+func (m *Migrator) Copy(ctx context.Context) error {
+	phase, err := m.store.Phase(ctx)
+	if err != nil {
+		return err
+	}
+
+	if phase != PhaseDoubleWrite {
+		return fmt.Errorf("%w: copy should be done in %s phase, but got %s", errPhaseChanged, PhaseDoubleWrite, phase)
+	}
+
+	var eg errgroup.Group
+	for _, layout := range m.layouts {
+		for _, dst := range layout.From.BroadCast() {
+			eg.Go(func() error {
+				return m.copyShard(ctx, layout.To, dst)
+			})
+		}
+	}
+	return eg.Wait()
+}
+
+// ResetCheckpoints removes the checkpoints of all the shards, so the next Copy starts over,
+// e.g. the rows copied have been lost in the new layout.
+func (m *Migrator) ResetCheckpoints(ctx context.Context) error {
+	return m.store.ResetCheckpoints(ctx)
+}
+
+// Recopy copies the shard of the old layout again from the start, e.g. it is not matched by Verify.
+func (m *Migrator) Recopy(ctx context.Context, src sharding.Dst) error {
+	phase, err := m.store.Phase(ctx)
+	if err != nil {
+		return err
+	}
+
+	if phase != PhaseDoubleWrite {
+		return fmt.Errorf("%w: copy should be done in %s phase, but got %s", errPhaseChanged, PhaseDoubleWrite, phase)
+	}
+
+	for _, layout := range m.layouts {
+		if !slices.Contains(layout.From.BroadCast(), src) {
+			continue
+		}
+
+		if err = m.store.SaveCheckpoint(ctx, src, 0); err != nil {
+			return fmt.Errorf("failed to reset checkpoint of %s.%s, cause of: %w", src.DB, src.Table, err)
+		}
+		return m.copyShard(ctx, layout.To, src)
+	}
+	return fmt.Errorf("%w: %s.%s is not a shard of the old layouts", errUnknownShard, src.DB, src.Table)
+}
+
+func (m *Migrator) copyShard(ctx context.Context, to sharding.Strategy, src sharding.Dst) error {
+	db, err := m.getDB(src.DB)
+	if err != nil {
+		return err
+	}
+
+	startId, err := m.store.Checkpoint(ctx, src)
+	if err != nil {
+		return fmt.Errorf("failed to get checkpoint of %s.%s, cause of: %w", src.DB, src.Table, err)
+	}
+
+	for {
+		var rows []map[string]any
+		err = db.WithContext(ctx).Table(src.Table).
+			Where("id > ?", startId).
+			Order("id ASC").
+			Limit(m.cfg.BatchSize).
+			Find(&rows).Error
+		if err != nil {
+			return fmt.Errorf("failed to read rows from %s.%s, cause of: %w", src.DB, src.Table, err)
+		}
+
+		if len(rows) == 0 {
+			return nil
+		}
+
+		lastId, err := m.copyRows(ctx, to, rows)
+		if err != nil {
+			return err
+		}
+
+		// the copy is idempotent, so the checkpoint is saved after the rows are copied.
+		if err = m.store.SaveCheckpoint(ctx, src, lastId); err != nil {
+			return fmt.Errorf("failed to save checkpoint of %s.%s, cause of: %w", src.DB, src.Table, err)
+		}
+		startId = lastId
+
+		m.logger.Info("[jotice] rows copied",
+			zap.String("db", src.DB),
+			zap.String("table", src.Table),
+			zap.Int("count", len(rows)),
+			zap.Uint64("last_id", lastId),
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.cfg.Interval):
+		}
+	}
+}
+
+// copyRows copies the rows to the new layout, returns the last id of the rows.
+func (m *Migrator) copyRows(ctx context.Context, to sharding.Strategy, rows []map[string]any) (uint64, error) {
+	var lastId uint64
+	groups := make(map[sharding.Dst][]map[string]any)
+	for _, row := range rows {
+		id, err := toUint64(row["id"])
+		if err != nil {
+			return 0, err
+		}
+		lastId = id
+
+		dst := to.ShardWithId(id)
+		groups[dst] = append(groups[dst], row)
+	}
+
+	for dst, group := range groups {
+		db, err := m.getDB(dst.DB)
+		if err != nil {
+			return 0, err
+		}
+
+		err = db.WithContext(ctx).Table(dst.Table).
+			Clauses(upsertNewer(dst.Table, group[0])).
+			Create(&group).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to write rows to %s.%s, cause of: %w", dst.DB, dst.Table, err)
+		}
+	}
+	return lastId, nil
+}
+
+// upsertNewer updates the conflicting row with all the columns of the row, if the row is updated later.
+func upsertNewer(table string, row map[string]any) clause.OnConflict {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		if column != "id" {
+			columns = append(columns, column)
+		}
+	}
+	slices.Sort(columns)
+
+	return clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: fmt.Sprintf(`"%s"."updated_at" < "excluded"."updated_at"`, table)},
+		}},
+	}
+}
+
+// ShardReport is the verification result of a shard of the new layout.
+type ShardReport struct {
+	Dst sharding.Dst
+
+	WantCount    int64
+	WantChecksum uint64
+	Count        int64
+	Checksum     uint64
+}
+
+func (r ShardReport) Matched() bool {
+	return r.WantCount == r.Count && r.WantChecksum == r.Checksum
+}
+
+// Verify compares each shard of the new layout with the rows of the old layout which should be routed to it.
+// The checksum is order independent and covers the id and the updated_at of each row,
+// so a row copied before its last update is detected.
+//
+// The layouts may share tables, e.g. resharding in place with the same prefixes,
+// so a row in a shared table is counted in the old layout only if the old layout routes it there,
+// and in the new layout unless it is such an old row the new layout routes elsewhere.
+func (m *Migrator) Verify(ctx context.Context) ([]ShardReport, error) {
+	var reports []ShardReport
+	for _, layout := range m.layouts {
+		layoutReports, err := m.verifyLayout(ctx, layout)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, layoutReports...)
+	}
+	return reports, nil
+}
+
+func (m *Migrator) verifyLayout(ctx context.Context, layout Layout) ([]ShardReport, error) {
+	from, to := layout.From, layout.To
+	want, err := m.summarize(ctx, from.BroadCast(), func(src sharding.Dst, id uint64) (sharding.Dst, bool) {
+		// the rows double written to a shared table are not in the old layout.
+		if from.ShardWithId(id) != src {
+			return sharding.Dst{}, false
+		}
+		return to.ShardWithId(id), true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fromDsts := make(map[sharding.Dst]struct{})
+	for _, dst := range from.BroadCast() {
+		fromDsts[dst] = struct{}{}
+	}
+
+	// the rows of the new layout are summarized where they are, so the misplaced rows are detected.
+	got, err := m.summarize(ctx, to.BroadCast(), func(src sharding.Dst, id uint64) (sharding.Dst, bool) {
+		if _, shared := fromDsts[src]; shared && from.ShardWithId(id) == src && to.ShardWithId(id) != src {
+			// the old row left in a shared table, which is copied to its new shard.
+			return sharding.Dst{}, false
+		}
+		return src, true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dsts := to.BroadCast()
+	reports := make([]ShardReport, 0, len(dsts))
+	for _, dst := range dsts {
+		reports = append(reports, ShardReport{
+			Dst:          dst,
+			WantCount:    want[dst].count,
+			WantChecksum: want[dst].checksum,
+			Count:        got[dst].count,
+			Checksum:     got[dst].checksum,
+		})
+	}
+	return reports, nil
+}
+
+// Switch verifies the new layout and switches the routing to it.
+// It fails without switching if any shard is not matched.
+func (m *Migrator) Switch(ctx context.Context) error {
+	reports, err := m.Verify(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		if !report.Matched() {
+			return fmt.Errorf("%w: %s.%s want %d rows with checksum %d, but got %d rows with checksum %d",
+				errNotVerified, report.Dst.DB, report.Dst.Table,
+				report.WantCount, report.WantChecksum, report.Count, report.Checksum,
+			)
+		}
+	}
+	return m.swapPhase(ctx, PhaseDoubleWrite, PhaseSwitched)
+}
+
+// Purge deletes the rows left in the old layout after the switch, which the new layout routes elsewhere,
+// returns the number of rows deleted. The old tables not in the new layout are left empty to be dropped.
+//
+// It should be run after all the instances have switched, the instances still in double write phase
+// read the old layout.
+func (m *Migrator) Purge(ctx context.Context) (int64, error) {
+	phase, err := m.store.Phase(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if phase != PhaseSwitched {
+		return 0, fmt.Errorf("%w: purge should be done in %s phase, but got %s", errPhaseChanged, PhaseSwitched, phase)
+	}
+
+	var mu sync.Mutex
+	var total int64
+
+	var eg errgroup.Group
+	for _, layout := range m.layouts {
+		for _, dst := range layout.From.BroadCast() {
+			eg.Go(func() error {
+				deleted, err := m.purgeShard(ctx, layout.To, dst)
+
+				mu.Lock()
+				total += deleted
+				mu.Unlock()
+				return err
+			})
+		}
+	}
+
+	err = eg.Wait()
+	return total, err
+}
+
+func (m *Migrator) purgeShard(ctx context.Context, to sharding.Strategy, src sharding.Dst) (int64, error) {
+	db, err := m.getDB(src.DB)
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	var startId uint64
+	for {
+		var ids []uint64
+		err = db.WithContext(ctx).Table(src.Table).
+			Where("id > ?", startId).
+			Order("id ASC").
+			Limit(m.cfg.BatchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return deleted, fmt.Errorf("failed to read rows from %s.%s, cause of: %w", src.DB, src.Table, err)
+		}
+
+		if len(ids) == 0 {
+			return deleted, nil
+		}
+		startId = ids[len(ids)-1]
+
+		// the rows the new layout routes to the shard are kept, e.g. resharding in place.
+		ids = slices.DeleteFunc(ids, func(id uint64) bool {
+			return to.ShardWithId(id) == src
+		})
+		if len(ids) == 0 {
+			continue
+		}
+
+		res := db.WithContext(ctx).Exec(fmt.Sprintf(`DELETE FROM "%s" WHERE id IN ?`, src.Table), ids)
+		if res.Error != nil {
+			return deleted, fmt.Errorf("failed to purge rows from %s.%s, cause of: %w", src.DB, src.Table, res.Error)
+		}
+		deleted += res.RowsAffected
+
+		m.logger.Info("[jotice] rows purged",
+			zap.String("db", src.DB),
+			zap.String("table", src.Table),
+			zap.Int64("count", res.RowsAffected),
+			zap.Uint64("last_id", startId),
+		)
+
+		select {
+		case <-ctx.Done():
+			return deleted, ctx.Err()
+		case <-time.After(m.cfg.Interval):
+		}
+	}
+}
+
+// summarize scans the shards and summarizes the rows by the dst of the new layout the route returns,
+// the rows are skipped if the route returns false.
+func (m *Migrator) summarize(
+	ctx context.Context, dsts []sharding.Dst, route func(src sharding.Dst, id uint64) (sharding.Dst, bool),
+) (map[sharding.Dst]summary, error) {
+	res := make(map[sharding.Dst]summary)
+	resultChan := make(chan map[sharding.Dst]summary, len(dsts))
+
+	var eg errgroup.Group
+	for _, dst := range dsts {
+		eg.Go(func() error {
+			s, err := m.summarizeShard(ctx, dst, route)
+			if err != nil {
+				return err
+			}
+			resultChan <- s
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	close(resultChan)
+
+	for s := range resultChan {
+		for dst, sum := range s {
+			res[dst] = res[dst].merge(sum)
+		}
+	}
+	return res, nil
+}
+
+func (m *Migrator) summarizeShard(
+	ctx context.Context, src sharding.Dst, route func(src sharding.Dst, id uint64) (sharding.Dst, bool),
+) (map[sharding.Dst]summary, error) {
+	db, err := m.getDB(src.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	type row struct {
+		Id        uint64
+		UpdatedAt int64
+	}
+
+	res := make(map[sharding.Dst]summary)
+	var startId uint64
+	for {
+		var rows []row
+		err = db.WithContext(ctx).Table(src.Table).
+			Select("id", "updated_at").
+			Where("id > ?", startId).
+			Order("id ASC").
+			Limit(m.cfg.BatchSize).
+			Find(&rows).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rows from %s.%s, cause of: %w", src.DB, src.Table, err)
+		}
+
+		if len(rows) == 0 {
+			return res, nil
+		}
+
+		for _, r := range rows {
+			if dst, ok := route(src, r.Id); ok {
+				res[dst] = res[dst].add(r.Id, r.UpdatedAt)
+			}
+		}
+		startId = rows[len(rows)-1].Id
+	}
+}
+
+func (m *Migrator) swapPhase(ctx context.Context, old, new Phase) error {
+	ok, err := m.store.CompareAndSwapPhase(ctx, old, new)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: failed to switch phase from %s to %s", errPhaseChanged, old, new)
+	}
+	return nil
+}
+
+func (m *Migrator) getDB(name string) (*gorm.DB, error) {
+	db, ok := m.dbs.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown db: %s", name)
+	}
+	return db, nil
+}
+
+func toUint64(val any) (uint64, error) {
+	switch v := val.(type) {
+	case int64:
+		return uint64(v), nil
+	case uint64:
+		return v, nil
+	case int32:
+		return uint64(v), nil
+	case int:
+		return uint64(v), nil
+	default:
+		return 0, fmt.Errorf("unsupported id type %T", val)
+	}
+}
+
+func NewMigrator(
+	dbs *xsync.Map[string, *gorm.DB], layouts []Layout, store Store, cfg Config, logger *zap.Logger,
+) *Migrator {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+
+	return &Migrator{
+		dbs:     dbs,
+		layouts: layouts,
+		store:   store,
+		cfg:     cfg,
+		logger:  logger,
+	}
+}
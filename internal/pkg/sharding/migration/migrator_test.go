@@ -0,0 +1,219 @@
+package migration
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// seedRows writes the rows of n notifications to the old layout, returns their ids.
+func seedRows(t *testing.T, fakes map[string]*fakeDB, from sharding.Strategy, n int) []uint64 {
+	g := snowflake.NewGenerator()
+	ids := make([]uint64, 0, n)
+	for i := 0; i < n; i++ {
+		id, err := g.NextId(uint64(i%7+1), "biz_key_"+strconv.Itoa(i))
+		require.NoError(t, err)
+		ids = append(ids, id)
+
+		dst := from.ShardWithId(id)
+		fakes[dst.DB].put(dst.Table, fakeRow{id: int64(id), updatedAt: 100, status: "pending"})
+	}
+	return ids
+}
+
+func TestMigrator_CopyVerify(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name string
+		from sharding.Strategy
+		to   sharding.Strategy
+	}{
+		{
+			name: "to new dbs",
+			from: sharding.NewHashStrategy("jotice", "notification", 2, 2),
+			to:   sharding.NewHashStrategy("jotice_v2", "notification", 4, 2),
+		}, {
+			// the old tables are kept in the new layout with the old rows left in them.
+			name: "in place",
+			from: sharding.NewHashStrategy("jotice", "notification", 2, 2),
+			to:   sharding.NewHashStrategy("jotice", "notification", 2, 4),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dbs, fakes := newFakeDBs(t, "jotice_0", "jotice_1", "jotice_v2_0", "jotice_v2_1", "jotice_v2_2", "jotice_v2_3")
+			store := &memStore{phase: PhaseOld}
+			m := NewMigrator(dbs, []Layout{{From: tc.from, To: tc.to}}, store, Config{BatchSize: 7}, zap.NewNop())
+
+			ids := seedRows(t, fakes, tc.from, 100)
+			require.NoError(t, m.StartDoubleWrite(t.Context()))
+
+			// a row double written before its last update in the old layout.
+			var moved uint64
+			for _, id := range ids {
+				if tc.from.ShardWithId(id) != tc.to.ShardWithId(id) {
+					moved = id
+					break
+				}
+			}
+			require.NotZero(t, moved)
+
+			src, dst := tc.from.ShardWithId(moved), tc.to.ShardWithId(moved)
+			fakes[src.DB].put(src.Table, fakeRow{id: int64(moved), updatedAt: 200, status: "succeeded"})
+			fakes[dst.DB].put(dst.Table, fakeRow{id: int64(moved), updatedAt: 100, status: "pending"})
+
+			require.NoError(t, m.Copy(t.Context()))
+
+			// the outdated row is overwritten by the newer one.
+			row, ok := fakes[dst.DB].get(dst.Table, int64(moved))
+			require.True(t, ok)
+			assert.Equal(t, fakeRow{id: int64(moved), updatedAt: 200, status: "succeeded"}, row)
+
+			reports, err := m.Verify(t.Context())
+			require.NoError(t, err)
+			assert.Len(t, reports, len(tc.to.BroadCast()))
+
+			var total int64
+			for _, report := range reports {
+				assert.True(t, report.Matched(), "%s.%s is not matched", report.Dst.DB, report.Dst.Table)
+				total += report.Count
+			}
+			assert.Equal(t, int64(len(ids)), total)
+
+			require.NoError(t, m.Switch(t.Context()))
+			assert.Equal(t, PhaseSwitched, store.phase)
+		})
+	}
+}
+
+func TestMigrator_VerifyMismatch(t *testing.T) {
+	t.Parallel()
+
+	from := sharding.NewHashStrategy("jotice", "notification", 2, 2)
+	to := sharding.NewHashStrategy("jotice", "notification", 2, 4)
+
+	dbs, fakes := newFakeDBs(t, "jotice_0", "jotice_1")
+	store := &memStore{phase: PhaseOld}
+	m := NewMigrator(dbs, []Layout{{From: from, To: to}}, store, Config{BatchSize: 10}, zap.NewNop())
+
+	ids := seedRows(t, fakes, from, 50)
+	require.NoError(t, m.StartDoubleWrite(t.Context()))
+	require.NoError(t, m.Copy(t.Context()))
+
+	// a row lost in the new layout, and a row updated in the old layout after the copy.
+	var lost, updated uint64
+	for _, id := range ids {
+		switch {
+		case lost == 0 && from.ShardWithId(id) != to.ShardWithId(id):
+			lost = id
+		case updated == 0 && from.ShardWithId(id) != to.ShardWithId(id):
+			updated = id
+		}
+	}
+	require.NotZero(t, lost)
+	require.NotZero(t, updated)
+
+	lostDst := to.ShardWithId(lost)
+	fakes[lostDst.DB].remove(lostDst.Table, int64(lost))
+
+	updatedSrc := from.ShardWithId(updated)
+	fakes[updatedSrc.DB].put(updatedSrc.Table, fakeRow{id: int64(updated), updatedAt: 300, status: "failed"})
+
+	reports, err := m.Verify(t.Context())
+	require.NoError(t, err)
+
+	mismatched := make(map[sharding.Dst]ShardReport)
+	for _, report := range reports {
+		if !report.Matched() {
+			mismatched[report.Dst] = report
+		}
+	}
+	assert.Contains(t, mismatched, lostDst)
+	assert.Equal(t, mismatched[lostDst].WantCount-1, mismatched[lostDst].Count)
+	assert.Contains(t, mismatched, to.ShardWithId(updated))
+
+	assert.ErrorIs(t, m.Switch(t.Context()), errNotVerified)
+	assert.Equal(t, PhaseDoubleWrite, store.phase)
+}
+
+func TestMigrator_RecopyPurge(t *testing.T) {
+	t.Parallel()
+
+	// the notifications and their callback logs are resharded together.
+	layouts := []Layout{
+		{
+			From: sharding.NewHashStrategy("jotice", "notification", 2, 2),
+			To:   sharding.NewHashStrategy("jotice", "notification", 2, 4),
+		}, {
+			From: sharding.NewHashStrategy("jotice", "callback_log", 2, 2),
+			To:   sharding.NewHashStrategy("jotice", "callback_log", 2, 4),
+		},
+	}
+
+	dbs, fakes := newFakeDBs(t, "jotice_0", "jotice_1")
+	store := &memStore{phase: PhaseOld}
+	m := NewMigrator(dbs, layouts, store, Config{BatchSize: 10}, zap.NewNop())
+
+	ids := seedRows(t, fakes, layouts[0].From, 50)
+	cbLogIds := seedRows(t, fakes, layouts[1].From, 50)
+	require.NoError(t, m.StartDoubleWrite(t.Context()))
+	require.NoError(t, m.Copy(t.Context()))
+
+	// a callback log lost in the new layout is not copied again from the checkpoint.
+	var lost uint64
+	for _, id := range cbLogIds {
+		if layouts[1].From.ShardWithId(id) != layouts[1].To.ShardWithId(id) {
+			lost = id
+			break
+		}
+	}
+	require.NotZero(t, lost)
+	lostDst := layouts[1].To.ShardWithId(lost)
+	fakes[lostDst.DB].remove(lostDst.Table, int64(lost))
+
+	require.NoError(t, m.Copy(t.Context()))
+	assert.ErrorIs(t, m.Switch(t.Context()), errNotVerified)
+
+	// the shard is copied again from the start.
+	assert.ErrorIs(t, m.Recopy(t.Context(), sharding.Dst{DB: "jotice_2", Table: "callback_log_0"}), errUnknownShard)
+	require.NoError(t, m.Recopy(t.Context(), layouts[1].From.ShardWithId(lost)))
+	_, ok := fakes[lostDst.DB].get(lostDst.Table, int64(lost))
+	assert.True(t, ok)
+
+	_, err := m.Purge(t.Context())
+	assert.ErrorIs(t, err, errPhaseChanged)
+	require.NoError(t, m.Switch(t.Context()))
+
+	// the rows left in the old tables are purged, the ones the new layout routes there are kept.
+	var moved int64
+	for i, layout := range layouts {
+		for _, id := range [][]uint64{ids, cbLogIds}[i] {
+			src, dst := layout.From.ShardWithId(id), layout.To.ShardWithId(id)
+			if src != dst {
+				moved++
+			}
+		}
+	}
+	purged, err := m.Purge(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, moved, purged)
+
+	for i, layout := range layouts {
+		for _, id := range [][]uint64{ids, cbLogIds}[i] {
+			src, dst := layout.From.ShardWithId(id), layout.To.ShardWithId(id)
+			_, ok = fakes[src.DB].get(src.Table, int64(id))
+			assert.Equal(t, src == dst, ok)
+			_, ok = fakes[dst.DB].get(dst.Table, int64(id))
+			assert.True(t, ok)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package migration
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"go.uber.org/zap"
+)
+
+var (
+	_ sharding.Strategy = (*SwitchableStrategy)(nil)
+	_ DoubleWriter      = (*SwitchableStrategy)(nil)
+)
+
+// SwitchableStrategy routes to the old or the new layout by the phase of the resharding.
+// The phase is loaded on Start and watched, so all the instances switch together.
+type SwitchableStrategy struct {
+	from sharding.Strategy
+	to   sharding.Strategy
+
+	phase atomic.Value
+
+	store  Store
+	logger *zap.Logger
+}
+
+func (s *SwitchableStrategy) Shard(bizId uint64, bizKey string) sharding.Dst {
+	return s.current().Shard(bizId, bizKey)
+}
+
+func (s *SwitchableStrategy) ShardWithId(id uint64) sharding.Dst {
+	return s.current().ShardWithId(id)
+}
+
+func (s *SwitchableStrategy) BroadCast() []sharding.Dst {
+	return s.current().BroadCast()
+}
+
+func (s *SwitchableStrategy) DoubleWriteDst(id uint64) (sharding.Dst, bool) {
+	if s.Phase() != PhaseDoubleWrite {
+		return sharding.Dst{}, false
+	}
+
+	dst := s.to.ShardWithId(id)
+	if dst == s.from.ShardWithId(id) {
+		return sharding.Dst{}, false
+	}
+	return dst, true
+}
+
+func (s *SwitchableStrategy) Phase() Phase {
+	return s.phase.Load().(Phase)
+}
+
+func (s *SwitchableStrategy) current() sharding.Strategy {
+	if s.Phase() == PhaseSwitched {
+		return s.to
+	}
+	return s.from
+}
+
+// Start loads the phase and watches the changes of it.
+func (s *SwitchableStrategy) Start(ctx context.Context) {
+	// watch before loading, so the change between them is not missed.
+	phaseChan := s.store.WatchPhase(ctx)
+
+	if phase, err := s.store.Phase(ctx); err != nil {
+		s.logger.Error("[jotice] failed to load resharding phase", zap.Error(err))
+	} else {
+		s.setPhase(phase)
+	}
+
+	go func() {
+		for phase := range phaseChan {
+			s.setPhase(phase)
+		}
+	}()
+}
+
+func (s *SwitchableStrategy) setPhase(phase Phase) {
+	if old := s.Phase(); old != phase {
+		s.logger.Info("[jotice] resharding phase changed",
+			zap.String("from", string(old)),
+			zap.String("to", string(phase)),
+		)
+	}
+	s.phase.Store(phase)
+}
+
+// NewSwitchableStrategy creates a SwitchableStrategy in PhaseOld until Start.
+func NewSwitchableStrategy(from, to sharding.Strategy, store Store, logger *zap.Logger) *SwitchableStrategy {
+	s := &SwitchableStrategy{
+		from:   from,
+		to:     to,
+		store:  store,
+		logger: logger,
+	}
+	s.phase.Store(PhaseOld)
+	return s
+}
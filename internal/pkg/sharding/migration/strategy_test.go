@@ -0,0 +1,163 @@
+package migration
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memStore is an in memory Store for test.
+type memStore struct {
+	mu          sync.Mutex
+	phase       Phase
+	watchers    []chan Phase
+	checkpoints map[sharding.Dst]uint64
+}
+
+func (s *memStore) Phase(_ context.Context) (Phase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.phase, nil
+}
+
+func (s *memStore) CompareAndSwapPhase(_ context.Context, old, new Phase) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.phase != old {
+		return false, nil
+	}
+
+	s.phase = new
+	for _, w := range s.watchers {
+		w <- new
+	}
+	return true, nil
+}
+
+func (s *memStore) WatchPhase(_ context.Context) <-chan Phase {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := make(chan Phase, 8)
+	s.watchers = append(s.watchers, w)
+	return w
+}
+
+func (s *memStore) Checkpoint(_ context.Context, dst sharding.Dst) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkpoints[dst], nil
+}
+
+func (s *memStore) SaveCheckpoint(_ context.Context, dst sharding.Dst, id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.checkpoints == nil {
+		s.checkpoints = make(map[sharding.Dst]uint64)
+	}
+	s.checkpoints[dst] = id
+	return nil
+}
+
+func (s *memStore) ResetCheckpoints(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints = nil
+	return nil
+}
+
+func TestSwitchableStrategy(t *testing.T) {
+	t.Parallel()
+
+	from := sharding.NewHashStrategy("jotice", "notification", 2, 4)
+	to := sharding.NewHashStrategy("jotice", "notification", 4, 4)
+
+	store := &memStore{phase: PhaseOld}
+	s := NewSwitchableStrategy(from, to, store, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+
+	g := snowflake.NewGenerator()
+	ids := make([]uint64, 0, 100)
+	for i := 0; i < 100; i++ {
+		id, err := g.NextId(uint64(i%7+1), "biz_key_"+strconv.Itoa(i))
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	// old phase, no double write.
+	for _, id := range ids {
+		assert.Equal(t, from.ShardWithId(id), s.ShardWithId(id))
+		_, ok := s.DoubleWriteDst(id)
+		assert.False(t, ok)
+	}
+
+	ok, err := store.CompareAndSwapPhase(ctx, PhaseOld, PhaseDoubleWrite)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Eventually(t, func() bool { return s.Phase() == PhaseDoubleWrite }, time.Second, 10*time.Millisecond)
+
+	// double write phase, the reads are still routed to the old layout.
+	moved := 0
+	for _, id := range ids {
+		assert.Equal(t, from.ShardWithId(id), s.ShardWithId(id))
+
+		dst, ok := s.DoubleWriteDst(id)
+		if from.ShardWithId(id) == to.ShardWithId(id) {
+			assert.False(t, ok)
+			continue
+		}
+
+		moved++
+		assert.True(t, ok)
+		assert.Equal(t, to.ShardWithId(id), dst)
+	}
+	assert.Positive(t, moved)
+
+	ok, err = store.CompareAndSwapPhase(ctx, PhaseDoubleWrite, PhaseSwitched)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Eventually(t, func() bool { return s.Phase() == PhaseSwitched }, time.Second, 10*time.Millisecond)
+
+	// switched phase, all routed to the new layout.
+	for _, id := range ids {
+		assert.Equal(t, to.ShardWithId(id), s.ShardWithId(id))
+		_, ok := s.DoubleWriteDst(id)
+		assert.False(t, ok)
+	}
+	assert.Len(t, s.BroadCast(), 16)
+}
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+
+	rows := [][2]int64{{1, 100}, {2, 200}, {3, 300}}
+
+	var forward summary
+	for _, r := range rows {
+		forward = forward.add(uint64(r[0]), r[1])
+	}
+
+	// order independent, and can be merged from the shards.
+	backward := summary{}.add(uint64(rows[2][0]), rows[2][1])
+	backward = backward.merge(summary{}.add(uint64(rows[1][0]), rows[1][1]).add(uint64(rows[0][0]), rows[0][1]))
+	assert.Equal(t, forward, backward)
+	assert.Equal(t, int64(3), forward.count)
+
+	// a stale row is detected.
+	stale := summary{}.add(1, 100).add(2, 199).add(3, 300)
+	assert.Equal(t, forward.count, stale.count)
+	assert.NotEqual(t, forward.checksum, stale.checksum)
+}
@@ -0,0 +1,43 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+)
+
+// Phase is the phase of a resharding, which decides how the rows are routed.
+type Phase string
+
+const (
+	// PhaseOld routes the reads and writes to the old layout.
+	PhaseOld Phase = "old"
+	// PhaseDoubleWrite routes the reads to the old layout, and the writes to both layouts.
+	PhaseDoubleWrite Phase = "double_write"
+	// PhaseSwitched routes the reads and writes to the new layout.
+	PhaseSwitched Phase = "switched"
+)
+
+// Store stores the phase and the copy progress of a resharding, shared by all the instances.
+type Store interface {
+	// Phase returns PhaseOld if the phase has not been set.
+	Phase(ctx context.Context) (Phase, error)
+	// CompareAndSwapPhase sets the phase only if the current phase is old, returns false if not.
+	CompareAndSwapPhase(ctx context.Context, old, new Phase) (bool, error)
+	// WatchPhase notifies the new phase when it changes.
+	WatchPhase(ctx context.Context) <-chan Phase
+
+	// Checkpoint returns the last id copied from the dst of the old layout, zero if not started.
+	Checkpoint(ctx context.Context, dst sharding.Dst) (uint64, error)
+	SaveCheckpoint(ctx context.Context, dst sharding.Dst, id uint64) error
+	// ResetCheckpoints removes all the checkpoints, so the next copy starts over.
+	ResetCheckpoints(ctx context.Context) error
+}
+
+// DoubleWriter is implemented by the strategy in resharding.
+// The DAO should also write the row to the dst returned, if it is in double write phase.
+type DoubleWriter interface {
+	// DoubleWriteDst returns the dst of the new layout for the id,
+	// false if not in double write phase or the dst is the same as the old one.
+	DoubleWriteDst(id uint64) (sharding.Dst, bool)
+}
@@ -0,0 +1,71 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JrMarcco/easy-kit/xsync"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TableCreateTask is a background task that creates the upcoming tables of TimeRangeStrategy in advance.
+// The tables are created like the template table named as the table prefix, which should exist in each db.
+type TableCreateTask struct {
+	dbs      *xsync.Map[string, *gorm.DB]
+	strategy TimeRangeStrategy
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func (t *TableCreateTask) Start(ctx context.Context) {
+	go t.loop(ctx)
+}
+
+func (t *TableCreateTask) loop(ctx context.Context) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.CreateUpcoming(ctx); err != nil {
+			t.logger.Error("[jotice] failed to create upcoming tables", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CreateUpcoming creates the tables of the current month and the months ahead if not exist.
+func (t *TableCreateTask) CreateUpcoming(ctx context.Context) error {
+	template := t.strategy.TablePrefix()
+	for _, dst := range t.strategy.Upcoming() {
+		db, ok := t.dbs.Load(dst.DB)
+		if !ok {
+			return fmt.Errorf("unknown db: %s", dst.DB)
+		}
+
+		err := db.WithContext(ctx).
+			Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS "%s" (LIKE "%s" INCLUDING ALL)`, dst.Table, template)).
+			Error
+		if err != nil {
+			return fmt.Errorf("failed to create table %s.%s, cause of: %w", dst.DB, dst.Table, err)
+		}
+	}
+	return nil
+}
+
+func NewTableCreateTask(
+	dbs *xsync.Map[string, *gorm.DB], strategy TimeRangeStrategy, interval time.Duration, logger *zap.Logger,
+) *TableCreateTask {
+	return &TableCreateTask{
+		dbs:      dbs,
+		strategy: strategy,
+		interval: interval,
+		logger:   logger,
+	}
+}
@@ -0,0 +1,130 @@
+package sharding
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+)
+
+var _ RangeStrategy = (*TimeRangeStrategy)(nil)
+
+// TimeRangeStrategy is a sharding strategy for the write-once history tables like notification.
+//
+// The db is decided by the hash embedded in the id, the same as HashStrategy,
+// and the table is decided by the month of the timestamp embedded in the id, named as "{table prefix}_200601".
+// The months are in UTC, so all the instances route to the same table.
+type TimeRangeStrategy struct {
+	dbPrefix    string
+	tablePrefix string
+
+	dbSharding uint64
+
+	// since is the first month of the tables.
+	since time.Time
+	// ahead is the number of months the tables should be created ahead of the current month.
+	ahead int
+	// lookback is the number of months before the current month scanned by the biz key lookups and the schedulers.
+	lookback int
+}
+
+// Shard routes by the current time, which is the time embedded in the id generated right now.
+// Prefer ShardWithId once the id is generated, in case the month changes between them.
+// The biz key created in the earlier months is not in the dst, use ShardRecent to look it up.
+func (s TimeRangeStrategy) Shard(bizId uint64, bizKey string) Dst {
+	return s.dst(snowflake.Hash(bizId, bizKey), time.Now())
+}
+
+func (s TimeRangeStrategy) ShardWithId(id uint64) Dst {
+	return s.dst(snowflake.ExtractHash(id), snowflake.ExtractTimestamp(id))
+}
+
+// BroadCast returns all the tables from the first month to the last upcoming month.
+// Use BroadCastRange to scan the recent tables only.
+func (s TimeRangeStrategy) BroadCast() []Dst {
+	return s.BroadCastRange(s.since, time.Now().UTC().AddDate(0, s.ahead, 0))
+}
+
+// BroadCastRange returns the tables of the months in [start, end], not earlier than the first month.
+func (s TimeRangeStrategy) BroadCastRange(start, end time.Time) []Dst {
+	first := monthOf(start)
+	if first.Before(s.since) {
+		first = s.since
+	}
+	last := monthOf(end)
+
+	var res []Dst
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		for i := uint64(0); i < s.dbSharding; i++ {
+			res = append(res, s.dstOf(i, month))
+		}
+	}
+	return res
+}
+
+// ShardRecent returns the tables of the biz key from the current month back to the lookback months, the newest first.
+func (s TimeRangeStrategy) ShardRecent(bizId uint64, bizKey string) []Dst {
+	dbSuffix := snowflake.Hash(bizId, bizKey) % s.dbSharding
+
+	var res []Dst
+	month := monthOf(time.Now())
+	for i := 0; i <= s.lookback && !month.Before(s.since); i++ {
+		res = append(res, s.dstOf(dbSuffix, month))
+		month = month.AddDate(0, -1, 0)
+	}
+	return res
+}
+
+// Recent returns the tables from the lookback months before the current month to the current month.
+func (s TimeRangeStrategy) Recent() []Dst {
+	now := time.Now()
+	return s.BroadCastRange(now.UTC().AddDate(0, -s.lookback, 0), now)
+}
+
+// Upcoming returns the tables of the current month and the months ahead, which should be created in advance.
+func (s TimeRangeStrategy) Upcoming() []Dst {
+	now := time.Now()
+	return s.BroadCastRange(now, now.UTC().AddDate(0, s.ahead, 0))
+}
+
+func (s TimeRangeStrategy) TablePrefix() string {
+	return s.tablePrefix
+}
+
+func (s TimeRangeStrategy) dst(hashVal uint64, t time.Time) Dst {
+	return s.dstOf(hashVal%s.dbSharding, monthOf(t))
+}
+
+func (s TimeRangeStrategy) dstOf(dbSuffix uint64, month time.Time) Dst {
+	tableSuffix, _ := strconv.ParseUint(month.Format("200601"), 10, 64)
+	return Dst{
+		DBSuffix:    dbSuffix,
+		TableSuffix: tableSuffix,
+		DB:          fmt.Sprintf("%s_%d", s.dbPrefix, dbSuffix),
+		Table:       fmt.Sprintf("%s_%d", s.tablePrefix, tableSuffix),
+	}
+}
+
+// monthOf returns the first moment of the month of t in UTC.
+func monthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// NewTimeRangeStrategy creates a TimeRangeStrategy,
+// since is the first month of the tables, and the tables of aheadMonths months later are created in advance.
+// The biz key lookups and the schedulers scan the tables of lookbackMonths months before the current month,
+// which should cover the retry window of the biz keys and the pending callbacks.
+func NewTimeRangeStrategy(
+	dbPrefix, tablePrefix string, dbSharding uint64, since time.Time, aheadMonths, lookbackMonths int,
+) TimeRangeStrategy {
+	return TimeRangeStrategy{
+		dbPrefix:    dbPrefix,
+		tablePrefix: tablePrefix,
+		dbSharding:  dbSharding,
+		since:       monthOf(since),
+		ahead:       max(aheadMonths, 0),
+		lookback:    max(lookbackMonths, 0),
+	}
+}
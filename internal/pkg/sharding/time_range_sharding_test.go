@@ -0,0 +1,146 @@
+package sharding
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeRangeStrategy_ShardWithId(t *testing.T) {
+	t.Parallel()
+
+	s := NewTimeRangeStrategy("jotice", "notification", 2, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 1, 1)
+	g := snowflake.NewGenerator()
+
+	for i := 0; i < 100; i++ {
+		bizId := uint64(i%7 + 1)
+		bizKey := "biz_key_" + strconv.Itoa(i)
+
+		id, err := g.NextId(bizId, bizKey)
+		require.NoError(t, err)
+
+		// the table is decided by the time embedded in the id, not the time of the check.
+		dst := s.ShardWithId(id)
+		assert.Equal(t, "notification_"+snowflake.ExtractTimestamp(id).UTC().Format("200601"), dst.Table)
+		assert.Equal(t, snowflake.Hash(bizId, bizKey)%2, dst.DBSuffix)
+		assert.Equal(t, s.Shard(bizId, bizKey).DB, dst.DB)
+	}
+}
+
+func TestTimeRangeStrategy_BroadCastRange(t *testing.T) {
+	t.Parallel()
+
+	s := NewTimeRangeStrategy("jotice", "notification", 2, time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), 1, 1)
+
+	tcs := []struct {
+		name       string
+		start      time.Time
+		end        time.Time
+		wantTables []string
+	}{
+		{
+			name:  "across year",
+			start: time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantTables: []string{
+				"notification_202511", "notification_202511",
+				"notification_202512", "notification_202512",
+				"notification_202601", "notification_202601",
+			},
+		}, {
+			name:       "clamped to the first month",
+			start:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:        time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			wantTables: []string{"notification_202503", "notification_202503"},
+		}, {
+			name:  "end before start",
+			start: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var tables []string
+			for _, dst := range s.BroadCastRange(tc.start, tc.end) {
+				tables = append(tables, dst.Table)
+			}
+			assert.Equal(t, tc.wantTables, tables)
+		})
+	}
+}
+
+func TestTimeRangeStrategy_Upcoming(t *testing.T) {
+	t.Parallel()
+
+	s := NewTimeRangeStrategy("jotice", "notification", 3, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 2, 1)
+
+	// the current month and 2 months ahead in each of the 3 dbs.
+	before := time.Now()
+	dsts := s.Upcoming()
+	after := time.Now()
+
+	assert.Len(t, dsts, 9)
+	assert.Contains(t, []string{monthTable(before, 0), monthTable(after, 0)}, dsts[0].Table)
+}
+
+func TestTimeRangeStrategy_ShardRecent(t *testing.T) {
+	t.Parallel()
+
+	s := NewTimeRangeStrategy("jotice", "notification", 2, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 1, 2)
+
+	before := time.Now()
+	dsts := s.ShardRecent(1, "biz_key")
+	after := time.Now()
+
+	// the current month and 2 months before in the db of the biz key, the newest first.
+	require.Len(t, dsts, 3)
+	if monthTable(before, 0) != monthTable(after, 0) {
+		t.Skip("the month changed during the test")
+	}
+	for i, dst := range dsts {
+		assert.Equal(t, monthTable(before, -i), dst.Table)
+		assert.Equal(t, s.Shard(1, "biz_key").DB, dst.DB)
+	}
+
+	// the tables before the first month are not returned.
+	s = NewTimeRangeStrategy("jotice", "notification", 2, before, 1, 2)
+	assert.Len(t, s.ShardRecent(1, "biz_key"), 1)
+}
+
+func TestTimeRangeStrategy_Recent(t *testing.T) {
+	t.Parallel()
+
+	s := NewTimeRangeStrategy("jotice", "notification", 2, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 1, 1)
+
+	// the previous month and the current month in each of the 2 dbs.
+	dsts := s.Recent()
+	require.Len(t, dsts, 4)
+	assert.Equal(t, dsts[0].Table, dsts[1].Table)
+	assert.Equal(t, dsts[2].Table, dsts[3].Table)
+	assert.NotEqual(t, dsts[0].Table, dsts[2].Table)
+}
+
+func TestShardRecent(t *testing.T) {
+	t.Parallel()
+
+	// the strategies not sharding by time have the only dst of Shard.
+	hash := NewHashStrategy("jotice", "notification", 2, 2)
+	assert.Equal(t, []Dst{hash.Shard(1, "biz_key")}, ShardRecent(hash, 1, "biz_key"))
+	assert.Equal(t, hash.BroadCast(), BroadCastRecent(hash))
+
+	timeRange := NewTimeRangeStrategy("jotice", "notification", 2, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 1, 3)
+	assert.Len(t, ShardRecent(timeRange, 1, "biz_key"), 4)
+	assert.Len(t, BroadCastRecent(timeRange), 8)
+}
+
+// monthTable returns the table of the month months after the month of t.
+func monthTable(t time.Time, months int) string {
+	return "notification_" + monthOf(t).AddDate(0, months, 0).Format("200601")
+}
@@ -2,6 +2,7 @@ package sharding
 
 import (
 	"context"
+	"time"
 )
 
 type Strategy interface {
@@ -10,6 +11,35 @@ type Strategy interface {
 	BroadCast() []Dst
 }
 
+// RangeStrategy is a Strategy sharding by time, the broadcast can be limited to a time range.
+type RangeStrategy interface {
+	Strategy
+	// BroadCastRange returns the dsts of the time range [start, end].
+	BroadCastRange(start, end time.Time) []Dst
+	// Recent returns the dsts of the recent time range, which the schedulers scan.
+	Recent() []Dst
+	// ShardRecent returns the dsts the biz key created in the recent time range may be in, the newest first.
+	ShardRecent(bizId uint64, bizKey string) []Dst
+}
+
+// BroadCastRecent returns the recent dsts of a RangeStrategy, or all the dsts of the other strategies.
+func BroadCastRecent(s Strategy) []Dst {
+	if rs, ok := s.(RangeStrategy); ok {
+		return rs.Recent()
+	}
+	return s.BroadCast()
+}
+
+// ShardRecent returns the dsts the biz key may be in, the newest first.
+// The dst of a RangeStrategy depends on the time the biz key is created, so all its recent dsts are returned,
+// the other strategies return the dst of Shard.
+func ShardRecent(s Strategy, bizId uint64, bizKey string) []Dst {
+	if rs, ok := s.(RangeStrategy); ok {
+		return rs.ShardRecent(bizId, bizKey)
+	}
+	return []Dst{s.Shard(bizId, bizKey)}
+}
+
 type Dst struct {
 	DBSuffix    uint64
 	TableSuffix uint64
@@ -1,22 +1,27 @@
 package snowflake
 
 import (
+	"fmt"
 	"strconv"
-	"sync/atomic"
+	"sync"
 	"time"
 
+	"github.com/JrMarcco/jotice/internal/errs"
 	"github.com/cespare/xxhash/v2"
 )
 
 const (
 	timestampBits = 41
 	hashBits      = 10
-	sequenceBits  = 12
+	workerIdBits  = 5
+	sequenceBits  = 7
 
-	hashShift      = sequenceBits
+	workerIdShift  = sequenceBits
+	hashShift      = workerIdShift + workerIdBits
 	timestampShift = hashShift + hashBits
 
 	sequenceMask  = (uint64(1) << sequenceBits) - 1
+	workerIdMask  = (uint64(1) << workerIdBits) - 1
 	hashMask      = (uint64(1) << hashBits) - 1
 	timestampMask = (uint64(1) << timestampBits) - 1
 
@@ -25,18 +30,51 @@ const (
 	number1000000 = uint64(1000000)
 )
 
+// maxBackwardMillis is the max clock rollback the generator waits for,
+// a larger rollback fails the generation instead of blocking the callers for long.
+const maxBackwardMillis = uint64(10)
+
+// MaxWorkerId is the max worker id the id can carry, so at most 32 instances generate ids at the same time.
+const MaxWorkerId = workerIdMask
+
 type Generator struct {
+	mu       sync.Mutex
 	sequence uint64
 	lastTime uint64    // the time of last id generated
 	epoch    time.Time // the epoch time
+
+	workerId uint64
+	// lost is closed when the worker id is no longer owned by the generator.
+	lost <-chan struct{}
+
+	maxBackward uint64
+	now         func() uint64 // milliseconds since the epoch
 }
 
+// NewGenerator creates a Generator of worker 0, which only works for a single instance.
 func NewGenerator() *Generator {
 	return &Generator{
-		sequence: 0,
-		lastTime: 0,
-		epoch:    time.Unix(int64(epochMillis/number1000), int64((epochMillis%number1000)*number1000000)),
+		sequence:    0,
+		lastTime:    0,
+		epoch:       time.Unix(int64(epochMillis/number1000), int64((epochMillis%number1000)*number1000000)),
+		maxBackward: maxBackwardMillis,
+		now: func() uint64 {
+			return uint64(time.Now().UnixMilli()) - epochMillis
+		},
+	}
+}
+
+// NewWorkerGenerator creates a Generator of the worker id,
+// the generator stops generating ids with errs.ErrWorkerIdLost once lost is closed.
+func NewWorkerGenerator(workerId uint64, lost <-chan struct{}) (*Generator, error) {
+	if workerId > MaxWorkerId {
+		return nil, fmt.Errorf("%w: worker id should be in range [0, %d]", errs.ErrInvalidParam, MaxWorkerId)
 	}
+
+	g := NewGenerator()
+	g.workerId = workerId
+	g.lost = lost
+	return g, nil
 }
 
 // NextId returns the next id.
@@ -45,14 +83,67 @@ func NewGenerator() *Generator {
 //   - 41 bits for timestamp, the timestamp is the milliseconds of the current time minus the epoch time.
 //   - 10 bits for hash, the hash is the hash value of the bizId and bizKey.
 //     bizId and bizKey decide the database sharding.
-//   - 12 bits for a sequence, the sequence is the auto incr sequence number of the id.
-func (g *Generator) NextId(bizId uint64, bizKey string) uint64 {
-	timestamp := uint64(time.Now().UnixMilli()) - epochMillis
-	hashVal := xxhash.Sum64String(HashKey(bizId, bizKey))
+//   - 5 bits for the worker id, which is unique among the running instances.
+//   - 7 bits for a sequence, the sequence is reset every millisecond,
+//     NextId waits for the next millisecond when the sequence of the current one is exhausted.
+//
+// The worker id takes 5 bits of the 12 bits sequence of the single instance layout,
+// the timestamp and the hash keep their positions, so the ids generated before still route to the same shards.
+// A generator issues at most 128 ids per millisecond, i.e. 128k ids per second,
+// and the cluster scales out to 32 instances for more.
+// The top bit is left zero, so the id fits in a signed BIGINT column.
+//
+// When the clock moves backwards, NextId waits for it to catch up if the rollback is within 10ms,
+// otherwise it returns errs.ErrClockMovedBackwards, as the ids may collide with the ones already generated.
+func (g *Generator) NextId(bizId uint64, bizKey string) (uint64, error) {
+	select {
+	case <-g.lost:
+		return 0, errs.ErrWorkerIdLost
+	default:
+	}
+
+	hashVal := Hash(bizId, bizKey)
 
-	seq := atomic.AddUint64(&g.sequence, 1) - 1
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	return (timestamp&timestampMask)<<timestampShift | (hashVal&hashMask)<<hashShift | (seq & sequenceMask)
+	timestamp := g.now()
+	if timestamp < g.lastTime {
+		backward := g.lastTime - timestamp
+		if backward > g.maxBackward {
+			return 0, fmt.Errorf("%w: %dms", errs.ErrClockMovedBackwards, backward)
+		}
+		timestamp = g.waitUntil(g.lastTime)
+	}
+
+	if timestamp == g.lastTime {
+		g.sequence = (g.sequence + 1) & sequenceMask
+		if g.sequence == 0 {
+			// the sequence of this millisecond is exhausted.
+			timestamp = g.waitUntil(g.lastTime + 1)
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastTime = timestamp
+
+	return (timestamp&timestampMask)<<timestampShift | hashVal<<hashShift | g.workerId<<workerIdShift | g.sequence, nil
+}
+
+// waitUntil blocks until the clock reaches the timestamp and returns the current time.
+func (g *Generator) waitUntil(timestamp uint64) uint64 {
+	now := g.now()
+	for now < timestamp {
+		time.Sleep(time.Duration(timestamp-now) * time.Millisecond)
+		now = g.now()
+	}
+	return now
+}
+
+// Hash returns the hash of the bizId and bizKey embedded in the id, which is the same as ExtractHash of the id.
+// The sharding strategies should shard by it, so that the id can be routed without the bizId and bizKey.
+func Hash(bizId uint64, bizKey string) uint64 {
+	return xxhash.Sum64String(HashKey(bizId, bizKey)) & hashMask
 }
 
 func HashKey(bizId uint64, bizKey string) string {
@@ -68,6 +159,10 @@ func ExtractHash(id uint64) uint64 {
 	return (id >> hashShift) & hashMask
 }
 
+func ExtractWorkerId(id uint64) uint64 {
+	return (id >> workerIdShift) & workerIdMask
+}
+
 func ExtractSequence(id uint64) uint64 {
 	return id & sequenceMask
 }
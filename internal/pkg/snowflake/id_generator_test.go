@@ -2,12 +2,38 @@ package snowflake
 
 import (
 	"strconv"
+	"sync"
 	"testing"
 
+	"github.com/JrMarcco/jotice/internal/errs"
 	"github.com/cespare/xxhash/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// fakeClock returns the times in order, and the last one repeatedly.
+type fakeClock struct {
+	mu    sync.Mutex
+	times []uint64
+}
+
+func (c *fakeClock) now() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := c.times[0]
+	if len(c.times) > 1 {
+		c.times = c.times[1:]
+	}
+	return t
+}
+
+func newFakeGenerator(times ...uint64) *Generator {
+	g := NewGenerator()
+	g.now = (&fakeClock{times: times}).now
+	return g
+}
+
 func TestGenerator_NextId(t *testing.T) {
 	t.Parallel()
 
@@ -16,7 +42,8 @@ func TestGenerator_NextId(t *testing.T) {
 	bizId := uint64(2025)
 	bizKey := "test_biz_key:" + strconv.FormatUint(bizId, 10)
 
-	id := g.NextId(bizId, bizKey)
+	id, err := g.NextId(bizId, bizKey)
+	require.NoError(t, err)
 
 	hashVal := ExtractHash(id)
 	if hashVal >= 1024 {
@@ -27,11 +54,10 @@ func TestGenerator_NextId(t *testing.T) {
 	assert.Equal(t, hashVal, wantHashVal%1024)
 
 	seq := ExtractSequence(id)
-	if seq >= (1 << 12) {
-		t.Errorf("seq should be in range [0, 4096), but got %d", seq)
+	if seq >= (1 << 7) {
+		t.Errorf("seq should be in range [0, 128), but got %d", seq)
 	}
 	assert.Equal(t, seq, uint64(0))
-
 }
 
 func TestGenerator_NextId_Uniqueness(t *testing.T) {
@@ -45,64 +71,179 @@ func TestGenerator_NextId_Uniqueness(t *testing.T) {
 		bizId := uint64(i % 100)                           // reuse bizId to test uniqueness
 		bizKey := "test_biz_key:" + string(rune('A'+i%26)) // reuse bizKey to test uniqueness
 
-		id := g.NextId(bizId, bizKey)
+		id, err := g.NextId(bizId, bizKey)
+		require.NoError(t, err)
 
-		if _, exists := ids[id]; exists {
-			t.Logf("id %d already exists", id)
-		}
+		_, exists := ids[id]
+		require.False(t, exists, "id %d already exists", id)
 		ids[id] = struct{}{}
 	}
-
-	conflictCnt := idCnt - len(ids)
-
-	t.Logf("generated %d ids", idCnt)
-	t.Logf("%d id conflicts and %.2f%% of the time. ",
-		conflictCnt,
-		float64(conflictCnt)/float64(idCnt)*float64(100),
-	)
 }
 
-func TestGenerator_NextId_SeqIncr(t *testing.T) {
+func TestGenerator_NextId_Concurrency(t *testing.T) {
 	t.Parallel()
 	g := NewGenerator()
 
-	biz := uint64(2025)
-	bizKey := "test_biz_key:" + strconv.FormatUint(biz, 10)
+	bizId := uint64(2025)
+	bizKey := "test_biz_key:" + strconv.FormatUint(bizId, 10)
 
-	cnt := 100
-	ids := make([]uint64, 0, cnt)
-	for i := 0; i < cnt; i++ {
-		id := g.NextId(biz, bizKey)
+	// the same biz key, so all the ids share the hash and differ only in timestamp and sequence.
+	// the 400k ids take at least 3.1s with 128 ids per millisecond, which covers thousands of sequence rollovers.
+	workerCnt := 8
+	idCnt := 50000
+	res := make([][]uint64, workerCnt)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workerCnt; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ids := make([]uint64, 0, idCnt)
+			for i := 0; i < idCnt; i++ {
+				id, err := g.NextId(bizId, bizKey)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				ids = append(ids, id)
+			}
+			res[w] = ids
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[uint64]struct{}, workerCnt*idCnt)
+	for _, ids := range res {
+		require.Len(t, ids, idCnt)
+		for i, id := range ids {
+			_, exists := seen[id]
+			require.False(t, exists, "id %d already exists", id)
+			seen[id] = struct{}{}
+
+			// ids are strictly increasing in each goroutine.
+			if i > 0 {
+				require.Greater(t, id, ids[i-1])
+			}
+		}
+	}
+}
 
-		seq := ExtractSequence(id)
-		assert.Equal(t, seq, uint64(i))
+func TestGenerator_NextId_SeqReset(t *testing.T) {
+	t.Parallel()
 
-		ids = append(ids, id)
+	g := newFakeGenerator(100, 100, 100, 101, 103)
+
+	tcs := []struct {
+		name    string
+		wantTs  uint64
+		wantSeq uint64
+	}{
+		{name: "first id of millisecond", wantTs: 100, wantSeq: 0},
+		{name: "same millisecond", wantTs: 100, wantSeq: 1},
+		{name: "same millisecond again", wantTs: 100, wantSeq: 2},
+		{name: "next millisecond", wantTs: 101, wantSeq: 0},
+		{name: "skipped milliseconds", wantTs: 103, wantSeq: 0},
 	}
 
-	wantHashVal := ExtractHash(ids[0])
-	for i := 1; i < cnt; i++ {
-		hashVal := ExtractHash(ids[i])
-		assert.Equal(t, hashVal, wantHashVal)
+	// run in order, each case depends on the state left by the previous one.
+	for _, tc := range tcs {
+		id, err := g.NextId(2025, "test_biz_key")
+		require.NoError(t, err, tc.name)
+
+		assert.Equal(t, tc.wantTs, id>>timestampShift, tc.name)
+		assert.Equal(t, tc.wantSeq, ExtractSequence(id), tc.name)
 	}
 }
 
-func TestGenerator_NextId_SeqRollover(t *testing.T) {
+func TestGenerator_NextId_SeqOverflow(t *testing.T) {
 	t.Parallel()
-	g := NewGenerator()
 
-	// change seq to max value
+	// the sequence of millisecond 100 is exhausted, waits for millisecond 101.
+	g := newFakeGenerator(100, 100, 101)
+	g.lastTime = 100
 	g.sequence = sequenceMask
 
-	bizId := uint64(2025)
-	bizKey := "test_biz_key:" + strconv.FormatUint(bizId, 10)
+	id, err := g.NextId(2025, "test_biz_key")
+	require.NoError(t, err)
 
-	id := g.NextId(bizId, bizKey)
+	assert.Equal(t, uint64(101), id>>timestampShift)
+	assert.Equal(t, uint64(0), ExtractSequence(id))
+}
 
-	seq := ExtractSequence(id)
-	assert.Equal(t, seq, sequenceMask, "sequence should be max value")
+func TestGenerator_NextId_ClockBackwards(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name    string
+		times   []uint64
+		wantTs  uint64
+		wantSeq uint64
+		wantErr error
+	}{
+		{
+			name:    "small rollback waits for the clock",
+			times:   []uint64{95, 97, 100},
+			wantTs:  100,
+			wantSeq: 8,
+		}, {
+			name:    "rollback of max backward waits for the clock",
+			times:   []uint64{90, 101},
+			wantTs:  101,
+			wantSeq: 0,
+		}, {
+			name:    "large rollback fails",
+			times:   []uint64{89},
+			wantErr: errs.ErrClockMovedBackwards,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			g := newFakeGenerator(tc.times...)
+			g.lastTime = 100
+			g.sequence = 7
+
+			id, err := g.NextId(2025, "test_biz_key")
+			assert.ErrorIs(t, err, tc.wantErr)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tc.wantTs, id>>timestampShift)
+			assert.Equal(t, tc.wantSeq, ExtractSequence(id))
+		})
+	}
+}
+
+func TestNewWorkerGenerator(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewWorkerGenerator(MaxWorkerId+1, nil)
+	assert.ErrorIs(t, err, errs.ErrInvalidParam)
+
+	lost := make(chan struct{})
+	g, err := NewWorkerGenerator(MaxWorkerId, lost)
+	require.NoError(t, err)
+
+	bizId := uint64(2025)
+	bizKey := "test_biz_key:" + strconv.FormatUint(bizId, 10)
 
-	id = g.NextId(bizId, bizKey)
-	seq = ExtractSequence(id)
-	assert.Equal(t, seq, uint64(0), "sequence should be rolled over to 0")
+	id, err := g.NextId(bizId, bizKey)
+	require.NoError(t, err)
+	assert.Equal(t, MaxWorkerId, ExtractWorkerId(id))
+	assert.Equal(t, Hash(bizId, bizKey), ExtractHash(id))
+	assert.Equal(t, uint64(0), ExtractSequence(id))
+
+	// ids of different workers never collide, even in the same millisecond with the same sequence.
+	other := newFakeGenerator(id >> timestampShift)
+	otherId, err := other.NextId(bizId, bizKey)
+	require.NoError(t, err)
+	assert.NotEqual(t, id, otherId)
+
+	close(lost)
+	_, err = g.NextId(bizId, bizKey)
+	assert.ErrorIs(t, err, errs.ErrWorkerIdLost)
 }
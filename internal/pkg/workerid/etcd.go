@@ -0,0 +1,193 @@
+package workerid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+const keyPrefix = "/jotice/worker_id/"
+
+const (
+	// maxDeadlineMargin is the max time the lease is given up before its local deadline,
+	// which covers the delay of the keepalive response and the clock drift between the instance and etcd.
+	maxDeadlineMargin = 2 * time.Second
+	// retryInterval is the interval of the retries after a keepalive fails.
+	retryInterval = 500 * time.Millisecond
+)
+
+// Lease is a worker id leased from etcd, bound to the lease of the session.
+//
+// The worker id is taken by creating its key with the session lease, the creation only succeeds when the key
+// does not exist, so the id of a live peer is never reused. The key is deleted by etcd when the lease expires,
+// and the id becomes available to other instances then, so the owner must stop using it once Done is closed.
+//
+// The lease keeps a local deadline, the time of the last successful keepalive plus the ttl minus a margin,
+// and Done is closed once the deadline passes without a keepalive, before etcd may give the id to others.
+type Lease struct {
+	id      uint64
+	session *concurrency.Session
+
+	// keepAlive renews the lease once and returns its ttl.
+	keepAlive   func(ctx context.Context) (time.Duration, error)
+	sessionDone <-chan struct{}
+
+	done     chan struct{}
+	doneOnce sync.Once
+	cancel   context.CancelFunc
+}
+
+func (l *Lease) Id() uint64 {
+	return l.id
+}
+
+// Done is closed when the lease is lost, e.g. expired, revoked, not renewed before the local deadline or released.
+func (l *Lease) Done() <-chan struct{} {
+	return l.done
+}
+
+// Release gives up the worker id if it is still held by the lease, the session is left to its owner.
+// Done is closed first, so no id is issued with the worker id after it is available to others.
+func (l *Lease) Release(ctx context.Context) error {
+	l.expire()
+	l.cancel()
+
+	k := key(l.id)
+	_, err := l.session.Client().Txn(ctx).
+		If(clientv3.Compare(clientv3.LeaseValue(k), "=", l.session.Lease())).
+		Then(clientv3.OpDelete(k)).
+		Commit()
+	return err
+}
+
+// watch renews the lease every third of the ttl, and closes Done once the lease is lost.
+func (l *Lease) watch(ctx context.Context, ttl time.Duration, deadline time.Time) {
+	defer l.expire()
+
+	next := time.Now().Add(ttl / 3)
+	for {
+		timer := time.NewTimer(time.Until(minTime(next, deadline)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-l.sessionDone:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		if !now.Before(deadline) {
+			return
+		}
+
+		renewCtx, cancel := context.WithDeadline(ctx, deadline)
+		renewedTTL, err := l.keepAlive(renewCtx)
+		cancel()
+
+		switch {
+		case errors.Is(err, rpctypes.ErrLeaseNotFound):
+			return
+		case err != nil:
+			next = time.Now().Add(retryInterval)
+		default:
+			ttl = renewedTTL
+			deadline = now.Add(ttl - deadlineMargin(ttl))
+			next = now.Add(ttl / 3)
+		}
+	}
+}
+
+func (l *Lease) expire() {
+	l.doneOnce.Do(func() {
+		close(l.done)
+	})
+}
+
+// newLease renews the lease once to set the first local deadline, then watches the lease in background.
+func newLease(
+	ctx context.Context, keepAlive func(ctx context.Context) (time.Duration, error), sessionDone <-chan struct{},
+) (*Lease, error) {
+	start := time.Now()
+	ttl, err := keepAlive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to keep alive the lease, cause of: %w", err)
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	l := &Lease{
+		keepAlive:   keepAlive,
+		sessionDone: sessionDone,
+		done:        make(chan struct{}),
+		cancel:      cancel,
+	}
+	go l.watch(watchCtx, ttl, start.Add(ttl-deadlineMargin(ttl)))
+	return l, nil
+}
+
+// Acquire leases the first free worker id in [0, maxId] with the lease of the session.
+func Acquire(ctx context.Context, session *concurrency.Session, maxId uint64) (*Lease, error) {
+	owner, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	owner = owner + ":" + strconv.Itoa(os.Getpid())
+
+	client := session.Client()
+	l, err := newLease(ctx, func(ctx context.Context) (time.Duration, error) {
+		resp, err := client.KeepAliveOnce(ctx, session.Lease())
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(resp.TTL) * time.Second, nil
+	}, session.Done())
+	if err != nil {
+		return nil, err
+	}
+
+	for id := uint64(0); id <= maxId; id++ {
+		k := key(id)
+		resp, err := client.Txn(ctx).
+			If(clientv3.Compare(clientv3.CreateRevision(k), "=", 0)).
+			Then(clientv3.OpPut(k, owner, clientv3.WithLease(session.Lease()))).
+			Commit()
+		if err != nil {
+			l.cancel()
+			return nil, fmt.Errorf("failed to acquire worker id %d, cause of: %w", id, err)
+		}
+
+		if resp.Succeeded {
+			l.id = id
+			l.session = session
+			return l, nil
+		}
+	}
+
+	l.cancel()
+	return nil, fmt.Errorf("%w: all the %d worker ids are taken", errs.ErrNoAvailableWorkerId, maxId+1)
+}
+
+func deadlineMargin(ttl time.Duration) time.Duration {
+	return min(maxDeadlineMargin, ttl/3)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func key(id uint64) string {
+	return keyPrefix + strconv.FormatUint(id, 10)
+}
@@ -0,0 +1,79 @@
+//go:build e2e
+
+package workerid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+func TestAcquire(t *testing.T) {
+	ctx := t.Context()
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{"192.168.3.3:2379"},
+		DialTimeout: time.Second,
+	})
+	require.NoError(t, err)
+	defer func() {
+		_, _ = client.Delete(ctx, keyPrefix, clientv3.WithPrefix())
+		_ = client.Close()
+	}()
+	_, err = client.Delete(ctx, keyPrefix, clientv3.WithPrefix())
+	require.NoError(t, err)
+
+	newSession := func() *concurrency.Session {
+		session, err := concurrency.NewSession(client, concurrency.WithTTL(5))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = session.Close() })
+		return session
+	}
+
+	// the instances take the free ids in order, the id of a live peer is never reused.
+	first, err := Acquire(ctx, newSession(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), first.Id())
+
+	second, err := Acquire(ctx, newSession(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), second.Id())
+
+	_, err = Acquire(ctx, newSession(), 1)
+	assert.ErrorIs(t, err, errs.ErrNoAvailableWorkerId)
+
+	// the released id is available again, and the lease of it is done.
+	require.NoError(t, first.Release(ctx))
+	select {
+	case <-first.Done():
+	default:
+		t.Fatal("released lease is not done")
+	}
+
+	third, err := Acquire(ctx, newSession(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), third.Id())
+
+	// releasing again does not delete the id taken by others.
+	require.NoError(t, first.Release(ctx))
+	resp, err := client.Get(ctx, key(0))
+	require.NoError(t, err)
+	assert.Len(t, resp.Kvs, 1)
+
+	// the lease of the closed session is done.
+	session := newSession()
+	require.NoError(t, third.Release(ctx))
+	fourth, err := Acquire(ctx, session, 1)
+	require.NoError(t, err)
+	require.NoError(t, session.Close())
+	select {
+	case <-fourth.Done():
+	case <-time.After(time.Second):
+		t.Fatal("lease of the closed session is not done")
+	}
+}
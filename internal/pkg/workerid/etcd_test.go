@@ -0,0 +1,113 @@
+package workerid
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+)
+
+var errKeepAlive = errors.New("keepalive failed")
+
+// fakeKeepAlive returns the ttl, or the error once failing is set.
+type fakeKeepAlive struct {
+	ttl     time.Duration
+	failing atomic.Pointer[error]
+	calls   atomic.Int32
+}
+
+func (f *fakeKeepAlive) keepAlive(context.Context) (time.Duration, error) {
+	f.calls.Add(1)
+	if err := f.failing.Load(); err != nil {
+		return 0, *err
+	}
+	return f.ttl, nil
+}
+
+func (f *fakeKeepAlive) fail(err error) {
+	f.failing.Store(&err)
+}
+
+func TestLease_Done(t *testing.T) {
+	t.Parallel()
+
+	ttl := 300 * time.Millisecond
+
+	tcs := []struct {
+		name string
+		// lose loses the lease, returns the max time to close Done after it.
+		lose func(ka *fakeKeepAlive, sessionDone chan struct{}, l *Lease) time.Duration
+	}{
+		{
+			name: "keepalive failed",
+			lose: func(ka *fakeKeepAlive, _ chan struct{}, _ *Lease) time.Duration {
+				// closed at the local deadline, before the lease expires in etcd.
+				ka.fail(errKeepAlive)
+				return ttl - deadlineMargin(ttl)
+			},
+		}, {
+			name: "lease not found",
+			lose: func(ka *fakeKeepAlive, _ chan struct{}, _ *Lease) time.Duration {
+				ka.fail(rpctypes.ErrLeaseNotFound)
+				return ttl / 3
+			},
+		}, {
+			name: "session closed",
+			lose: func(_ *fakeKeepAlive, sessionDone chan struct{}, _ *Lease) time.Duration {
+				close(sessionDone)
+				return 50 * time.Millisecond
+			},
+		}, {
+			name: "canceled",
+			lose: func(_ *fakeKeepAlive, _ chan struct{}, l *Lease) time.Duration {
+				l.expire()
+				l.cancel()
+				return 0
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ka := &fakeKeepAlive{ttl: ttl}
+			sessionDone := make(chan struct{})
+			l, err := newLease(t.Context(), ka.keepAlive, sessionDone)
+			require.NoError(t, err)
+
+			// kept alive over several ttls.
+			select {
+			case <-l.Done():
+				t.Fatal("lease is lost while kept alive")
+			case <-time.After(2 * ttl):
+			}
+			assert.GreaterOrEqual(t, ka.calls.Load(), int32(4))
+
+			lost := time.Now()
+			within := tc.lose(ka, sessionDone, l)
+
+			select {
+			case <-l.Done():
+				assert.LessOrEqual(t, time.Since(lost), within+50*time.Millisecond)
+			case <-time.After(ttl):
+				t.Fatal("lease is not lost before it expires")
+			}
+		})
+	}
+}
+
+func TestNewLease_KeepAliveFailed(t *testing.T) {
+	t.Parallel()
+
+	ka := &fakeKeepAlive{ttl: time.Second}
+	ka.fail(rpctypes.ErrLeaseNotFound)
+
+	_, err := newLease(t.Context(), ka.keepAlive, nil)
+	assert.ErrorIs(t, err, rpctypes.ErrLeaseNotFound)
+}
@@ -1,16 +1,308 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
 	"github.com/JrMarcco/jotice/internal/repository/cache"
 	"github.com/JrMarcco/jotice/internal/repository/dao"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
 )
 
-type BizConfigRepo interface{}
+type BizConfigRepo interface {
+	GetById(ctx context.Context, id uint64) (domain.BizConfig, error)
+	// Save saves the biz config and returns the revision created by this save.
+	Save(ctx context.Context, bizConfig domain.BizConfig, author string) (domain.BizConfigRevision, error)
+	Delete(ctx context.Context, id uint64) error
+
+	ListRevisions(ctx context.Context, bizId uint64, offset, limit int) ([]domain.BizConfigRevision, error)
+	GetRevision(ctx context.Context, bizId uint64, version int32) (domain.BizConfigRevision, error)
+}
+
+var _ BizConfigRepo = (*DefaultBizConfigRepo)(nil)
 
+// bizConfigLoadTimeout is the timeout of loading a biz config from db on cache miss.
+const bizConfigLoadTimeout = 3 * time.Second
+
+// DefaultBizConfigRepo reads biz config through local cache -> redis cache -> db.
+// The concurrent misses of the same biz id are collapsed into one db query.
 type DefaultBizConfigRepo struct {
-	dao    dao.ConfigDAO
+	dao    dao.BizConfigDAO
 	lc     cache.BizConfigCache
 	rc     cache.BizConfigCache
+	sg     singleflight.Group
 	logger *zap.Logger
+
+	// redeleteDelay is the delay to invalidate the biz config again after a save or delete.
+	redeleteDelay time.Duration
+}
+
+func (d *DefaultBizConfigRepo) GetById(ctx context.Context, id uint64) (domain.BizConfig, error) {
+	if bizConfig, err := d.lc.Get(ctx, id); err == nil {
+		return bizConfig, nil
+	}
+
+	if bizConfig, err := d.rc.Get(ctx, id); err == nil {
+		d.setLocal(ctx, bizConfig)
+		return bizConfig, nil
+	} else if !errors.Is(err, cache.ErrKeyNotFound) {
+		d.logger.Warn("[jotice] failed to get biz config from redis", zap.Uint64("biz_id", id), zap.Error(err))
+	}
+
+	ch := d.sg.DoChan(strconv.FormatUint(id, 10), func() (any, error) {
+		// the load is shared by all the waiters, so it is not canceled with the caller who happens to start it.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), bizConfigLoadTimeout)
+		defer cancel()
+		return d.getFromDB(ctx, id)
+	})
+
+	select {
+	case <-ctx.Done():
+		return domain.BizConfig{}, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return domain.BizConfig{}, res.Err
+		}
+		// the waiters share the loaded value, give each of them its own copy.
+		return res.Val.(domain.BizConfig).Clone(), nil
+	}
+}
+
+func (d *DefaultBizConfigRepo) getFromDB(ctx context.Context, id uint64) (domain.BizConfig, error) {
+	entity, err := d.dao.GetById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.BizConfig{}, fmt.Errorf("%w: biz id = %d", errs.ErrBizConfigNotFound, id)
+		}
+		return domain.BizConfig{}, err
+	}
+
+	bizConfig, err := d.toDomain(entity)
+	if err != nil {
+		return domain.BizConfig{}, err
+	}
+
+	// a load beyond the timeout may have read a biz config invalidated since, do not cache it,
+	// the delayed invalidation relies on all the loads caching within the timeout.
+	if ctx.Err() != nil {
+		return bizConfig, nil
+	}
+
+	if err = d.rc.Set(ctx, bizConfig); err != nil {
+		d.logger.Warn("[jotice] failed to set biz config to redis", zap.Uint64("biz_id", id), zap.Error(err))
+	}
+	d.setLocal(ctx, bizConfig)
+	return bizConfig, nil
+}
+
+func (d *DefaultBizConfigRepo) setLocal(ctx context.Context, bizConfig domain.BizConfig) {
+	if err := d.lc.Set(ctx, bizConfig); err != nil {
+		d.logger.Warn("[jotice] failed to set biz config to local cache", zap.Uint64("biz_id", bizConfig.Id), zap.Error(err))
+	}
+}
+
+func (d *DefaultBizConfigRepo) Save(
+	ctx context.Context, bizConfig domain.BizConfig, author string,
+) (domain.BizConfigRevision, error) {
+	entity, err := d.toEntity(bizConfig)
+	if err != nil {
+		return domain.BizConfigRevision{}, err
+	}
+
+	revision, err := d.dao.Save(ctx, entity, author)
+	if err != nil {
+		return domain.BizConfigRevision{}, err
+	}
+	d.invalidate(ctx, bizConfig.Id)
+	return d.toDomainRevision(revision)
+}
+
+func (d *DefaultBizConfigRepo) Delete(ctx context.Context, id uint64) error {
+	if err := d.dao.Delete(ctx, id); err != nil {
+		return err
+	}
+	d.invalidate(ctx, id)
+	return nil
+}
+
+func (d *DefaultBizConfigRepo) ListRevisions(
+	ctx context.Context, bizId uint64, offset, limit int,
+) ([]domain.BizConfigRevision, error) {
+	entities, err := d.dao.ListRevisions(ctx, bizId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]domain.BizConfigRevision, 0, len(entities))
+	for _, entity := range entities {
+		revision, err := d.toDomainRevision(entity)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+func (d *DefaultBizConfigRepo) GetRevision(
+	ctx context.Context, bizId uint64, version int32,
+) (domain.BizConfigRevision, error) {
+	entity, err := d.dao.GetRevision(ctx, bizId, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.BizConfigRevision{}, fmt.Errorf(
+				"%w: biz id = %d, version = %d", errs.ErrBizConfigRevisionNotFound, bizId, version,
+			)
+		}
+		return domain.BizConfigRevision{}, err
+	}
+	return d.toDomainRevision(entity)
+}
+
+// invalidate drops the biz config from redis and from the local cache of all the instances.
+// A load started before the invalidation may still write the old biz config back to the caches,
+// so the biz config is dropped again after the load timeout, when all such loads have finished.
+// The caches expire eventually, so the failure is only logged.
+func (d *DefaultBizConfigRepo) invalidate(ctx context.Context, id uint64) {
+	d.del(ctx, id)
+
+	time.AfterFunc(d.redeleteDelay, func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), bizConfigLoadTimeout)
+		defer cancel()
+		d.del(ctx, id)
+	})
+}
+
+func (d *DefaultBizConfigRepo) del(ctx context.Context, id uint64) {
+	if err := d.rc.Del(ctx, id); err != nil {
+		d.logger.Warn("[jotice] failed to delete biz config from redis", zap.Uint64("biz_id", id), zap.Error(err))
+	}
+	if err := d.lc.Del(ctx, id); err != nil {
+		d.logger.Warn("[jotice] failed to invalidate local biz config", zap.Uint64("biz_id", id), zap.Error(err))
+	}
+}
+
+func (d *DefaultBizConfigRepo) toEntity(bizConfig domain.BizConfig) (dao.BizConfig, error) {
+	entity := dao.BizConfig{
+		Id:        bizConfig.Id,
+		OwnerId:   bizConfig.OwnerId,
+		OwnerType: bizConfig.OwnerType,
+		RateLimit: bizConfig.RateLimit,
+	}
+
+	var err error
+	if entity.ChannelConfig, err = marshalSubConfig(bizConfig.ChannelConfig); err != nil {
+		return dao.BizConfig{}, err
+	}
+	if entity.TxNotifConfig, err = marshalSubConfig(bizConfig.TxNotifConfig); err != nil {
+		return dao.BizConfig{}, err
+	}
+	if entity.QuotaConfig, err = marshalSubConfig(bizConfig.QuotaConfig); err != nil {
+		return dao.BizConfig{}, err
+	}
+	if entity.CallbackConfig, err = marshalSubConfig(bizConfig.CallbackConfig); err != nil {
+		return dao.BizConfig{}, err
+	}
+	return entity, nil
+}
+
+func (d *DefaultBizConfigRepo) toDomain(entity dao.BizConfig) (domain.BizConfig, error) {
+	bizConfig := domain.BizConfig{
+		Id:        entity.Id,
+		OwnerId:   entity.OwnerId,
+		OwnerType: entity.OwnerType,
+		RateLimit: entity.RateLimit,
+		CreateAt:  entity.CreatedAt,
+		UpdateAt:  entity.UpdatedAt,
+	}
+
+	var err error
+	if bizConfig.ChannelConfig, err = unmarshalSubConfig[domain.ChannelConfig](entity.ChannelConfig); err != nil {
+		return domain.BizConfig{}, err
+	}
+	if bizConfig.TxNotifConfig, err = unmarshalSubConfig[domain.TxNotifConfig](entity.TxNotifConfig); err != nil {
+		return domain.BizConfig{}, err
+	}
+	if bizConfig.QuotaConfig, err = unmarshalSubConfig[domain.QuotaConfig](entity.QuotaConfig); err != nil {
+		return domain.BizConfig{}, err
+	}
+	if bizConfig.CallbackConfig, err = unmarshalSubConfig[domain.CallbackConfig](entity.CallbackConfig); err != nil {
+		return domain.BizConfig{}, err
+	}
+	return bizConfig, nil
+}
+
+func (d *DefaultBizConfigRepo) toDomainRevision(entity dao.BizConfigRevision) (domain.BizConfigRevision, error) {
+	bizConfig, err := d.toDomain(dao.BizConfig{
+		Id:             entity.BizId,
+		OwnerId:        entity.OwnerId,
+		OwnerType:      entity.OwnerType,
+		ChannelConfig:  entity.ChannelConfig,
+		TxNotifConfig:  entity.TxNotifConfig,
+		RateLimit:      entity.RateLimit,
+		QuotaConfig:    entity.QuotaConfig,
+		CallbackConfig: entity.CallbackConfig,
+		CreatedAt:      entity.CreatedAt,
+		UpdatedAt:      entity.CreatedAt,
+	})
+	if err != nil {
+		return domain.BizConfigRevision{}, err
+	}
+
+	return domain.BizConfigRevision{
+		Id:       entity.Id,
+		BizId:    entity.BizId,
+		Version:  entity.Version,
+		Config:   bizConfig,
+		Author:   entity.Author,
+		CreateAt: entity.CreatedAt,
+	}, nil
+}
+
+// marshalSubConfig marshals sub-config to json, returns null if the sub-config is nil.
+func marshalSubConfig[T any](val *T) (sql.NullString, error) {
+	if val == nil {
+		return sql.NullString{}, nil
+	}
+
+	jsonBytes, err := json.Marshal(val)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(jsonBytes), Valid: true}, nil
+}
+
+// unmarshalSubConfig unmarshals json to sub-config, returns nil if the json is null.
+func unmarshalSubConfig[T any](val sql.NullString) (*T, error) {
+	if !val.Valid || val.String == "" {
+		return nil, nil
+	}
+
+	var res T
+	if err := json.Unmarshal([]byte(val.String), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func NewDefaultBizConfigRepo(
+	dao dao.BizConfigDAO, lc cache.BizConfigCache, rc cache.BizConfigCache, logger *zap.Logger,
+) *DefaultBizConfigRepo {
+	return &DefaultBizConfigRepo{
+		dao:    dao,
+		lc:     lc,
+		rc:     rc,
+		logger: logger,
+
+		redeleteDelay: bizConfigLoadTimeout,
+	}
 }
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/repository/cache"
+	"github.com/JrMarcco/jotice/internal/repository/dao"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memBizConfigCache is a biz config cache in memory, which copies the biz configs in and out as the real caches.
+type memBizConfigCache struct {
+	mu      sync.Mutex
+	configs map[uint64]domain.BizConfig
+}
+
+func newMemBizConfigCache() *memBizConfigCache {
+	return &memBizConfigCache{configs: make(map[uint64]domain.BizConfig)}
+}
+
+func (m *memBizConfigCache) Get(_ context.Context, bizId uint64) (domain.BizConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bc, ok := m.configs[bizId]
+	if !ok {
+		return domain.BizConfig{}, cache.ErrKeyNotFound
+	}
+	return bc.Clone(), nil
+}
+
+func (m *memBizConfigCache) Set(_ context.Context, bizConfig domain.BizConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.configs[bizConfig.Id] = bizConfig.Clone()
+	return nil
+}
+
+func (m *memBizConfigCache) Del(_ context.Context, bizId uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.configs, bizId)
+	return nil
+}
+
+// blockingBizConfigDAO blocks GetById until release is closed.
+type blockingBizConfigDAO struct {
+	dao.BizConfigDAO
+
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (b *blockingBizConfigDAO) GetById(ctx context.Context, id uint64) (dao.BizConfig, error) {
+	b.calls.Add(1)
+
+	select {
+	case <-ctx.Done():
+		return dao.BizConfig{}, ctx.Err()
+	case <-b.release:
+	}
+
+	return dao.BizConfig{
+		Id:          id,
+		OwnerId:     1,
+		OwnerType:   "organization",
+		QuotaConfig: sql.NullString{String: `{"daily":{"sms":100}}`, Valid: true},
+	}, nil
+}
+
+func (b *blockingBizConfigDAO) Delete(context.Context, uint64) error {
+	return nil
+}
+
+func TestDefaultBizConfigRepo_GetById(t *testing.T) {
+	t.Parallel()
+
+	bizDAO := &blockingBizConfigDAO{release: make(chan struct{})}
+	lc, rc := newMemBizConfigCache(), newMemBizConfigCache()
+	repo := NewDefaultBizConfigRepo(bizDAO, lc, rc, zap.NewNop())
+
+	// the first caller gives up before the db query finishes
+	canceledCtx, cancel := context.WithCancel(t.Context())
+	canceledErr := make(chan error, 1)
+	go func() {
+		_, err := repo.GetById(canceledCtx, 1)
+		canceledErr <- err
+	}()
+	require.Eventually(t, func() bool {
+		return bizDAO.calls.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	const waiters = 5
+	var wg sync.WaitGroup
+	res := make([]domain.BizConfig, waiters)
+	errs := make([]error, waiters)
+	for i := range waiters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res[i], errs[i] = repo.GetById(t.Context(), 1)
+		}()
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-canceledErr, context.Canceled)
+
+	close(bizDAO.release)
+	wg.Wait()
+
+	// the concurrent misses are collapsed into one db query, which is not canceled with the first caller
+	assert.Equal(t, int32(1), bizDAO.calls.Load())
+	for i := range waiters {
+		require.NoError(t, errs[i])
+		assert.Equal(t, int32(100), res[i].QuotaConfig.Daily.SMS)
+	}
+
+	// each waiter has its own copy
+	res[0].QuotaConfig.Daily.SMS = 0
+	assert.Equal(t, int32(100), res[1].QuotaConfig.Daily.SMS)
+
+	// the loaded biz config is cached in both levels
+	_, err := lc.Get(t.Context(), 1)
+	assert.NoError(t, err)
+	_, err = rc.Get(t.Context(), 1)
+	assert.NoError(t, err)
+}
+
+func TestDefaultBizConfigRepo_StaleLoad(t *testing.T) {
+	t.Parallel()
+
+	bizDAO := &blockingBizConfigDAO{release: make(chan struct{})}
+	lc, rc := newMemBizConfigCache(), newMemBizConfigCache()
+	repo := NewDefaultBizConfigRepo(bizDAO, lc, rc, zap.NewNop())
+	repo.redeleteDelay = 50 * time.Millisecond
+
+	// the load reads the biz config before it is deleted, and caches it after the invalidation
+	loadErr := make(chan error, 1)
+	go func() {
+		_, err := repo.GetById(t.Context(), 1)
+		loadErr <- err
+	}()
+	require.Eventually(t, func() bool {
+		return bizDAO.calls.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, repo.Delete(t.Context(), 1))
+	close(bizDAO.release)
+	require.NoError(t, <-loadErr)
+
+	_, err := rc.Get(t.Context(), 1)
+	require.NoError(t, err)
+
+	// the stale biz config is dropped again after the delay
+	require.Eventually(t, func() bool {
+		_, lErr := lc.Get(t.Context(), 1)
+		_, rErr := rc.Get(t.Context(), 1)
+		return errors.Is(lErr, cache.ErrKeyNotFound) && errors.Is(rErr, cache.ErrKeyNotFound)
+	}, time.Second, 5*time.Millisecond)
+}
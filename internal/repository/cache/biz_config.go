@@ -1,4 +1,22 @@
 package cache
 
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+)
+
+var ErrKeyNotFound = errors.New("[jotice] cache key not found")
+
 type BizConfigCache interface {
+	// Get gets the biz config from cache, returns ErrKeyNotFound if the biz config is not cached.
+	Get(ctx context.Context, bizId uint64) (domain.BizConfig, error)
+	Set(ctx context.Context, bizConfig domain.BizConfig) error
+	Del(ctx context.Context, bizId uint64) error
+}
+
+func BizConfigKey(bizId uint64) string {
+	return "biz_config:" + strconv.FormatUint(bizId, 10)
 }
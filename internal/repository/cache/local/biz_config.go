@@ -1,15 +1,82 @@
 package local
 
 import (
+	"context"
+	"strconv"
+
+	"github.com/JrMarcco/jotice/internal/domain"
 	"github.com/JrMarcco/jotice/internal/repository/cache"
 	gcache "github.com/patrickmn/go-cache"
 	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 )
 
+// invalidateChannel is the redis pub/sub channel to notify all the instances to drop the local biz config.
+const invalidateChannel = "biz_config:invalidate"
+
 var _ cache.BizConfigCache = (*LBizConfigCache)(nil)
 
 // LBizConfigCache is a local cache implementation for biz config.
+//
+// The biz configs are copied in and out, so the callers can not change the cached one through the sub configs.
+// Del drops the local biz config and publishes the invalidation through redis,
+// every instance subscribing the invalidation (see Start) will drop its local copy too.
 type LBizConfigCache struct {
-	c   *gcache.Cache
-	rdb redis.Cmdable
+	c      *gcache.Cache
+	rdb    redis.UniversalClient
+	logger *zap.Logger
+}
+
+func (l *LBizConfigCache) Get(_ context.Context, bizId uint64) (domain.BizConfig, error) {
+	val, ok := l.c.Get(cache.BizConfigKey(bizId))
+	if !ok {
+		return domain.BizConfig{}, cache.ErrKeyNotFound
+	}
+	return val.(domain.BizConfig).Clone(), nil
+}
+
+func (l *LBizConfigCache) Set(_ context.Context, bizConfig domain.BizConfig) error {
+	l.c.Set(cache.BizConfigKey(bizConfig.Id), bizConfig.Clone(), gcache.DefaultExpiration)
+	return nil
+}
+
+func (l *LBizConfigCache) Del(ctx context.Context, bizId uint64) error {
+	l.c.Delete(cache.BizConfigKey(bizId))
+	return l.rdb.Publish(ctx, invalidateChannel, strconv.FormatUint(bizId, 10)).Err()
+}
+
+// Start subscribes the invalidation of biz config from other instances.
+func (l *LBizConfigCache) Start(ctx context.Context) {
+	pubsub := l.rdb.Subscribe(ctx, invalidateChannel)
+
+	go func() {
+		defer func() { _ = pubsub.Close() }()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+
+				bizId, err := strconv.ParseUint(msg.Payload, 10, 64)
+				if err != nil {
+					l.logger.Warn("[jotice] invalid biz config invalidation", zap.String("payload", msg.Payload))
+					continue
+				}
+				l.c.Delete(cache.BizConfigKey(bizId))
+			}
+		}
+	}()
+}
+
+func NewLBizConfigCache(c *gcache.Cache, rdb redis.UniversalClient, logger *zap.Logger) *LBizConfigCache {
+	return &LBizConfigCache{
+		c:      c,
+		rdb:    rdb,
+		logger: logger,
+	}
 }
@@ -0,0 +1,46 @@
+//go:build e2e
+
+package local
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/repository/cache"
+	gcache "github.com/patrickmn/go-cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestLBizConfigCache_Invalidate(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "192.168.3.3:6379",
+		Password: "<passwd>",
+	})
+	defer func() { _ = client.Close() }()
+
+	// two instances with their own local cache
+	self := NewLBizConfigCache(gcache.New(time.Minute, time.Minute), client, zap.NewNop())
+	peer := NewLBizConfigCache(gcache.New(time.Minute, time.Minute), client, zap.NewNop())
+	peer.Start(ctx)
+
+	require.NoError(t, self.Set(ctx, domain.BizConfig{Id: 1}))
+	require.NoError(t, peer.Set(ctx, domain.BizConfig{Id: 1}))
+
+	require.NoError(t, self.Del(ctx, 1))
+
+	_, err := self.Get(ctx, 1)
+	assert.ErrorIs(t, err, cache.ErrKeyNotFound)
+
+	assert.Eventually(t, func() bool {
+		_, err := peer.Get(ctx, 1)
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+}
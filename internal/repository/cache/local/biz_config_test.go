@@ -0,0 +1,46 @@
+package local
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	gcache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestLBizConfigCache_Copy(t *testing.T) {
+	t.Parallel()
+
+	lbc := NewLBizConfigCache(gcache.New(time.Minute, time.Minute), nil, zap.NewNop())
+
+	bizConfig := domain.BizConfig{
+		Id: 1,
+		ChannelConfig: &domain.ChannelConfig{
+			Channels: []domain.ChannelItem{{Channel: domain.ChannelSMS.String(), Enabled: true}},
+		},
+		QuotaConfig: &domain.QuotaConfig{
+			Daily:           &domain.DailyQuotaConfig{SMS: 100},
+			AlertThresholds: []int32{80, 100},
+		},
+	}
+	require.NoError(t, lbc.Set(t.Context(), bizConfig))
+
+	// changing the biz config set does not change the cached one
+	bizConfig.QuotaConfig.Daily.SMS = 0
+	bizConfig.ChannelConfig.Channels[0].Enabled = false
+
+	got, err := lbc.Get(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(100), got.QuotaConfig.Daily.SMS)
+	assert.True(t, got.ChannelConfig.Channels[0].Enabled)
+
+	// neither does changing the biz config got
+	got.QuotaConfig.AlertThresholds[0] = 50
+
+	got, err = lbc.Get(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int32{80, 100}, got.QuotaConfig.AlertThresholds)
+}
@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+)
+
+type QuotaCache interface {
+	// Deduct deducts all the items or none of them, returns the used quota after deducted of each item.
+	// Returns errs.ErrQuotaExhausted if any item exceeds its limit,
+	// returns ErrKeyNotFound if any quota counter is not initialized.
+	Deduct(ctx context.Context, items []domain.QuotaItem) ([]int64, error)
+	// Refund gives back the deducted items, the quota counters never go below zero.
+	Refund(ctx context.Context, items []domain.QuotaItem) error
+	// Init initializes the quota counters which are not existing with the used quota.
+	Init(ctx context.Context, usages []domain.QuotaUsage) error
+	// GetUsages gets the used quota of the quota counters, the quota counters not existing are omitted.
+	GetUsages(ctx context.Context, keys []domain.QuotaUsage) ([]domain.QuotaUsage, error)
+	// ListUsages lists the used quota of all the quota counters.
+	ListUsages(ctx context.Context) ([]domain.QuotaUsage, error)
+
+	// ClaimAlert claims the alert of the biz on the channel for the period and threshold of the event,
+	// returns false if it has been claimed already.
+	ClaimAlert(ctx context.Context, event domain.QuotaAlertEvent) (bool, error)
+	// ReleaseAlert releases the claimed alert, e.g. the alert failed to emit.
+	ReleaseAlert(ctx context.Context, event domain.QuotaAlertEvent) error
+}
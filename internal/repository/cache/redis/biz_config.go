@@ -1,6 +1,12 @@
 package redis
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
 	"github.com/JrMarcco/jotice/internal/repository/cache"
 	"github.com/redis/go-redis/v9"
 )
@@ -9,5 +15,41 @@ var _ cache.BizConfigCache = (*RBizCacheConfig)(nil)
 
 // RBizCacheConfig is a redis cache implementation for biz config.
 type RBizCacheConfig struct {
-	rdb *redis.Cmdable
+	rdb     redis.Cmdable
+	expires time.Duration
+}
+
+func (r *RBizCacheConfig) Get(ctx context.Context, bizId uint64) (domain.BizConfig, error) {
+	val, err := r.rdb.Get(ctx, cache.BizConfigKey(bizId)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return domain.BizConfig{}, cache.ErrKeyNotFound
+		}
+		return domain.BizConfig{}, err
+	}
+
+	var bizConfig domain.BizConfig
+	if err = json.Unmarshal(val, &bizConfig); err != nil {
+		return domain.BizConfig{}, err
+	}
+	return bizConfig, nil
+}
+
+func (r *RBizCacheConfig) Set(ctx context.Context, bizConfig domain.BizConfig) error {
+	val, err := json.Marshal(bizConfig)
+	if err != nil {
+		return err
+	}
+	return r.rdb.Set(ctx, cache.BizConfigKey(bizConfig.Id), val, r.expires).Err()
+}
+
+func (r *RBizCacheConfig) Del(ctx context.Context, bizId uint64) error {
+	return r.rdb.Del(ctx, cache.BizConfigKey(bizId)).Err()
+}
+
+func NewRBizCacheConfig(rdb redis.Cmdable, expires time.Duration) *RBizCacheConfig {
+	return &RBizCacheConfig{
+		rdb:     rdb,
+		expires: expires,
+	}
 }
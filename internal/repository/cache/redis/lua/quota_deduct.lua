@@ -0,0 +1,22 @@
+-- KEYS: quota counters.
+-- ARGV: limit and amount of each quota counter, in the same order as KEYS.
+-- Returns {1, used...} with the used quota after deducted of each quota counter,
+-- {0} if any quota exhausted, {-1} if any quota counter is not initialized.
+for i, key in ipairs(KEYS) do
+    local used = redis.call('GET', key)
+    if not used then
+        return { -1 }
+    end
+
+    local limit = tonumber(ARGV[i * 2 - 1])
+    local amount = tonumber(ARGV[i * 2])
+    if tonumber(used) + amount > limit then
+        return { 0 }
+    end
+end
+
+local res = { 1 }
+for i, key in ipairs(KEYS) do
+    res[i + 1] = redis.call('INCRBY', key, tonumber(ARGV[i * 2]))
+end
+return res
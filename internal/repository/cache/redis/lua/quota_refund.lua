@@ -0,0 +1,13 @@
+-- KEYS: quota counters.
+-- ARGV: amount of each quota counter, in the same order as KEYS.
+-- The quota counter which is not existing is skipped, and it never goes below zero.
+for i, key in ipairs(KEYS) do
+    local used = redis.call('GET', key)
+    if used then
+        local amount = math.min(tonumber(used), tonumber(ARGV[i]))
+        if amount > 0 then
+            redis.call('DECRBY', key, amount)
+        end
+    end
+end
+return 1
@@ -0,0 +1,250 @@
+package redis
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/repository/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	quotaKeyPrefix = "quota:"
+	// the alert keys should not start with quotaKeyPrefix, or they are scanned as quota counters.
+	quotaAlertKeyPrefix = "quota_alert:"
+
+	// the quota counters are kept a little longer than the period, so the last usage can be persisted.
+	dailyQuotaExpiration   = 48 * time.Hour
+	monthlyQuotaExpiration = 33 * 24 * time.Hour
+
+	quotaScanCount = 256
+)
+
+var (
+	//go:embed lua/quota_deduct.lua
+	quotaDeductLua string
+	//go:embed lua/quota_refund.lua
+	quotaRefundLua string
+
+	quotaDeductScript = redis.NewScript(quotaDeductLua)
+	quotaRefundScript = redis.NewScript(quotaRefundLua)
+)
+
+var _ cache.QuotaCache = (*RQuotaCache)(nil)
+
+// RQuotaCache is a redis quota counter implementation.
+// The biz id is used as hash tag, so the quota counters of a biz are in the same slot of a redis cluster.
+type RQuotaCache struct {
+	rdb redis.Cmdable
+}
+
+func (r *RQuotaCache) Deduct(ctx context.Context, items []domain.QuotaItem) ([]int64, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(items))
+	args := make([]any, 0, 2*len(items))
+	for _, item := range items {
+		keys = append(keys, quotaKey(item.BizId, item.Channel, item.Period))
+		args = append(args, item.Limit, item.Amount)
+	}
+
+	res, err := quotaDeductScript.Run(ctx, r.rdb, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	switch res[0] {
+	case 1:
+		return res[1:], nil
+	case 0:
+		return nil, errs.ErrQuotaExhausted
+	default:
+		return nil, cache.ErrKeyNotFound
+	}
+}
+
+func (r *RQuotaCache) Refund(ctx context.Context, items []domain.QuotaItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(items))
+	args := make([]any, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, quotaKey(item.BizId, item.Channel, item.Period))
+		args = append(args, item.Amount)
+	}
+	return quotaRefundScript.Run(ctx, r.rdb, keys, args...).Err()
+}
+
+func (r *RQuotaCache) Init(ctx context.Context, usages []domain.QuotaUsage) error {
+	pipeline := r.rdb.Pipeline()
+	for _, usage := range usages {
+		pipeline.SetNX(ctx, quotaKey(usage.BizId, usage.Channel, usage.Period), usage.Used, quotaExpiration(usage.Period))
+	}
+
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+func (r *RQuotaCache) GetUsages(ctx context.Context, keys []domain.QuotaUsage) ([]domain.QuotaUsage, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	redisKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		redisKeys = append(redisKeys, quotaKey(key.BizId, key.Channel, key.Period))
+	}
+	return r.getUsages(ctx, r.rdb, redisKeys)
+}
+
+// ListUsages scans the quota counters node by node in a redis cluster,
+// because SCAN only covers the keys of the node it is sent to.
+func (r *RQuotaCache) ListUsages(ctx context.Context) ([]domain.QuotaUsage, error) {
+	cluster, ok := r.rdb.(*redis.ClusterClient)
+	if !ok {
+		return r.scanUsages(ctx, r.rdb)
+	}
+
+	var mu sync.Mutex
+	var usages []domain.QuotaUsage
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		nodeUsages, err := r.scanUsages(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		usages = append(usages, nodeUsages...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
+
+func (r *RQuotaCache) scanUsages(ctx context.Context, rdb redis.Cmdable) ([]domain.QuotaUsage, error) {
+	var usages []domain.QuotaUsage
+
+	var cursor uint64
+	for {
+		keys, next, err := rdb.Scan(ctx, cursor, quotaKeyPrefix+"*", quotaScanCount).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(keys) > 0 {
+			scanned, err := r.getUsages(ctx, rdb, keys)
+			if err != nil {
+				return nil, err
+			}
+			usages = append(usages, scanned...)
+		}
+
+		if next == 0 {
+			return usages, nil
+		}
+		cursor = next
+	}
+}
+
+// getUsages gets the quota counters by GET in a pipeline instead of MGET,
+// the keys of different bizs are in different slots, which MGET does not allow in a redis cluster.
+// The quota counters not existing are omitted, e.g. expired after scan.
+func (r *RQuotaCache) getUsages(ctx context.Context, rdb redis.Cmdable, keys []string) ([]domain.QuotaUsage, error) {
+	pipeline := rdb.Pipeline()
+	cmds := make([]*redis.StringCmd, 0, len(keys))
+	for _, key := range keys {
+		cmds = append(cmds, pipeline.Get(ctx, key))
+	}
+
+	if _, err := pipeline.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	usages := make([]domain.QuotaUsage, 0, len(keys))
+	for i, cmd := range cmds {
+		val, err := cmd.Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			return nil, err
+		}
+
+		usage, err := parseQuotaUsage(keys[i], val)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+func (r *RQuotaCache) ClaimAlert(ctx context.Context, event domain.QuotaAlertEvent) (bool, error) {
+	return r.rdb.SetNX(ctx, quotaAlertKey(event), event.AlertAt, quotaExpiration(event.Period)).Result()
+}
+
+func (r *RQuotaCache) ReleaseAlert(ctx context.Context, event domain.QuotaAlertEvent) error {
+	return r.rdb.Del(ctx, quotaAlertKey(event)).Err()
+}
+
+func quotaExpiration(period string) time.Duration {
+	if domain.IsMonthlyQuotaPeriod(period) {
+		return monthlyQuotaExpiration
+	}
+	return dailyQuotaExpiration
+}
+
+// quotaAlertKey returns the key of quota alert, e.g. "quota_alert:{1}:sms:d20250101:80".
+func quotaAlertKey(event domain.QuotaAlertEvent) string {
+	return fmt.Sprintf("%s{%d}:%s:%s:%d", quotaAlertKeyPrefix, event.BizId, event.Channel, event.Period, event.Threshold)
+}
+
+// quotaKey returns the key of quota counter, e.g. "quota:{1}:sms:d20250101".
+func quotaKey(bizId uint64, channel domain.Channel, period string) string {
+	return fmt.Sprintf("%s{%d}:%s:%s", quotaKeyPrefix, bizId, channel, period)
+}
+
+func parseQuotaUsage(key string, val string) (domain.QuotaUsage, error) {
+	parts := strings.Split(strings.TrimPrefix(key, quotaKeyPrefix), ":")
+	if len(parts) != 3 {
+		return domain.QuotaUsage{}, fmt.Errorf("invalid quota key %s", key)
+	}
+
+	bizId, err := strconv.ParseUint(strings.Trim(parts[0], "{}"), 10, 64)
+	if err != nil {
+		return domain.QuotaUsage{}, fmt.Errorf("invalid quota key %s, cause of: %w", key, err)
+	}
+
+	used, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return domain.QuotaUsage{}, fmt.Errorf("invalid quota of key %s, cause of: %w", key, err)
+	}
+
+	return domain.QuotaUsage{
+		BizId:   bizId,
+		Channel: domain.Channel(parts[1]),
+		Period:  parts[2],
+		Used:    used,
+	}, nil
+}
+
+func NewRQuotaCache(rdb redis.Cmdable) *RQuotaCache {
+	return &RQuotaCache{
+		rdb: rdb,
+	}
+}
@@ -0,0 +1,116 @@
+//go:build e2e
+
+package redis
+
+import (
+	"testing"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/repository/cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRQuotaCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "192.168.3.3:6379",
+		Password: "<passwd>",
+	})
+	daily := domain.QuotaUsage{BizId: 9527, Channel: domain.ChannelSMS, Period: "d20250101"}
+	monthly := domain.QuotaUsage{BizId: 9527, Channel: domain.ChannelSMS, Period: "m202501"}
+	defer func() {
+		client.Del(ctx,
+			quotaKey(daily.BizId, daily.Channel, daily.Period),
+			quotaKey(monthly.BizId, monthly.Channel, monthly.Period),
+		)
+		_ = client.Close()
+	}()
+
+	qc := NewRQuotaCache(client)
+
+	items := []domain.QuotaItem{
+		{BizId: daily.BizId, Channel: daily.Channel, Period: daily.Period, Limit: 10, Amount: 4},
+		{BizId: monthly.BizId, Channel: monthly.Channel, Period: monthly.Period, Limit: 100, Amount: 4},
+	}
+
+	_, err := qc.Deduct(ctx, items)
+	assert.ErrorIs(t, err, cache.ErrKeyNotFound)
+
+	// the monthly counter is initialized with the used quota in db
+	daily.Used, monthly.Used = 0, 50
+	require.NoError(t, qc.Init(ctx, []domain.QuotaUsage{daily, monthly}))
+
+	used, err := qc.Deduct(ctx, items)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4, 54}, used)
+
+	used, err = qc.Deduct(ctx, items)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{8, 58}, used)
+
+	// the daily quota is exhausted, the monthly one is not deducted either
+	_, err = qc.Deduct(ctx, items)
+	assert.ErrorIs(t, err, errs.ErrQuotaExhausted)
+
+	// the refund never goes below zero
+	require.NoError(t, qc.Refund(ctx, []domain.QuotaItem{
+		{BizId: daily.BizId, Channel: daily.Channel, Period: daily.Period, Amount: 100},
+		{BizId: monthly.BizId, Channel: monthly.Channel, Period: monthly.Period, Amount: 4},
+	}))
+
+	usages, err := qc.GetUsages(ctx, []domain.QuotaUsage{
+		daily, monthly, {BizId: 9527, Channel: domain.ChannelEmail, Period: "d20250101"},
+	})
+	require.NoError(t, err)
+	daily.Used, monthly.Used = 0, 54
+	assert.Equal(t, []domain.QuotaUsage{daily, monthly}, usages)
+
+	usages, err = qc.ListUsages(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, usages, daily)
+	assert.Contains(t, usages, monthly)
+}
+
+func TestRQuotaCache_ClaimAlert(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "192.168.3.3:6379",
+		Password: "<passwd>",
+	})
+	event := domain.QuotaAlertEvent{BizId: 9528, Channel: domain.ChannelSMS, Period: "d20250101", Threshold: 80}
+	defer func() {
+		client.Del(ctx, quotaAlertKey(event))
+		_ = client.Close()
+	}()
+
+	qc := NewRQuotaCache(client)
+
+	claimed, err := qc.ClaimAlert(ctx, event)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = qc.ClaimAlert(ctx, event)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	// the alert keys are not listed as quota counters
+	usages, err := qc.ListUsages(ctx)
+	require.NoError(t, err)
+	for _, usage := range usages {
+		assert.NotEqual(t, event.BizId, usage.BizId)
+	}
+
+	require.NoError(t, qc.ReleaseAlert(ctx, event))
+	claimed, err = qc.ClaimAlert(ctx, event)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
@@ -2,20 +2,185 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
 	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
 	"github.com/JrMarcco/jotice/internal/repository/dao"
+	"gorm.io/gorm"
 )
 
 var _ CallbackLogRepo = (*DefaultCallbackLogRepo)(nil)
 
 type CallbackLogRepo interface {
+	BatchCreate(ctx context.Context, logs []domain.CallbackLog) error
+	BatchUpdate(ctx context.Context, logs []domain.CallbackLog) error
+	// ListPendingBatch lists pending callback logs which should be retried before startTime,
+	// and the init callback logs left behind by a crash before their first delivery.
+	// Returns the start id of the next batch, zero means there are no more callback logs.
+	ListPendingBatch(ctx context.Context, startTime int64, startId uint64, batchSize int) ([]domain.CallbackLog, uint64, error)
 	ListByNotificationIds(ctx context.Context, ids []uint64) ([]domain.CallbackLog, error)
+
+	// GetById returns errs.ErrCallbackLogNotFound if the callback log is not found.
+	GetById(ctx context.Context, id uint64) (domain.CallbackLog, error)
+	// ListFailed lists the failed callback logs of the business, which are failed in [startTime, endTime).
+	ListFailed(ctx context.Context, bizId uint64, startTime, endTime int64, offset, limit int) ([]domain.CallbackLog, error)
+	// Replay resets the failed callback logs to pending, returns the number of callback logs replayed.
+	Replay(ctx context.Context, ids []uint64) (int64, error)
+	// ReplayFailed resets all the failed callback logs of the business in [startTime, endTime) to pending.
+	ReplayFailed(ctx context.Context, bizId uint64, startTime, endTime int64) (int64, error)
 }
 
 type DefaultCallbackLogRepo struct {
 	dao dao.CallbackLogDAO
 }
 
+func (d *DefaultCallbackLogRepo) BatchCreate(ctx context.Context, logs []domain.CallbackLog) error {
+	entities, err := d.toEntities(logs)
+	if err != nil {
+		return err
+	}
+	return d.dao.BatchCreate(ctx, entities)
+}
+
+func (d *DefaultCallbackLogRepo) BatchUpdate(ctx context.Context, logs []domain.CallbackLog) error {
+	entities, err := d.toEntities(logs)
+	if err != nil {
+		return err
+	}
+	return d.dao.BatchUpdate(ctx, entities)
+}
+
+func (d *DefaultCallbackLogRepo) ListPendingBatch(
+	ctx context.Context, startTime int64, startId uint64, batchSize int,
+) ([]domain.CallbackLog, uint64, error) {
+	entities, nextStartId, err := d.dao.ListPendingBatch(ctx, startTime, startId, int32(batchSize))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	logs, err := d.toDomains(entities)
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, nextStartId, nil
+}
+
 func (d *DefaultCallbackLogRepo) ListByNotificationIds(ctx context.Context, ids []uint64) ([]domain.CallbackLog, error) {
-	panic("implement me")
+	entities, err := d.dao.ListByNotificationIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return d.toDomains(entities)
+}
+
+func (d *DefaultCallbackLogRepo) GetById(ctx context.Context, id uint64) (domain.CallbackLog, error) {
+	entity, err := d.dao.GetById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.CallbackLog{}, fmt.Errorf("%w: id = %d", errs.ErrCallbackLogNotFound, id)
+		}
+		return domain.CallbackLog{}, err
+	}
+	return d.toDomain(entity)
+}
+
+func (d *DefaultCallbackLogRepo) ListFailed(
+	ctx context.Context, bizId uint64, startTime, endTime int64, offset, limit int,
+) ([]domain.CallbackLog, error) {
+	entities, err := d.dao.ListFailed(ctx, bizId, startTime, endTime, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return d.toDomains(entities)
+}
+
+func (d *DefaultCallbackLogRepo) Replay(ctx context.Context, ids []uint64) (int64, error) {
+	return d.dao.Replay(ctx, ids)
+}
+
+func (d *DefaultCallbackLogRepo) ReplayFailed(ctx context.Context, bizId uint64, startTime, endTime int64) (int64, error) {
+	return d.dao.ReplayFailed(ctx, bizId, startTime, endTime)
+}
+
+func (d *DefaultCallbackLogRepo) toEntities(logs []domain.CallbackLog) ([]dao.CallbackLog, error) {
+	entities := make([]dao.CallbackLog, 0, len(logs))
+	for _, log := range logs {
+		entity, err := d.toEntity(log)
+		if err != nil {
+			return nil, err
+		}
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
+
+func (d *DefaultCallbackLogRepo) toEntity(log domain.CallbackLog) (dao.CallbackLog, error) {
+	attempts := "[]"
+	if len(log.Attempts) > 0 {
+		jsonBytes, err := json.Marshal(log.Attempts)
+		if err != nil {
+			return dao.CallbackLog{}, fmt.Errorf("failed to marshal callback attempts, cause of: %w", err)
+		}
+		attempts = string(jsonBytes)
+	}
+
+	return dao.CallbackLog{
+		Id:             log.Id,
+		NotificationId: log.Notification.Id,
+		BizId:          log.Notification.BizId,
+		BizKey:         log.Notification.BizKey,
+		RetryTimes:     log.RetryTimes,
+		NextRetryAt:    log.NextRetryAt,
+		Status:         log.Status.String(),
+		LastError:      log.LastError,
+		Attempts:       attempts,
+	}, nil
+}
+
+func (d *DefaultCallbackLogRepo) toDomains(entities []dao.CallbackLog) ([]domain.CallbackLog, error) {
+	logs := make([]domain.CallbackLog, 0, len(entities))
+	for _, entity := range entities {
+		log, err := d.toDomain(entity)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+// toDomain converts entity to domain model.
+// Only the identity fields of the notification are filled, the caller should load the full notification if needed.
+func (d *DefaultCallbackLogRepo) toDomain(entity dao.CallbackLog) (domain.CallbackLog, error) {
+	var attempts []domain.CallbackAttempt
+	if entity.Attempts != "" {
+		if err := json.Unmarshal([]byte(entity.Attempts), &attempts); err != nil {
+			return domain.CallbackLog{}, fmt.Errorf("failed to unmarshal callback attempts, cause of: %w", err)
+		}
+	}
+
+	return domain.CallbackLog{
+		Id: entity.Id,
+		Notification: domain.Notification{
+			Id:     entity.NotificationId,
+			BizId:  entity.BizId,
+			BizKey: entity.BizKey,
+		},
+		RetryTimes:  entity.RetryTimes,
+		NextRetryAt: entity.NextRetryAt,
+		Status:      domain.CallbackStatus(entity.Status),
+		LastError:   entity.LastError,
+		Attempts:    attempts,
+		CreateAt:    entity.CreatedAt,
+		UpdateAt:    entity.UpdatedAt,
+	}, nil
+}
+
+func NewDefaultCallbackLogRepo(dao dao.CallbackLogDAO) *DefaultCallbackLogRepo {
+	return &DefaultCallbackLogRepo{
+		dao: dao,
+	}
 }
@@ -0,0 +1,175 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	notifColumns = "id, biz_id, biz_key, receivers, channel, tpl_id, tpl_version_id, tpl_params, status, " +
+		"schedule_start, schedule_end, version, created_at, updated_at"
+	cbLogColumns = "id, notification_id, biz_id, biz_key, retry_times, next_retry_at, status, last_error, attempts, " +
+		"created_at, updated_at"
+)
+
+// terminalCbLogStatuses are the statuses of the callback logs done,
+// the notifications are archived only if their callback logs are done.
+var terminalCbLogStatuses = []string{"succeed", "failed"}
+
+// ArchiveTable returns the archive table of the sharding table in the same db,
+// e.g. the archive table of notification_3 is notification_archive_3.
+func ArchiveTable(dst sharding.Dst) string {
+	suffix := fmt.Sprintf("_%d", dst.TableSuffix)
+	return strings.TrimSuffix(dst.Table, suffix) + "_archive" + suffix
+}
+
+// Archive moves the notifications in statuses updated before the time to the archive tables in batches,
+// along with their callback logs. Each batch is moved in a local transaction of its db.
+// The notifications of which the callback logs are not done are left, and archived once they are done.
+// Returns the number of notifications archived.
+func (n *NotifShardingDAO) Archive(ctx context.Context, statuses []string, before int64, batchSize int) (int64, error) {
+	var total atomic.Int64
+
+	var eg errgroup.Group
+	for _, dst := range n.notifShardingStrategy.BroadCast() {
+		eg.Go(func() error {
+			cnt, err := n.archiveShard(ctx, dst, statuses, before, batchSize)
+			total.Add(cnt)
+			return err
+		})
+	}
+
+	err := eg.Wait()
+	return total.Load(), err
+}
+
+func (n *NotifShardingDAO) archiveShard(
+	ctx context.Context, dst sharding.Dst, statuses []string, before int64, batchSize int,
+) (int64, error) {
+	db, ok := n.dbs.Load(dst.DB)
+	if !ok {
+		return 0, fmt.Errorf("unknown db: %s", dst.DB)
+	}
+	db = db.WithContext(ctx)
+
+	var total int64
+	var startId uint64
+	for {
+		// the notifications left in a batch are skipped by the id, so the next batch moves forward.
+		var ids []uint64
+		err := db.Table(dst.Table).
+			Where("status IN ? AND updated_at < ? AND id > ?", statuses, before, startId).
+			Order("id ASC").
+			Limit(batchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return total, fmt.Errorf("failed to find notifications to archive in %s.%s, cause of: %w", dst.DB, dst.Table, err)
+		}
+
+		if len(ids) == 0 {
+			return total, nil
+		}
+
+		cnt, err := n.archiveBatch(db, dst, ids)
+		if err != nil {
+			return total, err
+		}
+		total += cnt
+
+		if len(ids) < batchSize {
+			return total, nil
+		}
+		startId = ids[len(ids)-1]
+	}
+}
+
+// archiveBatch archives the notifications of which the callback logs are done, returns the number archived.
+func (n *NotifShardingDAO) archiveBatch(db *gorm.DB, dst sharding.Dst, ids []uint64) (int64, error) {
+	// the callback logs are in the same db as their notifications.
+	cbDsts := make(map[sharding.Dst][]uint64)
+	for _, id := range ids {
+		cbDst := n.cbLogShardingStrategy.ShardWithId(id)
+		if cbDst.DB != dst.DB {
+			return 0, fmt.Errorf("callback log db %s is not the same as notification db %s", cbDst.DB, dst.DB)
+		}
+		cbDsts[cbDst] = append(cbDsts[cbDst], id)
+	}
+
+	var archived int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// lock the callback logs, so a log replayed during the move is not archived.
+		pending := make(map[uint64]struct{})
+		for cbDst, notifIds := range cbDsts {
+			var logs []CallbackLog
+			err := tx.Table(cbDst.Table).
+				Select("notification_id", "status").
+				Where("notification_id IN ?", notifIds).
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Find(&logs).Error
+			if err != nil {
+				return fmt.Errorf("failed to lock callback logs of %s.%s, cause of: %w", cbDst.DB, cbDst.Table, err)
+			}
+
+			for _, log := range logs {
+				if !slices.Contains(terminalCbLogStatuses, log.Status) {
+					pending[log.NotificationId] = struct{}{}
+				}
+			}
+		}
+
+		doneIds := slices.DeleteFunc(slices.Clone(ids), func(id uint64) bool {
+			_, ok := pending[id]
+			return ok
+		})
+		if len(doneIds) == 0 {
+			return nil
+		}
+
+		err := moveRows(tx, dst.Table, ArchiveTable(dst), notifColumns, "id IN ?", doneIds)
+		if err != nil {
+			return fmt.Errorf("failed to archive notifications of %s.%s, cause of: %w", dst.DB, dst.Table, err)
+		}
+
+		for cbDst, notifIds := range cbDsts {
+			notifIds = slices.DeleteFunc(notifIds, func(id uint64) bool {
+				_, ok := pending[id]
+				return ok
+			})
+			if len(notifIds) == 0 {
+				continue
+			}
+
+			err = moveRows(tx, cbDst.Table, ArchiveTable(cbDst), cbLogColumns, "notification_id IN ?", notifIds)
+			if err != nil {
+				return fmt.Errorf("failed to archive callback logs of %s.%s, cause of: %w", cbDst.DB, cbDst.Table, err)
+			}
+		}
+
+		archived = int64(len(doneIds))
+		return nil
+	})
+	return archived, err
+}
+
+// moveRows moves the rows matching the condition from the table to the archive table.
+// The rows already archived are skipped, in case of a retry after a partial failure.
+func moveRows(tx *gorm.DB, table, archiveTable, columns, cond string, args ...any) error {
+	err := tx.Exec(
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s WHERE %s ON CONFLICT DO NOTHING",
+			archiveTable, columns, columns, table, cond),
+		args...,
+	).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", table, cond), args...).Error
+}
@@ -0,0 +1,199 @@
+package dao
+
+import (
+	"database/sql/driver"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// idsOfDst generates n ids routed to the same dst, returns the dst and the ids ordered.
+func idsOfDst(t *testing.T, strategy sharding.Strategy, n int) (sharding.Dst, []uint64) {
+	g := snowflake.NewGenerator()
+
+	var dst sharding.Dst
+	ids := make([]uint64, 0, n)
+	for i := 0; len(ids) < n; i++ {
+		id, err := g.NextId(1, "biz_key_"+strconv.Itoa(i))
+		require.NoError(t, err)
+
+		if len(ids) == 0 {
+			dst = strategy.ShardWithId(id)
+		}
+		if strategy.ShardWithId(id) == dst {
+			ids = append(ids, id)
+		}
+	}
+
+	// the ids of the same millisecond are ordered by the hash
+	slices.Sort(ids)
+	return dst, ids
+}
+
+func TestNotifShardingDAO_Archive(t *testing.T) {
+	t.Parallel()
+
+	dao, fakes, _ := newTestNotifDAO(t)
+
+	// the first notification has its callback done, the second one has its callback pending,
+	// and the third one has no callback.
+	dst, ids := idsOfDst(t, sharding.NewHashStrategy("jotice", "notification", 2, 2), 3)
+	cbDst := sharding.NewHashStrategy("jotice", "callback_log", 2, 2).ShardWithId(ids[0])
+	cbStatuses := map[uint64]string{ids[0]: "succeed", ids[1]: "pending"}
+
+	fake := fakes[dst.DB]
+	fake.handle = func(query string, args []any) fakeResult {
+		switch {
+		case strings.HasPrefix(query, `SELECT "id" FROM "`+dst.Table+`"`):
+			// batches of 2 after the start id
+			startId := args[len(args)-2].(uint64)
+			res := fakeResult{columns: []string{"id"}}
+			for _, id := range ids {
+				if id > startId && len(res.rows) < 2 {
+					res.rows = append(res.rows, []driver.Value{int64(id)})
+				}
+			}
+			return res
+		case strings.HasPrefix(query, `SELECT "notification_id","status" FROM "`+cbDst.Table+`"`):
+			res := fakeResult{columns: []string{"notification_id", "status"}}
+			for _, arg := range args {
+				if status, ok := cbStatuses[arg.(uint64)]; ok {
+					res.rows = append(res.rows, []driver.Value{int64(arg.(uint64)), status})
+				}
+			}
+			return res
+		}
+		return fakeResult{}
+	}
+
+	archived, err := dao.Archive(t.Context(), []string{"succeeded", "failed"}, time.Now().UnixMilli(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), archived)
+
+	// the callback logs are locked before the move
+	locks := fake.queries(`FROM "` + cbDst.Table + `" WHERE notification_id IN`)
+	require.Len(t, locks, 2)
+	for _, lock := range locks {
+		assert.Contains(t, lock.query, "FOR UPDATE")
+	}
+
+	// the notification of the pending callback is left
+	notifMoves := fake.queries(`INSERT INTO ` + ArchiveTable(dst))
+	cbLogMoves := fake.queries(`INSERT INTO ` + ArchiveTable(cbDst))
+	require.Len(t, notifMoves, 2)
+	require.Len(t, cbLogMoves, 2)
+	assert.Equal(t, []any{ids[0]}, notifMoves[0].args)
+	assert.Equal(t, []any{ids[2]}, notifMoves[1].args)
+	assert.Equal(t, []any{ids[0]}, cbLogMoves[0].args)
+	assert.Equal(t, []any{ids[2]}, cbLogMoves[1].args)
+
+	// each batch is moved in a transaction with its locks
+	for i := range notifMoves {
+		assert.NotZero(t, notifMoves[i].tx)
+		assert.Equal(t, locks[i].tx, notifMoves[i].tx)
+		assert.Equal(t, notifMoves[i].tx, cbLogMoves[i].tx)
+		assert.Contains(t, fake.commit, notifMoves[i].tx)
+	}
+}
+
+func TestMoveRows(t *testing.T) {
+	t.Parallel()
+
+	dbs, fakes := newFakeDBs(t, "jotice_0")
+	db, _ := dbs.Load("jotice_0")
+
+	err := moveRows(db, "notification_1", "notification_archive_1", "id, status", "id IN ?", []uint64{1, 2})
+	require.NoError(t, err)
+
+	// the rows are copied without the archived ones, then deleted
+	stmts := fakes["jotice_0"].stmts
+	require.Len(t, stmts, 2)
+	assert.Equal(t, "INSERT INTO notification_archive_1 (id, status) SELECT id, status FROM notification_1 "+
+		"WHERE id IN ($1,$2) ON CONFLICT DO NOTHING", stmts[0].query)
+	assert.Equal(t, "DELETE FROM notification_1 WHERE id IN ($1,$2)", stmts[1].query)
+	assert.Equal(t, []any{uint64(1), uint64(2)}, stmts[1].args)
+}
+
+func TestCbLogShardingDAO_ListByNotificationIdsArchived(t *testing.T) {
+	t.Parallel()
+
+	dao, fakes := newTestCbLogDAO(t)
+
+	// the callback log of the first notification is archived
+	dst, ids := idsOfDst(t, sharding.NewHashStrategy("jotice", "callback_log", 2, 2), 2)
+	fake := fakes[dst.DB]
+	fake.handle = func(query string, args []any) fakeResult {
+		table := dst.Table
+		if strings.Contains(query, `FROM "`+ArchiveTable(dst)+`"`) {
+			table = ArchiveTable(dst)
+		} else if !strings.Contains(query, `FROM "`+dst.Table+`"`) {
+			return fakeResult{}
+		}
+
+		res := fakeResult{columns: []string{"id", "notification_id", "status"}}
+		for _, arg := range args {
+			archived := arg.(uint64) == ids[0]
+			if archived == (table != dst.Table) {
+				res.rows = append(res.rows, []driver.Value{int64(arg.(uint64)) + 1, int64(arg.(uint64)), "succeed"})
+			}
+		}
+		return res
+	}
+
+	logs, err := dao.ListByNotificationIds(t.Context(), ids)
+	require.NoError(t, err)
+
+	notifIds := make([]uint64, 0, len(logs))
+	for _, log := range logs {
+		notifIds = append(notifIds, log.NotificationId)
+	}
+	assert.ElementsMatch(t, ids, notifIds)
+
+	// only the notification not found is looked up in the archive table
+	archiveQueries := fake.queries(`FROM "` + ArchiveTable(dst) + `"`)
+	require.Len(t, archiveQueries, 1)
+	assert.Equal(t, []any{ids[0]}, archiveQueries[0].args)
+}
+
+func TestNotifShardingDAO_LocateById(t *testing.T) {
+	t.Parallel()
+
+	dao, fakes, _ := newTestNotifDAO(t)
+
+	// the first notification is archived, the second is not.
+	dst, ids := idsOfDst(t, sharding.NewHashStrategy("jotice", "notification", 2, 2), 3)
+	fakes[dst.DB].handle = func(query string, args []any) fakeResult {
+		table := dst.Table
+		if strings.Contains(query, `FROM "`+ArchiveTable(dst)+`"`) {
+			table = ArchiveTable(dst)
+		} else if !strings.Contains(query, `FROM "`+dst.Table+`"`) {
+			return fakeResult{}
+		}
+
+		id := args[0].(uint64)
+		if (id == ids[0]) != (table != dst.Table) || id == ids[2] {
+			return fakeResult{}
+		}
+		return fakeResult{columns: []string{"id", "status"}, rows: [][]driver.Value{{int64(id), "succeeded"}}}
+	}
+
+	archiveDst := dst
+	archiveDst.Table = ArchiveTable(dst)
+	for id, want := range map[uint64]sharding.Dst{ids[0]: archiveDst, ids[1]: dst} {
+		notif, src, err := dao.LocateById(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, id, notif.Id)
+		assert.Equal(t, want, src)
+	}
+
+	_, _, err := dao.LocateById(t.Context(), ids[2])
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -5,14 +5,19 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CallbackLog struct {
-	Id             uint64 `gorm:"column:id"`
+	Id             uint64 `gorm:"column:id;primaryKey;autoIncrement"`
 	NotificationId uint64 `gorm:"column:notification_id"`
+	BizId          uint64 `gorm:"column:biz_id"`
+	BizKey         string `gorm:"column:biz_key"`
 	RetryTimes     int32  `gorm:"column:retry_times"`
 	NextRetryAt    int64  `gorm:"column:next_retry_at"`
 	Status         string `gorm:"column:status"`
+	LastError      string `gorm:"column:last_error"`
+	Attempts       string `gorm:"column:attempts"`
 	CreatedAt      int64  `gorm:"column:created_at"`
 	UpdatedAt      int64  `gorm:"column:updated_at"`
 }
@@ -21,18 +26,60 @@ func (c CallbackLog) TableName() string {
 	return "callback_log"
 }
 
+// staleInitCallbackAge is how long a callback log can stay in init status before the retry task takes it over.
+// The init log is updated right after its first delivery,
+// so a stale one is left by a crash between creating and updating it.
+const staleInitCallbackAge = time.Minute
+
+// pendingScope selects the pending callback logs which should be retried before startTime,
+// and the stale init callback logs.
+func pendingScope(startTime int64) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where(
+			"(status = ? AND next_retry_at <= ?) OR (status = ? AND updated_at <= ?)",
+			"pending", startTime, "init", startTime-staleInitCallbackAge.Milliseconds(),
+		)
+	}
+}
+
 var _ CallbackLogDAO = (*DefaultCallbackLogDAO)(nil)
 
 type CallbackLogDAO interface {
+	BatchCreate(ctx context.Context, logs []CallbackLog) error
 	BatchUpdate(ctx context.Context, logs []CallbackLog) error
 	ListPendingBatch(ctx context.Context, startTime int64, startId uint64, batchSize int32) ([]CallbackLog, uint64, error)
 	ListByNotificationIds(ctx context.Context, ids []uint64) ([]CallbackLog, error)
+
+	GetById(ctx context.Context, id uint64) (CallbackLog, error)
+	ListFailed(ctx context.Context, bizId uint64, startTime, endTime int64, offset, limit int) ([]CallbackLog, error)
+	Replay(ctx context.Context, ids []uint64) (int64, error)
+	ReplayFailed(ctx context.Context, bizId uint64, startTime, endTime int64) (int64, error)
 }
 
 type DefaultCallbackLogDAO struct {
 	db *gorm.DB
 }
 
+// BatchCreate creates callback logs, logs of the notification already exists will be ignored.
+func (d *DefaultCallbackLogDAO) BatchCreate(ctx context.Context, logs []CallbackLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	for i := range logs {
+		logs[i].CreatedAt = now
+		logs[i].UpdatedAt = now
+	}
+
+	return d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "notification_id"}},
+			DoNothing: true,
+		}).
+		Create(&logs).Error
+}
+
 func (d *DefaultCallbackLogDAO) BatchUpdate(ctx context.Context, logs []CallbackLog) error {
 	if len(logs) == 0 {
 		return nil
@@ -46,6 +93,8 @@ func (d *DefaultCallbackLogDAO) BatchUpdate(ctx context.Context, logs []Callback
 					"retry_times":   log.RetryTimes,
 					"next_retry_at": log.NextRetryAt,
 					"status":        log.Status,
+					"last_error":    log.LastError,
+					"attempts":      log.Attempts,
 					"updated_at":    updateAt,
 				})
 
@@ -62,24 +111,24 @@ func (d *DefaultCallbackLogDAO) ListPendingBatch(
 ) ([]CallbackLog, uint64, error) {
 	var logs []CallbackLog
 
-	nextStartId := 0
+	// keyset pagination, so the logs must be ordered by id
+	var nextStartId uint64
 	res := d.db.WithContext(ctx).Model(&CallbackLog{}).
-		Where("status = ?", "pending").
-		Where("next_retry_at <= ?", startTime).
+		Scopes(pendingScope(startTime)).
 		Where("id > ?", startId).
-		Order("create_at ASC").
+		Order("id ASC").
 		Limit(int(batchSize)).
 		Find(&logs)
 
 	if res.Error != nil {
-		return nil, uint64(nextStartId), res.Error
+		return nil, nextStartId, res.Error
 	}
 
 	if len(logs) > 0 {
-		nextStartId = int(logs[len(logs)-1].Id)
+		nextStartId = logs[len(logs)-1].Id
 	}
 
-	return logs, uint64(nextStartId), nil
+	return logs, nextStartId, nil
 }
 
 func (d *DefaultCallbackLogDAO) ListByNotificationIds(ctx context.Context, ids []uint64) ([]CallbackLog, error) {
@@ -88,6 +137,60 @@ func (d *DefaultCallbackLogDAO) ListByNotificationIds(ctx context.Context, ids [
 	return logs, err
 }
 
+func (d *DefaultCallbackLogDAO) GetById(ctx context.Context, id uint64) (CallbackLog, error) {
+	var log CallbackLog
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(&log).Error
+	return log, err
+}
+
+// ListFailed lists the failed callback logs of the business, which are failed in [startTime, endTime).
+func (d *DefaultCallbackLogDAO) ListFailed(
+	ctx context.Context, bizId uint64, startTime, endTime int64, offset, limit int,
+) ([]CallbackLog, error) {
+	var logs []CallbackLog
+	err := d.db.WithContext(ctx).
+		Where("biz_id = ? AND status = ?", bizId, "failed").
+		Where("updated_at >= ? AND updated_at < ?", startTime, endTime).
+		Order("updated_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
+
+// Replay resets the failed callback logs to pending with zero retry times,
+// so that they will be delivered by the retry task again.
+// Returns the number of callback logs replayed.
+func (d *DefaultCallbackLogDAO) Replay(ctx context.Context, ids []uint64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	res := d.db.WithContext(ctx).Model(&CallbackLog{}).
+		Where("id IN ? AND status = ?", ids, "failed").
+		Updates(d.replayColumns())
+	return res.RowsAffected, res.Error
+}
+
+// ReplayFailed replays all the failed callback logs of the business, which are failed in [startTime, endTime).
+func (d *DefaultCallbackLogDAO) ReplayFailed(ctx context.Context, bizId uint64, startTime, endTime int64) (int64, error) {
+	res := d.db.WithContext(ctx).Model(&CallbackLog{}).
+		Where("biz_id = ? AND status = ?", bizId, "failed").
+		Where("updated_at >= ? AND updated_at < ?", startTime, endTime).
+		Updates(d.replayColumns())
+	return res.RowsAffected, res.Error
+}
+
+func (d *DefaultCallbackLogDAO) replayColumns() map[string]any {
+	now := time.Now().UnixMilli()
+	return map[string]any{
+		"retry_times":   0,
+		"next_retry_at": now,
+		"status":        "pending",
+		"updated_at":    now,
+	}
+}
+
 func NewDefaultCallbackLogDAO(db *gorm.DB) *DefaultCallbackLogDAO {
 	return &DefaultCallbackLogDAO{
 		db: db,
@@ -0,0 +1,455 @@
+package dao
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/JrMarcco/easy-kit/xsync"
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"github.com/JrMarcco/jotice/internal/pkg/sharding/migration"
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var _ CallbackLogDAO = (*CbLogShardingDAO)(nil)
+
+// CbLogShardingDAO is the sharded implementation of CallbackLogDAO.
+// The callback log is sharded by the biz id and biz key of its notification,
+// and its id is generated by the snowflake generator, so it can be routed by the id.
+//
+// The callback logs are resharded with the notifications, so they stay in the same db,
+// the writes are double written to the new layout if the sharding strategy is in resharding.
+type CbLogShardingDAO struct {
+	dbs *xsync.Map[string, *gorm.DB]
+
+	shardingStrategy sharding.Strategy
+	idGenerator      *snowflake.Generator
+	logger           *zap.Logger
+}
+
+// BatchCreate creates callback logs, logs of the notification already exists will be ignored.
+func (c *CbLogShardingDAO) BatchCreate(ctx context.Context, logs []CallbackLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	groups := make(map[sharding.Dst][]CallbackLog)
+	for _, log := range logs {
+		id, err := c.idGenerator.NextId(log.BizId, log.BizKey)
+		if err != nil {
+			return err
+		}
+		log.Id = id
+		log.CreatedAt = now
+		log.UpdatedAt = now
+
+		dst := c.shardingStrategy.ShardWithId(log.Id)
+		groups[dst] = append(groups[dst], log)
+	}
+
+	_, resharding := c.shardingStrategy.(migration.DoubleWriter)
+	return c.execByGroup(ctx, groups, func(db *gorm.DB, table string, logs []CallbackLog) error {
+		err := db.Table(table).
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "notification_id"}},
+				DoNothing: true,
+			}).
+			Create(&logs).Error
+		if err != nil || !resharding {
+			return err
+		}
+
+		// only the logs created are double written, the ignored ones conflict with the logs copied by the migrator.
+		ids := make([]uint64, 0, len(logs))
+		for _, log := range logs {
+			ids = append(ids, log.Id)
+		}
+		var created []uint64
+		if err = db.Table(table).Where("id IN ?", ids).Pluck("id", &created).Error; err != nil {
+			return err
+		}
+		logs = slices.DeleteFunc(logs, func(log CallbackLog) bool {
+			return !slices.Contains(created, log.Id)
+		})
+
+		doubleWrite(ctx, c.dbs, c.shardingStrategy, c.logger, "callback logs", logs, logId, insertIgnored[CallbackLog])
+		return nil
+	})
+}
+
+func (c *CbLogShardingDAO) BatchUpdate(ctx context.Context, logs []CallbackLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	groups := make(map[sharding.Dst][]CallbackLog)
+	for _, log := range logs {
+		dst := c.shardingStrategy.ShardWithId(log.Id)
+		groups[dst] = append(groups[dst], log)
+	}
+
+	updateAt := time.Now().UnixMilli()
+	update := func(db *gorm.DB, table string, logs []CallbackLog) error {
+		return updateLogs(db, table, logs, updateAt)
+	}
+	return c.execByGroup(ctx, groups, func(db *gorm.DB, table string, logs []CallbackLog) error {
+		if err := update(db, table, logs); err != nil {
+			return err
+		}
+
+		doubleWrite(ctx, c.dbs, c.shardingStrategy, c.logger, "callback log updates", logs, logId, update)
+		return nil
+	})
+}
+
+func updateLogs(db *gorm.DB, table string, logs []CallbackLog, updateAt int64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, log := range logs {
+			res := tx.Table(table).
+				Where("id = ?", log.Id).
+				Updates(map[string]any{
+					"retry_times":   log.RetryTimes,
+					"next_retry_at": log.NextRetryAt,
+					"status":        log.Status,
+					"last_error":    log.LastError,
+					"attempts":      log.Attempts,
+					"updated_at":    updateAt,
+				})
+
+			if res.Error != nil {
+				return res.Error
+			}
+		}
+		return nil
+	})
+}
+
+// ListPendingBatch lists the pending and stale init callback logs of all the shards.
+// Each shard returns at most batchSize logs after startId,
+// the merged logs are ordered by id and the first batchSize of them are returned,
+// so the keyset pagination still works across the shards.
+// Only the recent shards of a sharding.RangeStrategy are scanned.
+func (c *CbLogShardingDAO) ListPendingBatch(
+	ctx context.Context, startTime int64, startId uint64, batchSize int32,
+) ([]CallbackLog, uint64, error) {
+	logs, err := c.broadcastFind(ctx, sharding.BroadCastRecent(c.shardingStrategy), func(tx *gorm.DB) *gorm.DB {
+		return tx.Scopes(pendingScope(startTime)).
+			Where("id > ?", startId).
+			Order("id ASC").
+			Limit(int(batchSize))
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	slices.SortFunc(logs, func(a, b CallbackLog) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+	if len(logs) > int(batchSize) {
+		logs = logs[:batchSize]
+	}
+
+	var nextStartId uint64
+	if len(logs) > 0 {
+		nextStartId = logs[len(logs)-1].Id
+	}
+	return logs, nextStartId, nil
+}
+
+// ListByNotificationIds falls back to the archive tables for the notifications not found.
+func (c *CbLogShardingDAO) ListByNotificationIds(ctx context.Context, ids []uint64) ([]CallbackLog, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// the callback log is in the same shard as its notification.
+	groups := make(map[sharding.Dst][]uint64)
+	for _, id := range ids {
+		dst := c.shardingStrategy.ShardWithId(id)
+		groups[dst] = append(groups[dst], id)
+	}
+
+	var mu sync.Mutex
+	res := make([]CallbackLog, 0, len(ids))
+
+	var eg errgroup.Group
+	for dst, notifIds := range groups {
+		eg.Go(func() error {
+			db, err := c.getDB(dst)
+			if err != nil {
+				return err
+			}
+
+			var logs []CallbackLog
+			if err = db.WithContext(ctx).Table(dst.Table).
+				Where("notification_id IN ?", notifIds).
+				Find(&logs).Error; err != nil {
+				return err
+			}
+
+			// archived with their notifications
+			found := make(map[uint64]struct{}, len(logs))
+			for _, log := range logs {
+				found[log.NotificationId] = struct{}{}
+			}
+			archivedIds := slices.DeleteFunc(notifIds, func(id uint64) bool {
+				_, ok := found[id]
+				return ok
+			})
+
+			if len(archivedIds) > 0 {
+				var archived []CallbackLog
+				if err = db.WithContext(ctx).Table(ArchiveTable(dst)).
+					Where("notification_id IN ?", archivedIds).
+					Find(&archived).Error; err != nil {
+					return err
+				}
+				logs = append(logs, archived...)
+			}
+
+			mu.Lock()
+			res = append(res, logs...)
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *CbLogShardingDAO) GetById(ctx context.Context, id uint64) (CallbackLog, error) {
+	dst := c.shardingStrategy.ShardWithId(id)
+	db, err := c.getDB(dst)
+	if err != nil {
+		return CallbackLog{}, err
+	}
+
+	var log CallbackLog
+	err = db.WithContext(ctx).Table(dst.Table).Where("id = ?", id).First(&log).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// archived with its notification
+		err = db.WithContext(ctx).Table(ArchiveTable(dst)).Where("id = ?", id).First(&log).Error
+	}
+	return log, err
+}
+
+// ListFailed lists the failed callback logs of the business, which are failed in [startTime, endTime).
+// Each shard returns its first offset+limit logs, then the merged logs are paged.
+func (c *CbLogShardingDAO) ListFailed(
+	ctx context.Context, bizId uint64, startTime, endTime int64, offset, limit int,
+) ([]CallbackLog, error) {
+	logs, err := c.broadcastFind(ctx, c.shardingStrategy.BroadCast(), func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("biz_id = ? AND status = ?", bizId, "failed").
+			Where("updated_at >= ? AND updated_at < ?", startTime, endTime).
+			Order("updated_at DESC").
+			Limit(offset + limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(logs, func(a, b CallbackLog) int {
+		if a.UpdatedAt != b.UpdatedAt {
+			return cmp.Compare(b.UpdatedAt, a.UpdatedAt)
+		}
+		return cmp.Compare(b.Id, a.Id)
+	})
+
+	if offset >= len(logs) {
+		return []CallbackLog{}, nil
+	}
+	return logs[offset:min(offset+limit, len(logs))], nil
+}
+
+func (c *CbLogShardingDAO) Replay(ctx context.Context, ids []uint64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	groups := make(map[sharding.Dst][]uint64)
+	for _, id := range ids {
+		dst := c.shardingStrategy.ShardWithId(id)
+		groups[dst] = append(groups[dst], id)
+	}
+
+	var mu sync.Mutex
+	var total int64
+
+	columns := c.replayColumns()
+	var eg errgroup.Group
+	for dst, logIds := range groups {
+		eg.Go(func() error {
+			replayed, err := c.replayIn(ctx, dst, logIds, columns)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			total += replayed
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	err := eg.Wait()
+	return total, err
+}
+
+// ReplayFailed replays the failed logs of the biz found in each shard by their ids, so they can be double written.
+func (c *CbLogShardingDAO) ReplayFailed(ctx context.Context, bizId uint64, startTime, endTime int64) (int64, error) {
+	var mu sync.Mutex
+	var total int64
+
+	columns := c.replayColumns()
+	var eg errgroup.Group
+	for _, dst := range c.shardingStrategy.BroadCast() {
+		eg.Go(func() error {
+			db, err := c.getDB(dst)
+			if err != nil {
+				return err
+			}
+
+			var ids []uint64
+			err = db.WithContext(ctx).Table(dst.Table).
+				Where("biz_id = ? AND status = ?", bizId, "failed").
+				Where("updated_at >= ? AND updated_at < ?", startTime, endTime).
+				Pluck("id", &ids).Error
+			if err != nil || len(ids) == 0 {
+				return err
+			}
+
+			replayed, err := c.replayIn(ctx, dst, ids, columns)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			total += replayed
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	err := eg.Wait()
+	return total, err
+}
+
+// replayIn replays the failed logs of the ids in the shard, returns the number replayed.
+func (c *CbLogShardingDAO) replayIn(
+	ctx context.Context, dst sharding.Dst, ids []uint64, columns map[string]any,
+) (int64, error) {
+	db, err := c.getDB(dst)
+	if err != nil {
+		return 0, err
+	}
+
+	var replayed int64
+	replay := func(db *gorm.DB, table string, ids []uint64) error {
+		res := db.Table(table).Where("id IN ? AND status = ?", ids, "failed").Updates(columns)
+		replayed = res.RowsAffected
+		return res.Error
+	}
+	if err = replay(db.WithContext(ctx), dst.Table, ids); err != nil {
+		return 0, err
+	}
+	total := replayed
+
+	doubleWrite(ctx, c.dbs, c.shardingStrategy, c.logger, "callback log replays", ids, identity, replay)
+	return total, nil
+}
+
+// broadcastFind runs the query on the shards in parallel and merges the results.
+func (c *CbLogShardingDAO) broadcastFind(
+	ctx context.Context, dsts []sharding.Dst, query func(tx *gorm.DB) *gorm.DB,
+) ([]CallbackLog, error) {
+	var mu sync.Mutex
+	var res []CallbackLog
+
+	var eg errgroup.Group
+	for _, dst := range dsts {
+		eg.Go(func() error {
+			db, err := c.getDB(dst)
+			if err != nil {
+				return err
+			}
+
+			var logs []CallbackLog
+			if err = query(db.WithContext(ctx).Table(dst.Table)).Find(&logs).Error; err != nil {
+				return err
+			}
+
+			mu.Lock()
+			res = append(res, logs...)
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// execByGroup runs fn for each group of callback logs in parallel.
+func (c *CbLogShardingDAO) execByGroup(
+	ctx context.Context, groups map[sharding.Dst][]CallbackLog, fn func(db *gorm.DB, table string, logs []CallbackLog) error,
+) error {
+	var eg errgroup.Group
+	for dst, logs := range groups {
+		eg.Go(func() error {
+			db, err := c.getDB(dst)
+			if err != nil {
+				return err
+			}
+			return fn(db.WithContext(ctx), dst.Table, logs)
+		})
+	}
+	return eg.Wait()
+}
+
+func logId(log CallbackLog) uint64 {
+	return log.Id
+}
+
+func (c *CbLogShardingDAO) getDB(dst sharding.Dst) (*gorm.DB, error) {
+	db, ok := c.dbs.Load(dst.DB)
+	if !ok {
+		return nil, fmt.Errorf("unknown db: %s", dst.DB)
+	}
+	return db, nil
+}
+
+func (c *CbLogShardingDAO) replayColumns() map[string]any {
+	now := time.Now().UnixMilli()
+	return map[string]any{
+		"retry_times":   0,
+		"next_retry_at": now,
+		"status":        "pending",
+		"updated_at":    now,
+	}
+}
+
+func NewCbLogShardingDAO(
+	dbs *xsync.Map[string, *gorm.DB], shardingStrategy sharding.Strategy, idGenerator *snowflake.Generator,
+	logger *zap.Logger,
+) *CbLogShardingDAO {
+	return &CbLogShardingDAO{
+		dbs:              dbs,
+		shardingStrategy: shardingStrategy,
+		idGenerator:      idGenerator,
+		logger:           logger,
+	}
+}
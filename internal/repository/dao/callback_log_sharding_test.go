@@ -0,0 +1,191 @@
+package dao
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestCbLogDAO(t *testing.T) (*CbLogShardingDAO, map[string]*fakeDB) {
+	dbs, fakes := newFakeDBs(t, "jotice_0", "jotice_1")
+	return NewCbLogShardingDAO(
+		dbs, sharding.NewHashStrategy("jotice", "callback_log", 2, 2), snowflake.NewGenerator(), zap.NewNop(),
+	), fakes
+}
+
+func TestCbLogShardingDAO_BatchCreate(t *testing.T) {
+	t.Parallel()
+
+	dao, fakes := newTestCbLogDAO(t)
+
+	logs := []CallbackLog{
+		{NotificationId: 1, BizId: 1, BizKey: "a", Status: "init", Attempts: "[]"},
+		{NotificationId: 2, BizId: 1, BizKey: "b", Status: "init", Attempts: "[]"},
+		{NotificationId: 3, BizId: 2, BizKey: "c", Status: "init", Attempts: "[]"},
+	}
+	require.NoError(t, dao.BatchCreate(t.Context(), logs))
+
+	// each log is created in the shard of its biz key, the duplicate log of a notification is ignored
+	strategy := sharding.NewHashStrategy("jotice", "callback_log", 2, 2)
+	for _, log := range logs {
+		dst := strategy.Shard(log.BizId, log.BizKey)
+
+		var found bool
+		for _, stmt := range fakes[dst.DB].queries(`INSERT INTO "` + dst.Table + `"`) {
+			assert.Contains(t, stmt.query, `ON CONFLICT ("notification_id") DO NOTHING`)
+			for _, arg := range stmt.args {
+				found = found || arg == log.BizKey
+			}
+		}
+		assert.True(t, found, "callback log of %s is not created in %s.%s", log.BizKey, dst.DB, dst.Table)
+	}
+}
+
+func TestCbLogShardingDAO_ListPendingBatch(t *testing.T) {
+	t.Parallel()
+
+	dao, fakes := newTestCbLogDAO(t)
+
+	// each shard returns its pending logs after the start id
+	shardIds := map[string][]int64{
+		`"callback_log_0"`: {11, 15},
+		`"callback_log_1"`: {12, 18},
+	}
+	for name, fake := range fakes {
+		fake.handle = func(query string, _ []any) fakeResult {
+			for table, ids := range shardIds {
+				if !strings.Contains(query, "FROM "+table) {
+					continue
+				}
+
+				res := fakeResult{columns: []string{"id", "status"}}
+				for _, id := range ids {
+					// the ids of db 1 are shifted, so all the shards return different logs
+					if name == "jotice_1" {
+						id += 10
+					}
+					res.rows = append(res.rows, []driver.Value{id, "pending"})
+				}
+				return res
+			}
+			return fakeResult{}
+		}
+	}
+
+	logs, nextStartId, err := dao.ListPendingBatch(t.Context(), time.Now().UnixMilli(), 10, 3)
+	require.NoError(t, err)
+
+	// the merged logs are ordered by id and truncated to the batch size
+	ids := make([]uint64, 0, len(logs))
+	for _, log := range logs {
+		ids = append(ids, log.Id)
+	}
+	assert.Equal(t, []uint64{11, 12, 15}, ids)
+	assert.Equal(t, uint64(15), nextStartId)
+
+	// all the shards are queried with the stale init logs included
+	for _, fake := range fakes {
+		stmts := fake.queries("SELECT")
+		require.Len(t, stmts, 2)
+		assert.Contains(t, stmts[0].query, "((status = $2 AND next_retry_at <= $3) OR (status = $4 AND updated_at <= $5))")
+	}
+}
+
+func TestCbLogShardingDAO_Replay(t *testing.T) {
+	t.Parallel()
+
+	dao, fakes := newTestCbLogDAO(t)
+	for _, fake := range fakes {
+		fake.handle = func(string, []any) fakeResult {
+			return fakeResult{affected: 1}
+		}
+	}
+
+	generator := snowflake.NewGenerator()
+	ids := make([]uint64, 0, 4)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		id, err := generator.NextId(1, key)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	// each shard of the ids is updated once
+	strategy := sharding.NewHashStrategy("jotice", "callback_log", 2, 2)
+	shards := make(map[sharding.Dst]struct{})
+	for _, id := range ids {
+		shards[strategy.ShardWithId(id)] = struct{}{}
+	}
+
+	replayed, err := dao.Replay(t.Context(), ids)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(shards)), replayed)
+
+	for dst := range shards {
+		stmts := fakes[dst.DB].queries(`UPDATE "` + dst.Table + `"`)
+		require.Len(t, stmts, 1)
+		assert.Contains(t, stmts[0].query, "status = $")
+	}
+}
+
+func TestCbLogShardingDAO_DoubleWrite(t *testing.T) {
+	t.Parallel()
+
+	dbs, fakes := newFakeDBs(t, "jotice_0", "jotice_1")
+	strategy := doubleWriteStrategy{
+		HashStrategy: sharding.NewHashStrategy("jotice", "callback_log", 2, 2),
+		db:           "jotice_1",
+		table:        "callback_log_v2",
+	}
+	dao := NewCbLogShardingDAO(dbs, strategy, snowflake.NewGenerator(), zap.NewNop())
+
+	// the log of "a" is created, the one of "b" is ignored for its notification has a log already
+	for _, fake := range fakes {
+		fake.handle = func(query string, args []any) fakeResult {
+			if strings.HasPrefix(query, `SELECT "id" FROM "callback_log_`) {
+				res := fakeResult{columns: []string{"id"}}
+				if containsArg(fakes["jotice_0"].argsOf(`INSERT INTO "callback_log_`, "a"), args[0]) ||
+					containsArg(fakes["jotice_1"].argsOf(`INSERT INTO "callback_log_`, "a"), args[0]) {
+					res.rows = append(res.rows, []driver.Value{args[0]})
+				}
+				return res
+			}
+			return fakeResult{affected: 1}
+		}
+	}
+
+	require.NoError(t, dao.BatchCreate(t.Context(), []CallbackLog{
+		{NotificationId: 1, BizId: 1, BizKey: "a", Status: "init", Attempts: "[]"},
+	}))
+	require.NoError(t, dao.BatchCreate(t.Context(), []CallbackLog{
+		{NotificationId: 2, BizId: 1, BizKey: "b", Status: "init", Attempts: "[]"},
+	}))
+
+	inserts := fakes["jotice_1"].queries(`INSERT INTO "callback_log_v2"`)
+	require.Len(t, inserts, 1)
+	assert.Contains(t, inserts[0].args, "a")
+	assert.Contains(t, inserts[0].query, "ON CONFLICT DO NOTHING")
+
+	// the updates and replays are written with the same columns
+	id, err := snowflake.NewGenerator().NextId(1, "c")
+	require.NoError(t, err)
+	require.NoError(t, dao.BatchUpdate(t.Context(), []CallbackLog{{Id: id, Status: "failed", Attempts: "[]"}}))
+	replayed, err := dao.Replay(t.Context(), []uint64{id})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), replayed)
+
+	dst := strategy.ShardWithId(id)
+	updates := fakes[dst.DB].queries(`UPDATE "` + dst.Table + `"`)
+	v2Updates := fakes["jotice_1"].queries(`UPDATE "callback_log_v2"`)
+	require.Len(t, updates, 2)
+	require.Len(t, v2Updates, 2)
+	for i := range updates {
+		assert.Equal(t, updates[i].args, v2Updates[i].args)
+	}
+}
@@ -1,5 +1,157 @@
 package dao
 
-type ConfigDAO struct{}
+import (
+	"context"
+	"database/sql"
+	"time"
 
-type DefaultBizConfigDAO struct{}
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BizConfig entity definition.
+// The sub-configs are stored as json, null means the sub-config is not set.
+type BizConfig struct {
+	Id             uint64         `gorm:"column:id;primaryKey"`
+	OwnerId        uint64         `gorm:"column:owner_id"`
+	OwnerType      string         `gorm:"column:owner_type"`
+	ChannelConfig  sql.NullString `gorm:"column:channel_config"`
+	TxNotifConfig  sql.NullString `gorm:"column:tx_notif_config"`
+	RateLimit      int32          `gorm:"column:rate_limit"`
+	QuotaConfig    sql.NullString `gorm:"column:quota_config"`
+	CallbackConfig sql.NullString `gorm:"column:callback_config"`
+	CreatedAt      int64          `gorm:"column:created_at"`
+	UpdatedAt      int64          `gorm:"column:updated_at"`
+}
+
+func (b BizConfig) TableName() string {
+	return "biz_config"
+}
+
+// BizConfigRevision entity definition.
+// The config columns are the same as BizConfig.
+type BizConfigRevision struct {
+	Id             uint64         `gorm:"column:id;primaryKey;autoIncrement"`
+	BizId          uint64         `gorm:"column:biz_id"`
+	Version        int32          `gorm:"column:version"`
+	OwnerId        uint64         `gorm:"column:owner_id"`
+	OwnerType      string         `gorm:"column:owner_type"`
+	ChannelConfig  sql.NullString `gorm:"column:channel_config"`
+	TxNotifConfig  sql.NullString `gorm:"column:tx_notif_config"`
+	RateLimit      int32          `gorm:"column:rate_limit"`
+	QuotaConfig    sql.NullString `gorm:"column:quota_config"`
+	CallbackConfig sql.NullString `gorm:"column:callback_config"`
+	Author         string         `gorm:"column:author"`
+	CreatedAt      int64          `gorm:"column:created_at"`
+}
+
+func (b BizConfigRevision) TableName() string {
+	return "biz_config_revision"
+}
+
+type BizConfigDAO interface {
+	GetById(ctx context.Context, id uint64) (BizConfig, error)
+	// Save saves the biz config and creates a new revision of it in the same transaction.
+	Save(ctx context.Context, bizConfig BizConfig, author string) (BizConfigRevision, error)
+	Delete(ctx context.Context, id uint64) error
+
+	// ListRevisions lists the revisions of the biz config, the latest first.
+	ListRevisions(ctx context.Context, bizId uint64, offset, limit int) ([]BizConfigRevision, error)
+	GetRevision(ctx context.Context, bizId uint64, version int32) (BizConfigRevision, error)
+}
+
+var _ BizConfigDAO = (*DefaultBizConfigDAO)(nil)
+
+type DefaultBizConfigDAO struct {
+	db *gorm.DB
+}
+
+func (d *DefaultBizConfigDAO) GetById(ctx context.Context, id uint64) (BizConfig, error) {
+	var bizConfig BizConfig
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(&bizConfig).Error
+	return bizConfig, err
+}
+
+// Save creates the biz config or updates it if the biz config already exists,
+// then creates the next revision of it.
+// The upsert locks the biz config row, so the concurrent saves of the same biz get sequential versions.
+func (d *DefaultBizConfigDAO) Save(ctx context.Context, bizConfig BizConfig, author string) (BizConfigRevision, error) {
+	now := time.Now().UnixMilli()
+	bizConfig.CreatedAt = now
+	bizConfig.UpdatedAt = now
+
+	revision := BizConfigRevision{
+		BizId:          bizConfig.Id,
+		OwnerId:        bizConfig.OwnerId,
+		OwnerType:      bizConfig.OwnerType,
+		ChannelConfig:  bizConfig.ChannelConfig,
+		TxNotifConfig:  bizConfig.TxNotifConfig,
+		RateLimit:      bizConfig.RateLimit,
+		QuotaConfig:    bizConfig.QuotaConfig,
+		CallbackConfig: bizConfig.CallbackConfig,
+		Author:         author,
+		CreatedAt:      now,
+	}
+
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := d.upsert(tx, bizConfig); err != nil {
+			return err
+		}
+
+		var latest int32
+		if err := tx.Model(&BizConfigRevision{}).
+			Select("COALESCE(MAX(version), 0)").
+			Where("biz_id = ?", bizConfig.Id).
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+
+		revision.Version = latest + 1
+		return tx.Create(&revision).Error
+	})
+	return revision, err
+}
+
+func (d *DefaultBizConfigDAO) upsert(tx *gorm.DB, bizConfig BizConfig) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"owner_id",
+			"owner_type",
+			"channel_config",
+			"tx_notif_config",
+			"rate_limit",
+			"quota_config",
+			"callback_config",
+			"updated_at",
+		}),
+	}).Create(&bizConfig).Error
+}
+
+// Delete deletes the biz config, the revisions are kept for rollback.
+func (d *DefaultBizConfigDAO) Delete(ctx context.Context, id uint64) error {
+	return d.db.WithContext(ctx).Where("id = ?", id).Delete(&BizConfig{}).Error
+}
+
+func (d *DefaultBizConfigDAO) ListRevisions(ctx context.Context, bizId uint64, offset, limit int) ([]BizConfigRevision, error) {
+	var revisions []BizConfigRevision
+	err := d.db.WithContext(ctx).
+		Where("biz_id = ?", bizId).
+		Order("version DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&revisions).Error
+	return revisions, err
+}
+
+func (d *DefaultBizConfigDAO) GetRevision(ctx context.Context, bizId uint64, version int32) (BizConfigRevision, error) {
+	var revision BizConfigRevision
+	err := d.db.WithContext(ctx).Where("biz_id = ? AND version = ?", bizId, version).First(&revision).Error
+	return revision, err
+}
+
+func NewDefaultBizConfigDAO(db *gorm.DB) *DefaultBizConfigDAO {
+	return &DefaultBizConfigDAO{
+		db: db,
+	}
+}
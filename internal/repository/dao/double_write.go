@@ -0,0 +1,60 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/JrMarcco/easy-kit/xsync"
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"github.com/JrMarcco/jotice/internal/pkg/sharding/migration"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// doubleWrite writes the rows to the new layout too, if the sharding strategy is in resharding.
+// The rows have been committed to the old layout, so the failures are logged only,
+// the rows missed here are copied by the migrator, and detected by its verification.
+//
+// The updates should be written with the same updated_at as the old layout, which the verification compares.
+func doubleWrite[T any](
+	ctx context.Context, dbs *xsync.Map[string, *gorm.DB], strategy sharding.Strategy, logger *zap.Logger,
+	name string, rows []T, idOf func(T) uint64, write func(db *gorm.DB, table string, rows []T) error,
+) {
+	dw, ok := strategy.(migration.DoubleWriter)
+	if !ok {
+		return
+	}
+
+	groups := make(map[sharding.Dst][]T)
+	for _, row := range rows {
+		if dst, ok := dw.DoubleWriteDst(idOf(row)); ok {
+			groups[dst] = append(groups[dst], row)
+		}
+	}
+
+	for dst, group := range groups {
+		db, ok := dbs.Load(dst.DB)
+		if !ok {
+			logger.Error("[jotice] failed to double write "+name+" to unknown db", zap.String("db", dst.DB))
+			continue
+		}
+
+		if err := write(db.WithContext(ctx), dst.Table, group); err != nil {
+			logger.Error("[jotice] failed to double write "+name,
+				zap.String("db", dst.DB),
+				zap.String("table", dst.Table),
+				zap.Int("count", len(group)),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// insertIgnored inserts the rows, the ones already copied by the migrator are ignored.
+func insertIgnored[T any](db *gorm.DB, table string, rows []T) error {
+	return db.Table(table).Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+func identity(id uint64) uint64 {
+	return id
+}
@@ -0,0 +1,216 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/JrMarcco/easy-kit/xsync"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var errFakeDriver = errors.New("fake driver")
+
+// fakeResult is the result of a statement run by fakeDB.
+type fakeResult struct {
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+}
+
+// fakeStmt is a statement run by fakeDB.
+type fakeStmt struct {
+	query string
+	args  []any
+	// tx is the number of the transaction the statement runs in, zero if not in a transaction.
+	tx int
+}
+
+// fakeDB is a fake database/sql driver recording the statements,
+// the results are returned by handle, an empty result if handle is nil.
+type fakeDB struct {
+	mu     sync.Mutex
+	stmts  []fakeStmt
+	txs    int
+	inTx   int
+	commit []int
+	abort  []int
+
+	handle func(query string, args []any) fakeResult
+}
+
+func (f *fakeDB) run(query string, args []driver.NamedValue) fakeResult {
+	vals := make([]any, 0, len(args))
+	for _, arg := range args {
+		vals = append(vals, arg.Value)
+	}
+
+	f.mu.Lock()
+	f.stmts = append(f.stmts, fakeStmt{query: query, args: vals, tx: f.inTx})
+	handle := f.handle
+	f.mu.Unlock()
+
+	if handle == nil {
+		return fakeResult{}
+	}
+	return handle(query, vals)
+}
+
+// queries returns the queries recorded containing the substring.
+func (f *fakeDB) queries(substr string) []fakeStmt {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var res []fakeStmt
+	for _, stmt := range f.stmts {
+		if strings.Contains(stmt.query, substr) {
+			res = append(res, stmt)
+		}
+	}
+	return res
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{db: f}, nil
+}
+
+func (f *fakeDB) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errFakeDriver
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errFakeDriver
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	c.db.txs++
+	c.db.inTx = c.db.txs
+	return &fakeTx{db: c.db, id: c.db.txs}, nil
+}
+
+// CheckNamedValue accepts all the values, e.g. the uint64 ids with the high bit set.
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res := c.db.run(query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return driver.RowsAffected(res.affected), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	res := c.db.run(query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return &fakeRows{columns: res.columns, rows: res.rows}, nil
+}
+
+type fakeTx struct {
+	db *fakeDB
+	id int
+}
+
+func (t *fakeTx) Commit() error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	t.db.inTx = 0
+	t.db.commit = append(t.db.commit, t.id)
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	t.db.inTx = 0
+	t.db.abort = append(t.db.abort, t.id)
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// newFakeDBs opens a gorm db on a fakeDB for each name, each db holds a single connection,
+// so the statements of a transaction are recorded in order.
+func newFakeDBs(t *testing.T, names ...string) (*xsync.Map[string, *gorm.DB], map[string]*fakeDB) {
+	dbs := &xsync.Map[string, *gorm.DB]{}
+	fakes := make(map[string]*fakeDB, len(names))
+	for _, name := range names {
+		fake := &fakeDB{}
+		sqlDB := sql.OpenDB(fake)
+		sqlDB.SetMaxOpenConns(1)
+
+		db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+		require.NoError(t, err)
+
+		dbs.Store(name, db)
+		fakes[name] = fake
+	}
+	return dbs, fakes
+}
+
+// argsOf returns the args of the first query recorded containing the substring and the arg.
+func (f *fakeDB) argsOf(substr string, arg any) []any {
+	for _, stmt := range f.queries(substr) {
+		for _, a := range stmt.args {
+			if a == arg {
+				return stmt.args
+			}
+		}
+	}
+	return nil
+}
@@ -4,39 +4,68 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/JrMarcco/easy-kit/list"
 	"github.com/JrMarcco/easy-kit/xsync"
+	"github.com/JrMarcco/jotice/internal/errs"
 	"github.com/JrMarcco/jotice/internal/pkg/sharding"
 	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
 	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	"gorm.io/gorm"
 )
 
 // Notification entity definition.
 type Notification struct {
-	Id            uint64
-	BizId         uint64
-	BizKey        string
-	Receivers     string
-	Channel       string
-	TplId         uint64
-	TplVersionId  uint64
-	TplParams     string
-	Status        string
-	ScheduleStrat int64
-	ScheduleEnd   int64
-	Version       int32
-	CreatedAt     int64
-	UpdatedAt     int64
+	Id            uint64 `gorm:"column:id;primaryKey"`
+	BizId         uint64 `gorm:"column:biz_id"`
+	BizKey        string `gorm:"column:biz_key"`
+	Receivers     string `gorm:"column:receivers"`
+	Channel       string `gorm:"column:channel"`
+	TplId         uint64 `gorm:"column:tpl_id"`
+	TplVersionId  uint64 `gorm:"column:tpl_version_id"`
+	TplParams     string `gorm:"column:tpl_params"`
+	Status        string `gorm:"column:status"`
+	ScheduleStrat int64  `gorm:"column:schedule_start"`
+	ScheduleEnd   int64  `gorm:"column:schedule_end"`
+	Version       int32  `gorm:"column:version"`
+	CreatedAt     int64  `gorm:"column:created_at"`
+	UpdatedAt     int64  `gorm:"column:updated_at"`
 }
 
 type NotificationDAO interface {
+	// Create creates the notification in its shard, with an init callback log in the same transaction if withCallbackLog.
+	// Returns errs.ErrDuplicateNotification if the biz key of the biz already exists.
+	Create(ctx context.Context, notification Notification, withCallbackLog bool) (Notification, error)
+	// BatchCreate creates the notifications grouped by shard, each shard in its own transaction.
+	// The ids given are kept, which should be generated by snowflake.Generator to embed the shard hash,
+	// the zero ids are generated.
+	//
+	// The notification of which the biz key exists is not created, the existing one is returned in its place,
+	// the caller tells it by the id. So the retry of a batch partially committed creates the rest only.
+	BatchCreate(ctx context.Context, notifications []Notification, withCallbackLog bool) ([]Notification, error)
+	// BatchUpdateStatus updates the statuses of the notifications routed by their ids.
+	BatchUpdateStatus(ctx context.Context, notifications []Notification) error
+
+	// GetById routes by the hash embedded in the id, so the biz id and biz key are not needed.
+	GetById(ctx context.Context, id uint64) (Notification, error)
+	// LocateById is GetById returning the shard the notification is read from,
+	// the table of which is the archive table if the notification has been archived.
+	LocateById(ctx context.Context, id uint64) (Notification, sharding.Dst, error)
+	// BatchGetByIds groups the ids by shard, the ids not found are absent in the result.
+	BatchGetByIds(ctx context.Context, ids ...uint64) (map[uint64]Notification, error)
 	GetByBizKey(ctx context.Context, bizId uint64, bizKey string) (Notification, error)
 	GetByBizKeys(ctx context.Context, bizId uint64, bizKeys ...string) ([]Notification, error)
 
 	FindReadyNotifications(ctx context.Context, offset, limit int) ([]Notification, error)
+
+	// Archive moves the notifications in statuses updated before the time to the archive tables with their callback logs.
+	// The lookups fall back to the archive tables, so the notifications archived can still be found.
+	Archive(ctx context.Context, statuses []string, before int64, batchSize int) (int64, error)
 }
 
 var _ NotificationDAO = (*NotifShardingDAO)(nil)
@@ -48,41 +77,385 @@ type NotifShardingDAO struct {
 	cbLogShardingStrategy sharding.Strategy
 
 	idGenerator *snowflake.Generator
+	logger      *zap.Logger
 }
 
-func (n *NotifShardingDAO) GetByBizKey(ctx context.Context, bizId uint64, bizKey string) (Notification, error) {
-	dst := n.notifShardingStrategy.Shard(bizId, bizKey)
+func (n *NotifShardingDAO) Create(
+	ctx context.Context, notification Notification, withCallbackLog bool,
+) (Notification, error) {
+	ns := []Notification{notification}
+	res, err := n.BatchCreate(ctx, ns, withCallbackLog)
+	if err != nil {
+		return Notification{}, err
+	}
+
+	if res[0].Id != ns[0].Id {
+		return Notification{}, fmt.Errorf(
+			"%w: biz id = %d, biz key = %s", errs.ErrDuplicateNotification, notification.BizId, notification.BizKey,
+		)
+	}
+	return res[0], nil
+}
+
+func (n *NotifShardingDAO) BatchCreate(
+	ctx context.Context, notifications []Notification, withCallbackLog bool,
+) ([]Notification, error) {
+	if len(notifications) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now().UnixMilli()
+	groups := make(map[sharding.Dst][]int)
+	for i := range notifications {
+		notif := &notifications[i]
+		// the id given by the service is kept, it has been returned to the caller already.
+		if notif.Id == 0 {
+			id, err := n.idGenerator.NextId(notif.BizId, notif.BizKey)
+			if err != nil {
+				return nil, err
+			}
+			notif.Id = id
+		}
+		notif.CreatedAt = now
+		notif.UpdatedAt = now
+
+		// route by the id, so the time range strategy routes to the table of the time embedded in the id.
+		dst := n.notifShardingStrategy.ShardWithId(notif.Id)
+		groups[dst] = append(groups[dst], i)
+	}
+
+	res := slices.Clone(notifications)
+
+	var eg errgroup.Group
+	for dst, indexes := range groups {
+		eg.Go(func() error {
+			group := make([]Notification, 0, len(indexes))
+			for _, i := range indexes {
+				group = append(group, notifications[i])
+			}
+
+			existing, err := n.createInShard(ctx, dst, group, withCallbackLog)
+			if err != nil {
+				return err
+			}
+			// the groups hold disjoint indexes, so they are written concurrently.
+			for j, i := range indexes {
+				if notif, ok := existing[j]; ok {
+					res[i] = notif
+				}
+			}
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// createInShard creates the notifications of the same shard and their callback logs in a local transaction.
+// The callback log is sharded by the same key as the notification, so it is in the same db.
+//
+// The notifications of which the biz key exists are skipped, the existing ones are returned keyed by their indexes.
+// A concurrent create of the same biz key is still rejected by the unique constraint.
+func (n *NotifShardingDAO) createInShard(
+	ctx context.Context, dst sharding.Dst, notifications []Notification, withCallbackLog bool,
+) (map[int]Notification, error) {
+	db, ok := n.dbs.Load(dst.DB)
+	if !ok {
+		return nil, fmt.Errorf("unknown db: %s", dst.DB)
+	}
+
+	var existing map[int]Notification
+	var created []Notification
+	var cbLogs []CallbackLog
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if existing, err = n.findExisting(tx, dst, notifications); err != nil {
+			return err
+		}
+
+		created = make([]Notification, 0, len(notifications)-len(existing))
+		for i, notif := range notifications {
+			if _, ok := existing[i]; !ok {
+				created = append(created, notif)
+			}
+		}
+		if len(created) == 0 {
+			return nil
+		}
+
+		if err = tx.Table(dst.Table).Create(&created).Error; err != nil {
+			return err
+		}
+
+		if !withCallbackLog {
+			return nil
+		}
+
+		tableLogs, err := n.initCallbackLogs(dst, created)
+		if err != nil {
+			return err
+		}
+		for table, logs := range tableLogs {
+			if err = tx.Table(table).Create(&logs).Error; err != nil {
+				return err
+			}
+			cbLogs = append(cbLogs, logs...)
+		}
+		return nil
+	})
+
+	if n.isUniqueConstraintErr(err) {
+		return nil, fmt.Errorf("%w, cause of: %w", errs.ErrDuplicateNotification, err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	n.doubleWrite(ctx, created)
+	// the callback logs are resharded with the notifications, so they stay in the same db.
+	doubleWrite(ctx, n.dbs, n.cbLogShardingStrategy, n.logger, "callback logs", cbLogs, logId, insertIgnored[CallbackLog])
+	return existing, nil
+}
+
+// findExisting finds the notifications of which the biz key exists, keyed by their indexes.
+//
+// The unique constraint of biz key holds in a table only, so the biz key created in the earlier months
+// by a range strategy is looked up in its recent tables too, which are in the same db of dst.
+func (n *NotifShardingDAO) findExisting(
+	tx *gorm.DB, dst sharding.Dst, notifications []Notification,
+) (map[int]Notification, error) {
+	tablePairs := map[string][][]any{dst.Table: nil}
+	for _, notif := range notifications {
+		pair := []any{notif.BizId, notif.BizKey}
+		tablePairs[dst.Table] = append(tablePairs[dst.Table], pair)
+
+		for _, recent := range sharding.ShardRecent(n.notifShardingStrategy, notif.BizId, notif.BizKey) {
+			if recent.DB == dst.DB && recent.Table != dst.Table {
+				tablePairs[recent.Table] = append(tablePairs[recent.Table], pair)
+			}
+		}
+	}
+
+	type bizKey struct {
+		bizId  uint64
+		bizKey string
+	}
+	foundMap := make(map[bizKey]Notification)
+	for table, pairs := range tablePairs {
+		var found []Notification
+		if err := tx.Table(table).Where("(biz_id, biz_key) IN ?", pairs).Find(&found).Error; err != nil {
+			return nil, err
+		}
+		for _, notif := range found {
+			foundMap[bizKey{bizId: notif.BizId, bizKey: notif.BizKey}] = notif
+		}
+	}
+
+	existing := make(map[int]Notification, len(foundMap))
+	for i, notif := range notifications {
+		if f, ok := foundMap[bizKey{bizId: notif.BizId, bizKey: notif.BizKey}]; ok {
+			existing[i] = f
+		}
+	}
+	return existing, nil
+}
+
+// initCallbackLogs builds the init callback logs of the notifications, grouped by table.
+func (n *NotifShardingDAO) initCallbackLogs(
+	dst sharding.Dst, notifications []Notification,
+) (map[string][]CallbackLog, error) {
+	cbLogs := make(map[string][]CallbackLog)
+	for _, notif := range notifications {
+		cbLogId, err := n.idGenerator.NextId(notif.BizId, notif.BizKey)
+		if err != nil {
+			return nil, err
+		}
+		cbDst := n.cbLogShardingStrategy.ShardWithId(cbLogId)
+		if cbDst.DB != dst.DB {
+			return nil, fmt.Errorf("callback log db %s is not the same as notification db %s", cbDst.DB, dst.DB)
+		}
+
+		cbLogs[cbDst.Table] = append(cbLogs[cbDst.Table], CallbackLog{
+			Id:             cbLogId,
+			NotificationId: notif.Id,
+			BizId:          notif.BizId,
+			BizKey:         notif.BizKey,
+			Status:         "init",
+			Attempts:       "[]",
+			CreatedAt:      notif.CreatedAt,
+			UpdatedAt:      notif.UpdatedAt,
+		})
+	}
+	return cbLogs, nil
+}
+
+// doubleWrite writes the notifications to the new layout too, if the sharding strategy is in resharding.
+func (n *NotifShardingDAO) doubleWrite(ctx context.Context, notifications []Notification) {
+	doubleWrite(ctx, n.dbs, n.notifShardingStrategy, n.logger, "notifications", notifications,
+		func(notif Notification) uint64 { return notif.Id }, insertIgnored[Notification],
+	)
+}
+
+func (n *NotifShardingDAO) BatchUpdateStatus(ctx context.Context, notifications []Notification) error {
+	type group struct {
+		dst    sharding.Dst
+		status string
+	}
+
+	now := time.Now().UnixMilli()
+	groups := make(map[group][]uint64)
+	for _, notif := range notifications {
+		g := group{dst: n.notifShardingStrategy.ShardWithId(notif.Id), status: notif.Status}
+		groups[g] = append(groups[g], notif.Id)
+	}
+
+	var eg errgroup.Group
+	for g, ids := range groups {
+		eg.Go(func() error {
+			db, ok := n.dbs.Load(g.dst.DB)
+			if !ok {
+				return fmt.Errorf("unknown db: %s", g.dst.DB)
+			}
+
+			err := updateStatus(db.WithContext(ctx), g.dst.Table, ids, g.status, now)
+			if err != nil {
+				return err
+			}
+
+			n.doubleWriteStatus(ctx, ids, g.status, now)
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+func updateStatus(db *gorm.DB, table string, ids []uint64, status string, now int64) error {
+	return db.Table(table).
+		Where("id IN ?", ids).
+		Updates(map[string]any{
+			"status":     status,
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": now,
+		}).Error
+}
+
+// doubleWriteStatus updates the statuses in the new layout too, if the sharding strategy is in resharding.
+// The rows not copied to the new layout yet are not updated here, the migrator copies them with the new status.
+func (n *NotifShardingDAO) doubleWriteStatus(ctx context.Context, ids []uint64, status string, now int64) {
+	doubleWrite(ctx, n.dbs, n.notifShardingStrategy, n.logger, "notification statuses", ids, identity,
+		func(db *gorm.DB, table string, ids []uint64) error {
+			return updateStatus(db, table, ids, status, now)
+		},
+	)
+}
+
+// GetById falls back to the archive table if the notification has been archived.
+func (n *NotifShardingDAO) GetById(ctx context.Context, id uint64) (Notification, error) {
+	notif, _, err := n.LocateById(ctx, id)
+	return notif, err
+}
+
+func (n *NotifShardingDAO) LocateById(ctx context.Context, id uint64) (Notification, sharding.Dst, error) {
+	dst := n.notifShardingStrategy.ShardWithId(id)
 	dstDB, ok := n.dbs.Load(dst.DB)
 	if !ok {
-		return Notification{}, fmt.Errorf("unknown db: %s", dst.DB)
+		return Notification{}, sharding.Dst{}, fmt.Errorf("unknown db: %s", dst.DB)
 	}
 
 	var notif Notification
-	err := dstDB.WithContext(ctx).Table(dst.Table).
-		Where("`biz_id` = ? AND `biz_key` = ?", bizId, bizKey).
-		First(&notif).Error
+	table, err := n.firstWithArchive(dstDB.WithContext(ctx), dst, &notif, "id = ?", id)
+	if err != nil {
+		return Notification{}, sharding.Dst{}, fmt.Errorf("failed to get notification by Id = %d, cause of: %w", id, err)
+	}
+	dst.Table = table
+	return notif, dst, nil
+}
+
+// BatchGetByIds falls back to the archive tables for the ids not found.
+func (n *NotifShardingDAO) BatchGetByIds(ctx context.Context, ids ...uint64) (map[uint64]Notification, error) {
+	idMap := make(map[sharding.Dst][]uint64)
+	for _, id := range ids {
+		dst := n.notifShardingStrategy.ShardWithId(id)
+		idMap[dst] = append(idMap[dst], id)
+	}
+
+	var eg errgroup.Group
+
+	notifList := list.ConcurrentList[Notification]{
+		List: list.NewArrayList[Notification](len(ids)),
+	}
+	for dst, dstIds := range idMap {
+		eg.Go(func() error {
+			gormDB, ok := n.dbs.Load(dst.DB)
+			if !ok {
+				return fmt.Errorf("unknown db: %s", dst.DB)
+			}
+
+			notifs, err := findWithArchive(gormDB.WithContext(ctx), dst, dstIds, func(notif Notification) uint64 {
+				return notif.Id
+			}, func(tx *gorm.DB, ids []uint64) *gorm.DB {
+				return tx.Where("id IN ?", ids)
+			})
+			if err != nil {
+				return err
+			}
+			return notifList.Append(notifs...)
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	notifs := notifList.ToSlice()
+	res := make(map[uint64]Notification, len(notifs))
+	for _, notif := range notifs {
+		res[notif.Id] = notif
+	}
+	return res, nil
+}
+
+// GetByBizKey looks up the recent dsts of the biz key, newest first,
+// and falls back to the archive table of each dst if the notification has been archived.
+func (n *NotifShardingDAO) GetByBizKey(ctx context.Context, bizId uint64, bizKey string) (Notification, error) {
+	var notif Notification
+	err := gorm.ErrRecordNotFound
+	for _, dst := range sharding.ShardRecent(n.notifShardingStrategy, bizId, bizKey) {
+		dstDB, ok := n.dbs.Load(dst.DB)
+		if !ok {
+			return Notification{}, fmt.Errorf("unknown db: %s", dst.DB)
+		}
+
+		_, err = n.firstWithArchive(dstDB.WithContext(ctx), dst, &notif, "biz_id = ? AND biz_key = ?", bizId, bizKey)
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			break
+		}
+	}
 	if err != nil {
 		return Notification{}, fmt.Errorf("failed to get notification by BizId = %d and BizKey = %s, cause of: %w", bizId, bizKey, err)
 	}
 	return notif, nil
 }
 
+// GetByBizKeys looks up the recent dsts of the biz keys, newest first,
+// and falls back to the archive tables for the biz keys not found.
 func (n *NotifShardingDAO) GetByBizKeys(ctx context.Context, bizId uint64, bizKeys ...string) ([]Notification, error) {
-	notifMap := make(map[[2]string][]string, len(bizKeys))
+	// group the biz keys by their newest dst, the keys in the same group have the same recent dsts.
+	notifMap := make(map[sharding.Dst][]string, len(bizKeys))
+	recentDsts := make(map[sharding.Dst][]sharding.Dst, len(bizKeys))
 	for index := range bizKeys {
 		bizKey := bizKeys[index]
 
-		dst := n.notifShardingStrategy.Shard(bizId, bizKey)
-
-		shardingInfo := [2]string{dst.DB, dst.Table}
-
-		val, ok := notifMap[shardingInfo]
-		if !ok {
-			val = []string{bizKey}
-		} else {
-			val = append(val, bizKey)
+		dsts := sharding.ShardRecent(n.notifShardingStrategy, bizId, bizKey)
+		if len(dsts) == 0 {
+			continue
 		}
-		notifMap[shardingInfo] = val
+		notifMap[dsts[0]] = append(notifMap[dsts[0]], bizKey)
+		recentDsts[dsts[0]] = dsts
 	}
 
 	var eg errgroup.Group
@@ -90,24 +463,32 @@ func (n *NotifShardingDAO) GetByBizKeys(ctx context.Context, bizId uint64, bizKe
 	notifList := list.ConcurrentList[Notification]{
 		List: list.NewArrayList[Notification](len(bizKeys)),
 	}
-	for shardingInfo, ks := range notifMap {
+	for first, ks := range notifMap {
 		eg.Go(func() error {
-			dbName := shardingInfo[0]
-			tableName := shardingInfo[1]
+			for _, dst := range recentDsts[first] {
+				gormDB, ok := n.dbs.Load(dst.DB)
+				if !ok {
+					return fmt.Errorf("unknown db: %s", dst.DB)
+				}
 
-			gormDB, ok := n.dbs.Load(dbName)
-			if !ok {
-				return fmt.Errorf("unknown db: %s", dbName)
-			}
+				notifs, err := findWithArchive(gormDB.WithContext(ctx), dst, ks, func(notif Notification) string {
+					return notif.BizKey
+				}, func(tx *gorm.DB, keys []string) *gorm.DB {
+					return tx.Where("biz_id = ? AND biz_key IN ?", bizId, keys)
+				})
+				if err != nil {
+					return err
+				}
+				if err = notifList.Append(notifs...); err != nil {
+					return err
+				}
 
-			var notifs []Notification
-			err := gormDB.WithContext(ctx).Table(tableName).
-				Where("`biz_id` = ? AND `biz_key` IN ?", bizId, ks).
-				Find(&notifs).Error
-			if err != nil {
-				return err
+				// look up the keys not found in the earlier dsts.
+				if ks = missingKeys(ks, notifs); len(ks) == 0 {
+					return nil
+				}
 			}
-			return notifList.Append(notifs...)
+			return nil
 		})
 	}
 
@@ -115,6 +496,69 @@ func (n *NotifShardingDAO) GetByBizKeys(ctx context.Context, bizId uint64, bizKe
 	return notifList.ToSlice(), err
 }
 
+// missingKeys returns the biz keys not in the notifications.
+func missingKeys(bizKeys []string, notifs []Notification) []string {
+	found := make(map[string]struct{}, len(notifs))
+	for _, notif := range notifs {
+		found[notif.BizKey] = struct{}{}
+	}
+
+	var res []string
+	for _, bizKey := range bizKeys {
+		if _, ok := found[bizKey]; !ok {
+			res = append(res, bizKey)
+		}
+	}
+	return res
+}
+
+// firstWithArchive finds the first notification in the table of dst, then in its archive table if not found.
+// Returns the table the notification is found in.
+func (n *NotifShardingDAO) firstWithArchive(
+	db *gorm.DB, dst sharding.Dst, notif *Notification, query string, args ...any,
+) (string, error) {
+	err := db.Table(dst.Table).Where(query, args...).First(notif).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return dst.Table, err
+	}
+
+	archive := ArchiveTable(dst)
+	return archive, db.Table(archive).Where(query, args...).First(notif).Error
+}
+
+// findWithArchive finds the notifications by keys in the table of dst,
+// then finds the keys not found in its archive table.
+func findWithArchive[K comparable](
+	db *gorm.DB, dst sharding.Dst, keys []K, keyOf func(Notification) K, query func(tx *gorm.DB, keys []K) *gorm.DB,
+) ([]Notification, error) {
+	var notifs []Notification
+	if err := query(db.Table(dst.Table), keys).Find(&notifs).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[K]struct{}, len(notifs))
+	for _, notif := range notifs {
+		found[keyOf(notif)] = struct{}{}
+	}
+
+	var missing []K
+	for _, key := range keys {
+		if _, ok := found[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) == 0 {
+		return notifs, nil
+	}
+
+	var archived []Notification
+	if err := query(db.Table(ArchiveTable(dst)), missing).Find(&archived).Error; err != nil {
+		return nil, err
+	}
+	return append(notifs, archived...), nil
+}
+
 // FindReadyNotifications implements at dao.DefaultNotifDAO.
 // Only use in the loop job.
 func (n *NotifShardingDAO) FindReadyNotifications(ctx context.Context, offset, limit int) ([]Notification, error) {
@@ -126,12 +570,14 @@ func NewNotifShardingDAO(
 	notifShardingStrategy sharding.Strategy,
 	cbLogShardingStrategy sharding.Strategy,
 	idGenerator *snowflake.Generator,
+	logger *zap.Logger,
 ) *NotifShardingDAO {
 	return &NotifShardingDAO{
 		dbs:                   dbs,
 		notifShardingStrategy: notifShardingStrategy,
 		cbLogShardingStrategy: cbLogShardingStrategy,
 		idGenerator:           idGenerator,
+		logger:                logger,
 	}
 }
 
@@ -140,6 +586,16 @@ func (n *NotifShardingDAO) isUniqueConstraintErr(err error) bool {
 		return false
 	}
 
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+
+	pgErr := new(pgconn.PgError)
+	if ok := errors.As(err, &pgErr); ok {
+		const uniqueViolationCode = "23505"
+		return pgErr.Code == uniqueViolationCode
+	}
+
 	mysqlErr := new(mysql.MySQLError)
 	if ok := errors.As(err, &mysqlErr); ok {
 		const uniqueConstraintErrCode = 1062
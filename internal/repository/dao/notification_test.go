@@ -0,0 +1,318 @@
+package dao
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func newTestNotifDAO(t *testing.T) (*NotifShardingDAO, map[string]*fakeDB, *snowflake.Generator) {
+	dbs, fakes := newFakeDBs(t, "jotice_0", "jotice_1")
+	idGenerator := snowflake.NewGenerator()
+	return NewNotifShardingDAO(
+		dbs,
+		sharding.NewHashStrategy("jotice", "notification", 2, 2),
+		sharding.NewHashStrategy("jotice", "callback_log", 2, 2),
+		idGenerator,
+		zap.NewNop(),
+	), fakes, idGenerator
+}
+
+func TestNotifShardingDAO_BatchCreate(t *testing.T) {
+	t.Parallel()
+
+	dao, fakes, idGenerator := newTestNotifDAO(t)
+
+	// the id given by the service is kept, the zero one is generated
+	givenId, err := idGenerator.NextId(1, "given")
+	require.NoError(t, err)
+
+	notifs, err := dao.BatchCreate(t.Context(), []Notification{
+		{Id: givenId, BizId: 1, BizKey: "given", Status: "pending"},
+		{BizId: 1, BizKey: "generated", Status: "pending"},
+	}, true)
+	require.NoError(t, err)
+	require.Len(t, notifs, 2)
+	assert.Equal(t, givenId, notifs[0].Id)
+	assert.NotZero(t, notifs[1].Id)
+
+	strategy := sharding.NewHashStrategy("jotice", "notification", 2, 2)
+	cbLogStrategy := sharding.NewHashStrategy("jotice", "callback_log", 2, 2)
+	for _, notif := range notifs {
+		assert.Positive(t, notif.CreatedAt)
+
+		// the id routes to the shard of the biz key, so GetById finds it
+		dst := strategy.ShardWithId(notif.Id)
+		assert.Equal(t, strategy.Shard(notif.BizId, notif.BizKey), dst)
+
+		fake := fakes[dst.DB]
+		inserts := fake.queries(`INSERT INTO "` + dst.Table + `"`)
+		require.NotEmpty(t, inserts)
+		assert.Contains(t, inserts[0].args, notif.Id)
+
+		// the callback log is created in the same transaction of the same db
+		cbLogDst := cbLogStrategy.Shard(notif.BizId, notif.BizKey)
+		cbLogInserts := fake.queries(`INSERT INTO "` + cbLogDst.Table + `"`)
+		require.NotEmpty(t, cbLogInserts)
+		assert.Contains(t, cbLogInserts[0].args, notif.Id)
+		assert.Equal(t, inserts[0].tx, cbLogInserts[0].tx)
+		assert.NotZero(t, inserts[0].tx)
+		assert.Contains(t, fake.commit, inserts[0].tx)
+	}
+}
+
+func TestNotifShardingDAO_CreateDuplicate(t *testing.T) {
+	t.Parallel()
+
+	dao, fakes, _ := newTestNotifDAO(t)
+	for _, fake := range fakes {
+		fake.handle = func(query string, _ []any) fakeResult {
+			if strings.HasPrefix(query, `INSERT INTO "notification_`) {
+				return fakeResult{err: &pgconn.PgError{Code: "23505"}}
+			}
+			return fakeResult{}
+		}
+	}
+
+	_, err := dao.Create(t.Context(), Notification{BizId: 1, BizKey: "duplicate", Status: "pending"}, true)
+	assert.ErrorIs(t, err, errs.ErrDuplicateNotification)
+
+	// the transaction is rolled back before creating the callback log
+	dst := sharding.NewHashStrategy("jotice", "notification", 2, 2).Shard(1, "duplicate")
+	fake := fakes[dst.DB]
+	assert.Empty(t, fake.queries(`INSERT INTO "callback_log_`))
+	assert.Empty(t, fake.commit)
+	assert.Len(t, fake.abort, 1)
+}
+
+func TestNotifShardingDAO_BatchCreateRetry(t *testing.T) {
+	t.Parallel()
+
+	// "created" is committed by the previous try of the batch
+	dao, fakes, _ := newTestNotifDAO(t)
+	for _, fake := range fakes {
+		fake.handle = func(query string, args []any) fakeResult {
+			if strings.HasPrefix(query, `SELECT * FROM "notification_`) && containsArg(args, "created") {
+				return fakeResult{
+					columns: []string{"id", "biz_id", "biz_key", "status"},
+					rows:    [][]driver.Value{{int64(42), int64(1), "created", "succeeded"}},
+				}
+			}
+			return fakeResult{}
+		}
+	}
+
+	notifs, err := dao.BatchCreate(t.Context(), []Notification{
+		{BizId: 1, BizKey: "created", Status: "pending"},
+		{BizId: 1, BizKey: "rest", Status: "pending"},
+	}, true)
+	require.NoError(t, err)
+	require.Len(t, notifs, 2)
+
+	// the existing one is returned in its place
+	assert.Equal(t, uint64(42), notifs[0].Id)
+	assert.Equal(t, "succeeded", notifs[0].Status)
+	assert.NotEqual(t, uint64(42), notifs[1].Id)
+	assert.Equal(t, "rest", notifs[1].BizKey)
+
+	// only the rest is created with its callback log
+	var inserts, cbLogInserts []fakeStmt
+	for _, fake := range fakes {
+		inserts = append(inserts, fake.queries(`INSERT INTO "notification_`)...)
+		cbLogInserts = append(cbLogInserts, fake.queries(`INSERT INTO "callback_log_`)...)
+	}
+	require.Len(t, inserts, 1)
+	assert.NotContains(t, inserts[0].args, "created")
+	require.Len(t, cbLogInserts, 1)
+	assert.Contains(t, cbLogInserts[0].args, notifs[1].Id)
+
+	// the single create of the existing biz key is still a duplicate
+	_, err = dao.Create(t.Context(), Notification{BizId: 1, BizKey: "created", Status: "pending"}, true)
+	assert.ErrorIs(t, err, errs.ErrDuplicateNotification)
+}
+
+func TestNotifShardingDAO_CreateDuplicateRecent(t *testing.T) {
+	t.Parallel()
+
+	dbs, fakes := newFakeDBs(t, "jotice_0", "jotice_1")
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	strategy := sharding.NewTimeRangeStrategy("jotice", "notification", 2, since, 1, 2)
+	dao := NewNotifShardingDAO(
+		dbs, strategy, sharding.NewHashStrategy("jotice", "callback_log", 2, 2), snowflake.NewGenerator(), zap.NewNop(),
+	)
+
+	// "a" is created in the previous month, out of the unique constraint of the table of this month
+	recent := strategy.ShardRecent(1, "a")
+	require.Len(t, recent, 3)
+	for _, fake := range fakes {
+		fake.handle = func(query string, args []any) fakeResult {
+			if strings.Contains(query, `FROM "`+recent[1].Table+`"`) && containsArg(args, "a") {
+				return fakeResult{
+					columns: []string{"id", "biz_id", "biz_key"},
+					rows:    [][]driver.Value{{int64(42), int64(1), "a"}},
+				}
+			}
+			return fakeResult{}
+		}
+	}
+
+	_, err := dao.Create(t.Context(), Notification{BizId: 1, BizKey: "a", Status: "pending"}, true)
+	assert.ErrorIs(t, err, errs.ErrDuplicateNotification)
+
+	// the recent tables are looked up in the transaction of the create
+	fake := fakes[recent[0].DB]
+	selects := fake.queries(`FROM "` + recent[1].Table + `"`)
+	require.Len(t, selects, 1)
+	assert.NotZero(t, selects[0].tx)
+	assert.Empty(t, fake.queries(`INSERT INTO "notification_`))
+}
+
+func TestNotifShardingDAO_GetByBizKeyRecent(t *testing.T) {
+	t.Parallel()
+
+	dbs, fakes := newFakeDBs(t, "jotice_0", "jotice_1")
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	strategy := sharding.NewTimeRangeStrategy("jotice", "notification", 2, since, 1, 2)
+	dao := NewNotifShardingDAO(
+		dbs, strategy, sharding.NewHashStrategy("jotice", "callback_log", 2, 2), snowflake.NewGenerator(), zap.NewNop(),
+	)
+
+	// the notifications of "a" and "b" are created in the previous month and the month before
+	recent := strategy.ShardRecent(1, "a")
+	require.Len(t, recent, 3)
+	found := map[string]string{
+		"a": `FROM "` + recent[1].Table + `"`,
+		"b": `FROM "` + recent[2].Table + `"`,
+	}
+	for _, fake := range fakes {
+		fake.handle = func(query string, args []any) fakeResult {
+			res := fakeResult{columns: []string{"id", "biz_id", "biz_key"}}
+			for bizKey, table := range found {
+				if strings.Contains(query, table) && strings.Contains(query, "biz_key") && containsArg(args, bizKey) {
+					res.rows = append(res.rows, []driver.Value{int64(len(bizKey)), int64(1), bizKey})
+				}
+			}
+			return res
+		}
+	}
+
+	notif, err := dao.GetByBizKey(t.Context(), 1, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", notif.BizKey)
+
+	notifs, err := dao.GetByBizKeys(t.Context(), 1, "a", "b", "c")
+	require.NoError(t, err)
+	bizKeys := make([]string, 0, len(notifs))
+	for _, n := range notifs {
+		bizKeys = append(bizKeys, n.BizKey)
+	}
+	assert.ElementsMatch(t, []string{"a", "b"}, bizKeys)
+
+	// the key not found is looked up in the recent tables and their archive tables only
+	fake := fakes[strategy.Shard(1, "c").DB]
+	selects := len(fake.queries("SELECT"))
+	_, err = dao.GetByBizKey(t.Context(), 1, "c")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Len(t, fake.queries("SELECT"), selects+6)
+}
+
+func containsArg(args []any, val any) bool {
+	for _, arg := range args {
+		if arg == val {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleWriteStrategy double writes all the notifications to the table in the db.
+type doubleWriteStrategy struct {
+	sharding.HashStrategy
+	db    string
+	table string
+}
+
+func (s doubleWriteStrategy) DoubleWriteDst(uint64) (sharding.Dst, bool) {
+	return sharding.Dst{DB: s.db, Table: s.table}, true
+}
+
+func TestNotifShardingDAO_CreateDoubleWriteFailed(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name string
+		db   string
+	}{
+		{name: "write failed", db: "jotice_0"},
+		{name: "unknown db", db: "jotice_v2_0"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dbs, fakes := newFakeDBs(t, "jotice_0", "jotice_1")
+			for _, fake := range fakes {
+				fake.handle = func(query string, _ []any) fakeResult {
+					if strings.HasPrefix(query, `INSERT INTO "notification_v2"`) {
+						return fakeResult{err: errFakeDriver}
+					}
+					return fakeResult{}
+				}
+			}
+
+			strategy := doubleWriteStrategy{
+				HashStrategy: sharding.NewHashStrategy("jotice", "notification", 2, 2),
+				db:           tc.db,
+				table:        "notification_v2",
+			}
+			cbLogStrategy := sharding.NewHashStrategy("jotice", "callback_log", 2, 2)
+			dao := NewNotifShardingDAO(dbs, strategy, cbLogStrategy, snowflake.NewGenerator(), zap.NewNop())
+
+			// the notification committed is returned, the failed double write is left to the migrator.
+			notif, err := dao.Create(t.Context(), Notification{BizId: 1, BizKey: "double", Status: "pending"}, false)
+			require.NoError(t, err)
+			assert.NotZero(t, notif.Id)
+		})
+	}
+}
+
+func TestNotifShardingDAO_BatchUpdateStatus(t *testing.T) {
+	t.Parallel()
+
+	dbs, fakes := newFakeDBs(t, "jotice_0", "jotice_1")
+	strategy := doubleWriteStrategy{
+		HashStrategy: sharding.NewHashStrategy("jotice", "notification", 2, 2),
+		db:           "jotice_1",
+		table:        "notification_v2",
+	}
+	cbLogStrategy := sharding.NewHashStrategy("jotice", "callback_log", 2, 2)
+	idGenerator := snowflake.NewGenerator()
+	dao := NewNotifShardingDAO(dbs, strategy, cbLogStrategy, idGenerator, zap.NewNop())
+
+	id, err := idGenerator.NextId(1, "update")
+	require.NoError(t, err)
+
+	require.NoError(t, dao.BatchUpdateStatus(t.Context(), []Notification{{Id: id, Status: "success"}}))
+
+	// the status is updated in the shard of the id, and in the new layout in resharding.
+	dst := strategy.ShardWithId(id)
+	updates := fakes[dst.DB].queries(`UPDATE "` + dst.Table + `" SET`)
+	require.Len(t, updates, 1)
+	assert.Contains(t, updates[0].args, "success")
+	assert.Contains(t, updates[0].args, id)
+
+	updates = fakes["jotice_1"].queries(`UPDATE "notification_v2" SET`)
+	require.Len(t, updates, 1)
+	assert.Contains(t, updates[0].args, id)
+}
@@ -0,0 +1,94 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QuotaUsage entity definition.
+// It is the persisted snapshot of the quota counter in redis.
+type QuotaUsage struct {
+	Id        uint64 `gorm:"column:id;primaryKey;autoIncrement"`
+	BizId     uint64 `gorm:"column:biz_id"`
+	Channel   string `gorm:"column:channel"`
+	Period    string `gorm:"column:period"`
+	Used      int64  `gorm:"column:used"`
+	CreatedAt int64  `gorm:"column:created_at"`
+	UpdatedAt int64  `gorm:"column:updated_at"`
+}
+
+func (q QuotaUsage) TableName() string {
+	return "quota_usage"
+}
+
+// QuotaUsageKey is the unique key of quota usage.
+type QuotaUsageKey struct {
+	BizId   uint64
+	Channel string
+	Period  string
+}
+
+type QuotaUsageDAO interface {
+	ListByKeys(ctx context.Context, keys []QuotaUsageKey) ([]QuotaUsage, error)
+	// ListByPeriodPrefix lists the quota usages of the biz of which the period starts with the prefix,
+	// ordered by period and channel.
+	ListByPeriodPrefix(ctx context.Context, bizId uint64, prefix string) ([]QuotaUsage, error)
+	// BatchSave creates the quota usages or overwrites the used quota if the quota usages already exist.
+	BatchSave(ctx context.Context, usages []QuotaUsage) error
+}
+
+var _ QuotaUsageDAO = (*DefaultQuotaUsageDAO)(nil)
+
+type DefaultQuotaUsageDAO struct {
+	db *gorm.DB
+}
+
+func (d *DefaultQuotaUsageDAO) ListByKeys(ctx context.Context, keys []QuotaUsageKey) ([]QuotaUsage, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	tuples := make([][]any, 0, len(keys))
+	for _, key := range keys {
+		tuples = append(tuples, []any{key.BizId, key.Channel, key.Period})
+	}
+
+	var usages []QuotaUsage
+	err := d.db.WithContext(ctx).Where("(biz_id, channel, period) IN ?", tuples).Find(&usages).Error
+	return usages, err
+}
+
+func (d *DefaultQuotaUsageDAO) ListByPeriodPrefix(ctx context.Context, bizId uint64, prefix string) ([]QuotaUsage, error) {
+	var usages []QuotaUsage
+	err := d.db.WithContext(ctx).
+		Where("biz_id = ? AND period LIKE ?", bizId, prefix+"%").
+		Order("period ASC, channel ASC").
+		Find(&usages).Error
+	return usages, err
+}
+
+func (d *DefaultQuotaUsageDAO) BatchSave(ctx context.Context, usages []QuotaUsage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	for i := range usages {
+		usages[i].CreatedAt = now
+		usages[i].UpdatedAt = now
+	}
+
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "biz_id"}, {Name: "channel"}, {Name: "period"}},
+		DoUpdates: clause.AssignmentColumns([]string{"used", "updated_at"}),
+	}).Create(&usages).Error
+}
+
+func NewDefaultQuotaUsageDAO(db *gorm.DB) *DefaultQuotaUsageDAO {
+	return &DefaultQuotaUsageDAO{
+		db: db,
+	}
+}
@@ -2,18 +2,49 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
 	"github.com/JrMarcco/jotice/internal/repository/dao"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // NotificationRepo is a repository for notification.
 type NotificationRepo interface {
+	// Create returns errs.ErrDuplicateNotification if the biz key of the biz already exists.
+	Create(ctx context.Context, n domain.Notification) (domain.Notification, error)
+	// CreateWithCallbackLog creates the notification with an init callback log in the same local transaction.
+	CreateWithCallbackLog(ctx context.Context, n domain.Notification) (domain.Notification, error)
+	// BatchCreate returns the existing notification in place of the one of which the biz key exists,
+	// which is told by the id differing from the one requested.
+	BatchCreate(ctx context.Context, ns []domain.Notification) ([]domain.Notification, error)
+	BatchCreateWithCallbackLog(ctx context.Context, ns []domain.Notification) ([]domain.Notification, error)
+
+	// UpdateStatus updates the status of the notification.
+	UpdateStatus(ctx context.Context, n domain.Notification) error
+	BatchUpdateStatus(ctx context.Context, ns []domain.Notification) error
+
+	// GetById returns errs.ErrNotificationNotFound if the notification is not found.
+	GetById(ctx context.Context, id uint64) (domain.Notification, error)
+	// LocateById is GetById returning the shard the notification is read from,
+	// the table of which is the archive table if the notification has been archived.
+	LocateById(ctx context.Context, id uint64) (domain.Notification, sharding.Dst, error)
+	// BatchGetByIds returns the notifications found, keyed by id.
+	BatchGetByIds(ctx context.Context, ids ...uint64) (map[uint64]domain.Notification, error)
+	// GetByBizKey returns errs.ErrNotificationNotFound if the notification is not found.
 	GetByBizKey(ctx context.Context, bizId uint64, bizKey string) (domain.Notification, error)
 	GetByBizKeys(ctx context.Context, bizId uint64, bizKeys ...string) ([]domain.Notification, error)
 
 	FindDreadyNotifications(ctx context.Context, offset, limit int) ([]domain.Notification, error)
+
+	// Archive archives the notifications in terminal status updated before the time, returns the number archived.
+	Archive(ctx context.Context, before time.Time, batchSize int) (int64, error)
 }
 
 var _ NotificationRepo = (*DefaultNotifRepo)(nil)
@@ -24,14 +55,165 @@ type DefaultNotifRepo struct {
 	logger *zap.Logger
 }
 
+func (d *DefaultNotifRepo) Create(ctx context.Context, n domain.Notification) (domain.Notification, error) {
+	return d.create(ctx, n, false)
+}
+
+func (d *DefaultNotifRepo) CreateWithCallbackLog(ctx context.Context, n domain.Notification) (domain.Notification, error) {
+	return d.create(ctx, n, true)
+}
+
+func (d *DefaultNotifRepo) create(ctx context.Context, n domain.Notification, withCallbackLog bool) (domain.Notification, error) {
+	entity, err := d.toEntity(n)
+	if err != nil {
+		return domain.Notification{}, err
+	}
+
+	created, err := d.dao.Create(ctx, entity, withCallbackLog)
+	if err != nil {
+		return domain.Notification{}, err
+	}
+	n.Id = created.Id
+	return n, nil
+}
+
+func (d *DefaultNotifRepo) BatchCreate(ctx context.Context, ns []domain.Notification) ([]domain.Notification, error) {
+	return d.batchCreate(ctx, ns, false)
+}
+
+func (d *DefaultNotifRepo) BatchCreateWithCallbackLog(
+	ctx context.Context, ns []domain.Notification,
+) ([]domain.Notification, error) {
+	return d.batchCreate(ctx, ns, true)
+}
+
+func (d *DefaultNotifRepo) batchCreate(
+	ctx context.Context, ns []domain.Notification, withCallbackLog bool,
+) ([]domain.Notification, error) {
+	if len(ns) == 0 {
+		return nil, nil
+	}
+
+	entities := make([]dao.Notification, 0, len(ns))
+	for _, n := range ns {
+		entity, err := d.toEntity(n)
+		if err != nil {
+			return nil, err
+		}
+		entities = append(entities, entity)
+	}
+
+	created, err := d.dao.BatchCreate(ctx, entities, withCallbackLog)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Notification, len(ns))
+	for i := range ns {
+		if created[i].Id != entities[i].Id {
+			// the biz key exists, e.g. created by the previous try of the batch.
+			existing, err := d.toDomain(created[i])
+			if err != nil {
+				return nil, err
+			}
+			res[i] = existing
+			continue
+		}
+
+		res[i] = ns[i]
+		res[i].Id = created[i].Id
+	}
+	return res, nil
+}
+
+func (d *DefaultNotifRepo) UpdateStatus(ctx context.Context, n domain.Notification) error {
+	return d.BatchUpdateStatus(ctx, []domain.Notification{n})
+}
+
+func (d *DefaultNotifRepo) BatchUpdateStatus(ctx context.Context, ns []domain.Notification) error {
+	if len(ns) == 0 {
+		return nil
+	}
+
+	entities := make([]dao.Notification, 0, len(ns))
+	for _, n := range ns {
+		entities = append(entities, dao.Notification{Id: n.Id, Status: n.Status.String()})
+	}
+	return d.dao.BatchUpdateStatus(ctx, entities)
+}
+
+func (d *DefaultNotifRepo) GetById(ctx context.Context, id uint64) (domain.Notification, error) {
+	entity, err := d.dao.GetById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Notification{}, fmt.Errorf("%w: id = %d", errs.ErrNotificationNotFound, id)
+		}
+		return domain.Notification{}, err
+	}
+	return d.toDomain(entity)
+}
+
+func (d *DefaultNotifRepo) LocateById(ctx context.Context, id uint64) (domain.Notification, sharding.Dst, error) {
+	entity, dst, err := d.dao.LocateById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Notification{}, sharding.Dst{}, fmt.Errorf("%w: id = %d", errs.ErrNotificationNotFound, id)
+		}
+		return domain.Notification{}, sharding.Dst{}, err
+	}
+
+	n, err := d.toDomain(entity)
+	if err != nil {
+		return domain.Notification{}, sharding.Dst{}, err
+	}
+	return n, dst, nil
+}
+
+func (d *DefaultNotifRepo) BatchGetByIds(ctx context.Context, ids ...uint64) (map[uint64]domain.Notification, error) {
+	entities, err := d.dao.BatchGetByIds(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make(map[uint64]domain.Notification, len(entities))
+	for id, entity := range entities {
+		n, err := d.toDomain(entity)
+		if err != nil {
+			return nil, err
+		}
+		notifications[id] = n
+	}
+	return notifications, nil
+}
+
 func (d *DefaultNotifRepo) GetByBizKey(ctx context.Context, bizId uint64, bizKey string) (domain.Notification, error) {
-	//TODO implement me
-	panic("implement me")
+	entity, err := d.dao.GetByBizKey(ctx, bizId, bizKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Notification{}, fmt.Errorf(
+				"%w: biz id = %d, biz key = %s", errs.ErrNotificationNotFound, bizId, bizKey,
+			)
+		}
+		return domain.Notification{}, err
+	}
+	return d.toDomain(entity)
 }
 
 func (d *DefaultNotifRepo) GetByBizKeys(ctx context.Context, bizId uint64, bizKeys ...string) ([]domain.Notification, error) {
-	//TODO implement me
-	panic("implement me")
+	entities, err := d.dao.GetByBizKeys(ctx, bizId, bizKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make([]domain.Notification, 0, len(entities))
+	for _, entity := range entities {
+		n, err := d.toDomain(entity)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
 }
 
 func (d *DefaultNotifRepo) FindDreadyNotifications(ctx context.Context, offset, limit int) ([]domain.Notification, error) {
@@ -39,6 +221,71 @@ func (d *DefaultNotifRepo) FindDreadyNotifications(ctx context.Context, offset,
 	panic("implement me")
 }
 
+func (d *DefaultNotifRepo) Archive(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	statuses := []string{
+		domain.SendStatusSuccess.String(),
+		domain.SendStatusFailed.String(),
+		domain.SendStatusCanceled.String(),
+	}
+	return d.dao.Archive(ctx, statuses, before.UnixMilli(), batchSize)
+}
+
+func (d *DefaultNotifRepo) toDomain(entity dao.Notification) (domain.Notification, error) {
+	var receivers []string
+	if err := json.Unmarshal([]byte(entity.Receivers), &receivers); err != nil {
+		return domain.Notification{}, fmt.Errorf("failed to unmarshal receivers, cause of: %w", err)
+	}
+
+	var tplParams map[string]string
+	if err := json.Unmarshal([]byte(entity.TplParams), &tplParams); err != nil {
+		return domain.Notification{}, fmt.Errorf("failed to unmarshal template params, cause of: %w", err)
+	}
+
+	return domain.Notification{
+		Id:        entity.Id,
+		BizId:     entity.BizId,
+		BizKey:    entity.BizKey,
+		Receivers: receivers,
+		Channel:   domain.Channel(entity.Channel),
+		Template: domain.Template{
+			Id:        entity.TplId,
+			VersionId: entity.TplVersionId,
+			Params:    tplParams,
+		},
+		Status:         domain.SendStatus(entity.Status),
+		ScheduledStart: time.UnixMilli(entity.ScheduleStrat),
+		ScheduledEnd:   time.UnixMilli(entity.ScheduleEnd),
+		Version:        entity.Version,
+	}, nil
+}
+
+func (d *DefaultNotifRepo) toEntity(n domain.Notification) (dao.Notification, error) {
+	receivers, err := json.Marshal(n.Receivers)
+	if err != nil {
+		return dao.Notification{}, fmt.Errorf("failed to marshal receivers, cause of: %w", err)
+	}
+
+	tplParams, err := json.Marshal(n.Template.Params)
+	if err != nil {
+		return dao.Notification{}, fmt.Errorf("failed to marshal template params, cause of: %w", err)
+	}
+
+	return dao.Notification{
+		Id:            n.Id,
+		BizId:         n.BizId,
+		BizKey:        n.BizKey,
+		Receivers:     string(receivers),
+		Channel:       n.Channel.String(),
+		TplId:         n.Template.Id,
+		TplVersionId:  n.Template.VersionId,
+		TplParams:     string(tplParams),
+		Status:        n.Status.String(),
+		ScheduleStrat: n.ScheduledStart.UnixMilli(),
+		ScheduleEnd:   n.ScheduledEnd.UnixMilli(),
+		Version:       n.Version,
+	}, nil
+}
+
 func NewNotificationRepo(dao dao.NotificationDAO, logger *zap.Logger) *DefaultNotifRepo {
 	return &DefaultNotifRepo{
 		dao:    dao,
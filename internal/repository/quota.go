@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/repository/cache"
+	"github.com/JrMarcco/jotice/internal/repository/dao"
+)
+
+type QuotaRepo interface {
+	// Deduct deducts all the items or none of them, returns the used quota after deducted of each item.
+	// Returns errs.ErrQuotaExhausted if any item exceeds its limit.
+	Deduct(ctx context.Context, items []domain.QuotaItem) ([]int64, error)
+	Refund(ctx context.Context, items []domain.QuotaItem) error
+	// GetUsages gets the used quota of the keys, the used quota is zero if the key has no usage yet.
+	GetUsages(ctx context.Context, keys []domain.QuotaUsage) ([]domain.QuotaUsage, error)
+	// ListDailyUsages lists the persisted daily usages of the biz in the month.
+	ListDailyUsages(ctx context.Context, bizId uint64, month time.Time) ([]domain.QuotaUsage, error)
+	// Sync persists the used quota in cache to db, returns the number of quota usages persisted.
+	Sync(ctx context.Context) (int, error)
+
+	// ClaimAlert claims the alert of the event, so it is emitted once per period and threshold.
+	// Returns false if it has been claimed already.
+	ClaimAlert(ctx context.Context, event domain.QuotaAlertEvent) (bool, error)
+	// ReleaseAlert releases the claimed alert failed to emit, so it can be emitted again.
+	ReleaseAlert(ctx context.Context, event domain.QuotaAlertEvent) error
+}
+
+var _ QuotaRepo = (*DefaultQuotaRepo)(nil)
+
+// DefaultQuotaRepo deducts the quota in redis and reconciles it with db.
+// The quota counter missing in redis (expired or lost) is initialized with the used quota in db.
+type DefaultQuotaRepo struct {
+	dao dao.QuotaUsageDAO
+	rc  cache.QuotaCache
+}
+
+func (d *DefaultQuotaRepo) Deduct(ctx context.Context, items []domain.QuotaItem) ([]int64, error) {
+	used, err := d.rc.Deduct(ctx, items)
+	if !errors.Is(err, cache.ErrKeyNotFound) {
+		return used, err
+	}
+
+	if err = d.initCounters(ctx, items); err != nil {
+		return nil, err
+	}
+	return d.rc.Deduct(ctx, items)
+}
+
+func (d *DefaultQuotaRepo) initCounters(ctx context.Context, items []domain.QuotaItem) error {
+	keys := make([]dao.QuotaUsageKey, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, dao.QuotaUsageKey{
+			BizId:   item.BizId,
+			Channel: item.Channel.String(),
+			Period:  item.Period,
+		})
+	}
+
+	entities, err := d.dao.ListByKeys(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	used := make(map[dao.QuotaUsageKey]int64, len(entities))
+	for _, entity := range entities {
+		used[dao.QuotaUsageKey{BizId: entity.BizId, Channel: entity.Channel, Period: entity.Period}] = entity.Used
+	}
+
+	usages := make([]domain.QuotaUsage, 0, len(keys))
+	for _, key := range keys {
+		usages = append(usages, domain.QuotaUsage{
+			BizId:   key.BizId,
+			Channel: domain.Channel(key.Channel),
+			Period:  key.Period,
+			Used:    used[key],
+		})
+	}
+	return d.rc.Init(ctx, usages)
+}
+
+func (d *DefaultQuotaRepo) Refund(ctx context.Context, items []domain.QuotaItem) error {
+	return d.rc.Refund(ctx, items)
+}
+
+func (d *DefaultQuotaRepo) GetUsages(ctx context.Context, keys []domain.QuotaUsage) ([]domain.QuotaUsage, error) {
+	cached, err := d.rc.GetUsages(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[dao.QuotaUsageKey]int64, len(keys))
+	for _, usage := range cached {
+		used[toUsageKey(usage)] = usage.Used
+	}
+
+	// the quota counters not in cache are expired or lost, fall back to db.
+	missing := make([]dao.QuotaUsageKey, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := used[toUsageKey(key)]; !ok {
+			missing = append(missing, toUsageKey(key))
+		}
+	}
+
+	entities, err := d.dao.ListByKeys(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range entities {
+		used[dao.QuotaUsageKey{BizId: entity.BizId, Channel: entity.Channel, Period: entity.Period}] = entity.Used
+	}
+
+	usages := make([]domain.QuotaUsage, 0, len(keys))
+	for _, key := range keys {
+		key.Used = used[toUsageKey(key)]
+		usages = append(usages, key)
+	}
+	return usages, nil
+}
+
+func (d *DefaultQuotaRepo) ListDailyUsages(ctx context.Context, bizId uint64, month time.Time) ([]domain.QuotaUsage, error) {
+	entities, err := d.dao.ListByPeriodPrefix(ctx, bizId, domain.DailyQuotaPeriodPrefix(month))
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make([]domain.QuotaUsage, 0, len(entities))
+	for _, entity := range entities {
+		usages = append(usages, domain.QuotaUsage{
+			BizId:   entity.BizId,
+			Channel: domain.Channel(entity.Channel),
+			Period:  entity.Period,
+			Used:    entity.Used,
+		})
+	}
+	return usages, nil
+}
+
+func toUsageKey(usage domain.QuotaUsage) dao.QuotaUsageKey {
+	return dao.QuotaUsageKey{
+		BizId:   usage.BizId,
+		Channel: usage.Channel.String(),
+		Period:  usage.Period,
+	}
+}
+
+func (d *DefaultQuotaRepo) Sync(ctx context.Context) (int, error) {
+	usages, err := d.rc.ListUsages(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	entities := make([]dao.QuotaUsage, 0, len(usages))
+	for _, usage := range usages {
+		entities = append(entities, dao.QuotaUsage{
+			BizId:   usage.BizId,
+			Channel: usage.Channel.String(),
+			Period:  usage.Period,
+			Used:    usage.Used,
+		})
+	}
+
+	if err = d.dao.BatchSave(ctx, entities); err != nil {
+		return 0, err
+	}
+	return len(entities), nil
+}
+
+func (d *DefaultQuotaRepo) ClaimAlert(ctx context.Context, event domain.QuotaAlertEvent) (bool, error) {
+	return d.rc.ClaimAlert(ctx, event)
+}
+
+func (d *DefaultQuotaRepo) ReleaseAlert(ctx context.Context, event domain.QuotaAlertEvent) error {
+	return d.rc.ReleaseAlert(ctx, event)
+}
+
+func NewDefaultQuotaRepo(dao dao.QuotaUsageDAO, rc cache.QuotaCache) *DefaultQuotaRepo {
+	return &DefaultQuotaRepo{
+		dao: dao,
+		rc:  rc,
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/repository/cache"
+	"github.com/JrMarcco/jotice/internal/repository/dao"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memQuotaCache is a quota cache in memory, keyed by the quota usage without the used quota.
+type memQuotaCache struct {
+	counters map[domain.QuotaUsage]int64
+	alerts   map[domain.QuotaAlertEvent]bool
+}
+
+func quotaCounterKey(bizId uint64, channel domain.Channel, period string) domain.QuotaUsage {
+	return domain.QuotaUsage{BizId: bizId, Channel: channel, Period: period}
+}
+
+func (m *memQuotaCache) Deduct(_ context.Context, items []domain.QuotaItem) ([]int64, error) {
+	for _, item := range items {
+		used, ok := m.counters[quotaCounterKey(item.BizId, item.Channel, item.Period)]
+		if !ok {
+			return nil, cache.ErrKeyNotFound
+		}
+		if used+item.Amount > item.Limit {
+			return nil, errs.ErrQuotaExhausted
+		}
+	}
+
+	res := make([]int64, 0, len(items))
+	for _, item := range items {
+		key := quotaCounterKey(item.BizId, item.Channel, item.Period)
+		m.counters[key] += item.Amount
+		res = append(res, m.counters[key])
+	}
+	return res, nil
+}
+
+func (m *memQuotaCache) Refund(_ context.Context, items []domain.QuotaItem) error {
+	for _, item := range items {
+		key := quotaCounterKey(item.BizId, item.Channel, item.Period)
+		if used, ok := m.counters[key]; ok {
+			m.counters[key] = max(used-item.Amount, 0)
+		}
+	}
+	return nil
+}
+
+func (m *memQuotaCache) Init(_ context.Context, usages []domain.QuotaUsage) error {
+	for _, usage := range usages {
+		key := quotaCounterKey(usage.BizId, usage.Channel, usage.Period)
+		if _, ok := m.counters[key]; !ok {
+			m.counters[key] = usage.Used
+		}
+	}
+	return nil
+}
+
+func (m *memQuotaCache) GetUsages(_ context.Context, keys []domain.QuotaUsage) ([]domain.QuotaUsage, error) {
+	var res []domain.QuotaUsage
+	for _, key := range keys {
+		if used, ok := m.counters[quotaCounterKey(key.BizId, key.Channel, key.Period)]; ok {
+			key.Used = used
+			res = append(res, key)
+		}
+	}
+	return res, nil
+}
+
+func (m *memQuotaCache) ListUsages(_ context.Context) ([]domain.QuotaUsage, error) {
+	res := make([]domain.QuotaUsage, 0, len(m.counters))
+	for key, used := range m.counters {
+		key.Used = used
+		res = append(res, key)
+	}
+	return res, nil
+}
+
+func (m *memQuotaCache) ClaimAlert(_ context.Context, event domain.QuotaAlertEvent) (bool, error) {
+	key := domain.QuotaAlertEvent{BizId: event.BizId, Channel: event.Channel, Period: event.Period, Threshold: event.Threshold}
+	if m.alerts[key] {
+		return false, nil
+	}
+	m.alerts[key] = true
+	return true, nil
+}
+
+func (m *memQuotaCache) ReleaseAlert(_ context.Context, event domain.QuotaAlertEvent) error {
+	delete(m.alerts, domain.QuotaAlertEvent{BizId: event.BizId, Channel: event.Channel, Period: event.Period, Threshold: event.Threshold})
+	return nil
+}
+
+// memQuotaUsageDAO keeps the persisted quota usages in memory.
+type memQuotaUsageDAO struct {
+	dao.QuotaUsageDAO
+	usages map[dao.QuotaUsageKey]int64
+}
+
+func (m *memQuotaUsageDAO) ListByKeys(_ context.Context, keys []dao.QuotaUsageKey) ([]dao.QuotaUsage, error) {
+	var res []dao.QuotaUsage
+	for _, key := range keys {
+		if used, ok := m.usages[key]; ok {
+			res = append(res, dao.QuotaUsage{BizId: key.BizId, Channel: key.Channel, Period: key.Period, Used: used})
+		}
+	}
+	return res, nil
+}
+
+func (m *memQuotaUsageDAO) BatchSave(_ context.Context, usages []dao.QuotaUsage) error {
+	for _, usage := range usages {
+		m.usages[dao.QuotaUsageKey{BizId: usage.BizId, Channel: usage.Channel, Period: usage.Period}] = usage.Used
+	}
+	return nil
+}
+
+func TestDefaultQuotaRepo(t *testing.T) {
+	t.Parallel()
+
+	qc := &memQuotaCache{counters: make(map[domain.QuotaUsage]int64), alerts: make(map[domain.QuotaAlertEvent]bool)}
+	usageDAO := &memQuotaUsageDAO{
+		usages: map[dao.QuotaUsageKey]int64{
+			// the counter in redis is lost, the db keeps the last synced usage
+			{BizId: 1, Channel: "sms", Period: "m202501"}: 90,
+		},
+	}
+	repo := NewDefaultQuotaRepo(usageDAO, qc)
+
+	items := []domain.QuotaItem{
+		{BizId: 1, Channel: domain.ChannelSMS, Period: "d20250101", Limit: 8, Amount: 5},
+		{BizId: 1, Channel: domain.ChannelSMS, Period: "m202501", Limit: 100, Amount: 5},
+	}
+
+	// the missing counters are initialized from db
+	used, err := repo.Deduct(t.Context(), items)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5, 95}, used)
+
+	_, err = repo.Deduct(t.Context(), items)
+	assert.ErrorIs(t, err, errs.ErrQuotaExhausted)
+
+	// the usage not in cache falls back to db, and the one in neither is zero
+	usageDAO.usages[dao.QuotaUsageKey{BizId: 1, Channel: "email", Period: "m202501"}] = 7
+	usages, err := repo.GetUsages(t.Context(), []domain.QuotaUsage{
+		{BizId: 1, Channel: domain.ChannelSMS, Period: "d20250101"},
+		{BizId: 1, Channel: domain.ChannelEmail, Period: "m202501"},
+		{BizId: 1, Channel: domain.ChannelEmail, Period: "d20250101"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.QuotaUsage{
+		{BizId: 1, Channel: domain.ChannelSMS, Period: "d20250101", Used: 5},
+		{BizId: 1, Channel: domain.ChannelEmail, Period: "m202501", Used: 7},
+		{BizId: 1, Channel: domain.ChannelEmail, Period: "d20250101", Used: 0},
+	}, usages)
+
+	// sync persists all the counters in cache
+	n, err := repo.Sync(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, int64(5), usageDAO.usages[dao.QuotaUsageKey{BizId: 1, Channel: "sms", Period: "d20250101"}])
+	assert.Equal(t, int64(95), usageDAO.usages[dao.QuotaUsageKey{BizId: 1, Channel: "sms", Period: "m202501"}])
+
+	// the alert is claimed once until released
+	event := domain.QuotaAlertEvent{BizId: 1, Channel: domain.ChannelSMS, Period: "d20250101", Threshold: 80, Used: 5}
+	claimed, err := repo.ClaimAlert(t.Context(), event)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	event.Used = 7
+	claimed, err = repo.ClaimAlert(t.Context(), event)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	require.NoError(t, repo.ReleaseAlert(t.Context(), event))
+	claimed, err = repo.ClaimAlert(t.Context(), event)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
@@ -0,0 +1,19 @@
+// Package schema holds the versioned ddl of all the tables, applied by the migrate command.
+//
+// The files are named as "{version}_{logical table}.sql", a new version of a table should be a new file,
+// the applied files should never be changed.
+package schema
+
+import (
+	"embed"
+
+	"github.com/JrMarcco/jotice/internal/pkg/schema"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// Migrations returns all the migrations ordered by version.
+func Migrations() ([]schema.Migration, error) {
+	return schema.Load(files, "sql")
+}
@@ -0,0 +1,32 @@
+package schema
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var indexRegexp = regexp.MustCompile(`INDEX IF NOT EXISTS (\w+) ON (\w+)`)
+
+// TestMigrations_IndexNames checks the indexes are named with the physical table,
+// since the index names are unique in a db holding many physical tables of a logical table.
+func TestMigrations_IndexNames(t *testing.T) {
+	t.Parallel()
+
+	migrations, err := Migrations()
+	require.NoError(t, err)
+
+	for _, m := range migrations {
+		stmts, err := m.Statements("t_3")
+		require.NoError(t, err)
+
+		for _, stmt := range stmts {
+			for _, matches := range indexRegexp.FindAllStringSubmatch(stmt, -1) {
+				assert.Equal(t, "t_3", matches[2])
+				assert.Regexp(t, `^(idx|uk)_t_3_`, matches[1], "index of %d_%s", m.Version, m.Table)
+			}
+		}
+	}
+}
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS {{.Table}}
+(
+    id              BIGINT PRIMARY KEY,                 -- 业务方 id
+    owner_id        BIGINT      NOT NULL,               -- 业务方所属者 id
+    owner_type      VARCHAR(32) NOT NULL,               -- 业务方所属者类型
+    channel_config  JSONB,                              -- 渠道配置
+    tx_notif_config JSONB,                              -- 事务消息配置
+    rate_limit      INTEGER     NOT NULL DEFAULT 1000,  -- 每秒最大请求数
+    quota_config    JSONB,                              -- 配额配置
+    callback_config JSONB,                              -- 回调配置
+    created_at      BIGINT,
+    updated_at      BIGINT
+);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS {{.Table}}
+(
+    id              BIGSERIAL PRIMARY KEY,
+    biz_id          BIGINT       NOT NULL,              -- 业务方 id
+    version         INTEGER      NOT NULL,              -- 版本号，同一业务方内递增
+    owner_id        BIGINT       NOT NULL,
+    owner_type      VARCHAR(32)  NOT NULL,
+    channel_config  JSONB,
+    tx_notif_config JSONB,
+    rate_limit      INTEGER      NOT NULL,
+    quota_config    JSONB,
+    callback_config JSONB,
+    author          VARCHAR(128) NOT NULL DEFAULT '',   -- 修改人
+    created_at      BIGINT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_{{.Table}}_biz_id_version ON {{.Table}}(biz_id, version);
@@ -0,0 +1,19 @@
+-- 回调记录分表，分库分表规则与 notification 一致
+CREATE TABLE IF NOT EXISTS {{.Table}}
+(
+    id              BIGINT PRIMARY KEY,                  -- 雪花 id
+    notification_id BIGINT       NOT NULL,               -- 等待回调通知的 id
+    biz_id          BIGINT       NOT NULL,               -- 业务方 id
+    biz_key         VARCHAR(256) NOT NULL,               -- 业务方唯一标识
+    retry_times     SMALLINT     NOT NULL DEFAULT 0,     -- 重试次数
+    next_retry_at   BIGINT       NOT NULL DEFAULT 0,     -- 下次重试时间戳（毫秒）
+    status          VARCHAR(16)  NOT NULL DEFAULT 'init', -- 回调状态
+    last_error      TEXT         NOT NULL DEFAULT '',    -- 最近一次回调失败的原因
+    attempts        JSONB        NOT NULL DEFAULT '[]',  -- 最近的回调记录
+    created_at      BIGINT,
+    updated_at      BIGINT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_{{.Table}}_notification_id ON {{.Table}}(notification_id);
+CREATE INDEX IF NOT EXISTS idx_{{.Table}}_status_next_retry_at ON {{.Table}}(status, next_retry_at);
+CREATE INDEX IF NOT EXISTS idx_{{.Table}}_biz_id_status_updated_at ON {{.Table}}(biz_id, status, updated_at);
@@ -0,0 +1,17 @@
+-- 回调记录归档分表，与 callback_log 分表一一对应，随通知一起归档
+CREATE TABLE IF NOT EXISTS {{.Table}}
+(
+    id              BIGINT PRIMARY KEY,
+    notification_id BIGINT       NOT NULL,
+    biz_id          BIGINT       NOT NULL,
+    biz_key         VARCHAR(256) NOT NULL,
+    retry_times     SMALLINT     NOT NULL DEFAULT 0,
+    next_retry_at   BIGINT       NOT NULL DEFAULT 0,
+    status          VARCHAR(16)  NOT NULL,
+    last_error      TEXT         NOT NULL DEFAULT '',
+    attempts        JSONB        NOT NULL DEFAULT '[]',
+    created_at      BIGINT,
+    updated_at      BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_{{.Table}}_notification_id ON {{.Table}}(notification_id);
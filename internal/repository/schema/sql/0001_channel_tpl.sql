@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS {{.Table}}
+(
+    id                BIGSERIAL PRIMARY KEY,
+    owner_id          BIGINT       NOT NULL,            -- 模板所属者 id
+    owner_type        VARCHAR(32)  NOT NULL,            -- 模板所属者类型
+    name              VARCHAR(128) NOT NULL,            -- 模板名称
+    description       VARCHAR(512) NOT NULL,            -- 模板描述
+    channel           VARCHAR(16)  NOT NULL,            -- 渠道
+    biz_type          VARCHAR(32)  NOT NULL,            -- 业务类型
+    active_version_id BIGINT       NOT NULL DEFAULT 0,  -- 生效的版本 id，0 为未发布
+    created_at        BIGINT,
+    updated_at        BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_{{.Table}}_owner_id_owner_type ON {{.Table}}(owner_id, owner_type);
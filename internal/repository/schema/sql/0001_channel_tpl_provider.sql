@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS {{.Table}}
+(
+    id               BIGSERIAL PRIMARY KEY,
+    tpl_id           BIGINT       NOT NULL,             -- 模板 id
+    tpl_version_id   BIGINT       NOT NULL,             -- 模板版本 id
+    provider_id      BIGINT       NOT NULL,             -- 供应商 id
+    provider_name    VARCHAR(64)  NOT NULL,             -- 供应商名称
+    provider_channel VARCHAR(16)  NOT NULL,             -- 供应商渠道
+    req_id           VARCHAR(64)  NOT NULL DEFAULT '',  -- 提审请求 id
+    provider_tpl_id  BIGINT       NOT NULL DEFAULT 0,   -- 供应商侧模板 id
+    audit_status     VARCHAR(16)  NOT NULL,             -- 审核状态
+    reject_reason    VARCHAR(512) NOT NULL DEFAULT '',  -- 驳回原因
+    last_review_at   BIGINT       NOT NULL DEFAULT 0,   -- 最近一次提审时间（毫秒）
+    created_at       BIGINT,
+    updated_at       BIGINT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_{{.Table}}_tpl_version_id_provider_id ON {{.Table}}(tpl_version_id, provider_id);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS {{.Table}}
+(
+    id             BIGSERIAL PRIMARY KEY,
+    channel_tpl_id BIGINT       NOT NULL,                 -- 模板 id
+    name           VARCHAR(128) NOT NULL,                 -- 版本名称
+    signature      VARCHAR(64)  NOT NULL DEFAULT '',      -- 签名
+    content        TEXT         NOT NULL,                 -- 模板内容
+    remark         TEXT         NOT NULL DEFAULT '',      -- 备注
+    audit_id       BIGINT       NOT NULL DEFAULT 0,       -- 审核记录 id
+    auditor_id     BIGINT       NOT NULL DEFAULT 0,       -- 审核人 id
+    audit_at       BIGINT       NOT NULL DEFAULT 0,       -- 审核时间（毫秒）
+    audit_status   VARCHAR(16)  NOT NULL,                 -- 审核状态
+    reject_reason  VARCHAR(512) NOT NULL DEFAULT '',      -- 驳回原因
+    last_review_at BIGINT       NOT NULL DEFAULT 0,       -- 最近一次提审时间（毫秒）
+    created_at     BIGINT,
+    updated_at     BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_{{.Table}}_channel_tpl_id ON {{.Table}}(channel_tpl_id);
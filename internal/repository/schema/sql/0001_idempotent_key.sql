@@ -0,0 +1,8 @@
+-- 幂等键分表，分库分表规则与 notification 一致
+CREATE TABLE IF NOT EXISTS {{.Table}}
+(
+    biz_id     BIGINT       NOT NULL, -- 业务方 id
+    biz_key    VARCHAR(256) NOT NULL, -- 业务方唯一标识
+    created_at BIGINT,
+    PRIMARY KEY (biz_id, biz_key)
+);
@@ -0,0 +1,21 @@
+-- 通知分表，分库分表规则由 sharding.notification 配置
+CREATE TABLE IF NOT EXISTS {{.Table}}
+(
+    id             BIGINT PRIMARY KEY,                -- 雪花 id，内嵌分片哈希
+    biz_id         BIGINT       NOT NULL,             -- 业务方 id
+    biz_key        VARCHAR(256) NOT NULL,             -- 业务方唯一标识
+    receivers      JSONB        NOT NULL,             -- 接收者
+    channel        VARCHAR(16)  NOT NULL,             -- 渠道
+    tpl_id         BIGINT       NOT NULL,             -- 模板 id
+    tpl_version_id BIGINT       NOT NULL,             -- 模板版本 id
+    tpl_params     JSONB,                             -- 模板参数
+    status         VARCHAR(16)  NOT NULL,             -- 发送状态
+    schedule_start BIGINT       NOT NULL DEFAULT 0,   -- 计划发送开始时间（毫秒）
+    schedule_end   BIGINT       NOT NULL DEFAULT 0,   -- 计划发送结束时间（毫秒）
+    version        INTEGER      NOT NULL DEFAULT 1,   -- 乐观锁版本号
+    created_at     BIGINT,
+    updated_at     BIGINT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_{{.Table}}_biz_id_biz_key ON {{.Table}}(biz_id, biz_key);
+CREATE INDEX IF NOT EXISTS idx_{{.Table}}_status_schedule_start ON {{.Table}}(status, schedule_start);
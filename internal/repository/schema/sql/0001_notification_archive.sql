@@ -0,0 +1,20 @@
+-- 通知归档分表，与 notification 分表一一对应，保存超过保留期的终态通知
+CREATE TABLE IF NOT EXISTS {{.Table}}
+(
+    id             BIGINT PRIMARY KEY,
+    biz_id         BIGINT       NOT NULL,
+    biz_key        VARCHAR(256) NOT NULL,
+    receivers      JSONB        NOT NULL,
+    channel        VARCHAR(16)  NOT NULL,
+    tpl_id         BIGINT       NOT NULL,
+    tpl_version_id BIGINT       NOT NULL,
+    tpl_params     JSONB,
+    status         VARCHAR(16)  NOT NULL,
+    schedule_start BIGINT       NOT NULL DEFAULT 0,
+    schedule_end   BIGINT       NOT NULL DEFAULT 0,
+    version        INTEGER      NOT NULL DEFAULT 1,
+    created_at     BIGINT,
+    updated_at     BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_{{.Table}}_biz_id_biz_key ON {{.Table}}(biz_id, biz_key);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS {{.Table}}
+(
+    id         BIGSERIAL PRIMARY KEY,
+    biz_id     BIGINT      NOT NULL,            -- 业务方 id
+    channel    VARCHAR(16) NOT NULL,            -- 渠道
+    period     VARCHAR(16) NOT NULL,            -- 周期，d20250101 为日配额，m202501 为月配额
+    used       BIGINT      NOT NULL DEFAULT 0,  -- 已用配额，由 redis 计数定期同步
+    created_at BIGINT,
+    updated_at BIGINT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_{{.Table}}_biz_id_channel_period ON {{.Table}}(biz_id, channel, period);
@@ -0,0 +1,16 @@
+-- 事务通知分表，分库分表规则与 notification 一致
+CREATE TABLE IF NOT EXISTS {{.Table}}
+(
+    tx_id              BIGINT PRIMARY KEY,              -- 雪花 id
+    biz_id             BIGINT       NOT NULL,           -- 业务方 id
+    biz_key            VARCHAR(256) NOT NULL,           -- 业务方唯一标识
+    notification_id    BIGINT       NOT NULL,           -- 通知 id
+    status             VARCHAR(16)  NOT NULL,           -- 事务状态
+    checked_back_cnt   INTEGER      NOT NULL DEFAULT 0, -- 回查次数
+    next_check_back_at BIGINT       NOT NULL DEFAULT 0, -- 下次回查时间（毫秒）
+    created_at         BIGINT,
+    updated_at         BIGINT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_{{.Table}}_biz_id_biz_key ON {{.Table}}(biz_id, biz_key);
+CREATE INDEX IF NOT EXISTS idx_{{.Table}}_status_next_check_back_at ON {{.Table}}(status, next_check_back_at);
@@ -0,0 +1,2 @@
+-- 按创建时间清理过期的幂等键
+CREATE INDEX IF NOT EXISTS idx_{{.Table}}_created_at ON {{.Table}}(created_at);
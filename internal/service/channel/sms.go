@@ -1,11 +1,21 @@
 package channel
 
+import (
+	"github.com/JrMarcco/jotice/internal/service/provider"
+	"go.uber.org/zap"
+)
+
 type smsChannel struct {
 	baseChannel
 }
 
-func NewSMSChannel() Channel {
+func NewSMSChannel(providers []provider.Provider, throttler provider.Throttler, logger *zap.Logger) Channel {
 	return &smsChannel{
-		baseChannel: baseChannel{},
+		baseChannel: baseChannel{
+			providers: providers,
+			throttler: throttler,
+			maxWait:   defaultMaxWait,
+			logger:    logger,
+		},
 	}
 }
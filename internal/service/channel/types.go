@@ -2,16 +2,28 @@ package channel
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/JrMarcco/jotice/internal/domain"
 	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/service/provider"
+	"go.uber.org/zap"
 )
 
 type Channel interface {
 	Send(ctx context.Context, notification domain.Notification) (domain.SendResp, error)
 }
 
-var _ Channel = (*Dispatcher)(nil)
+// UsageReporter reports the usage of the providers behind a channel.
+type UsageReporter interface {
+	ProviderUsages(ctx context.Context) ([]domain.ProviderUsage, error)
+}
+
+var (
+	_ Channel       = (*Dispatcher)(nil)
+	_ UsageReporter = (*Dispatcher)(nil)
+)
 
 // Dispatcher is a channel dispatcher that chooses the appropriate channel based on the notification's channel configuration.
 // Is a dispatcher pattern implementation.
@@ -28,11 +40,118 @@ func (d *Dispatcher) Send(ctx context.Context, notification domain.Notification)
 	return ch.Send(ctx, notification)
 }
 
-var _ Channel = (*baseChannel)(nil)
+// ProviderUsages returns the usages of the providers of all the channels.
+func (d *Dispatcher) ProviderUsages(ctx context.Context) ([]domain.ProviderUsage, error) {
+	var usages []domain.ProviderUsage
+	for _, ch := range d.channels {
+		reporter, ok := ch.(UsageReporter)
+		if !ok {
+			continue
+		}
+
+		chUsages, err := reporter.ProviderUsages(ctx)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, chUsages...)
+	}
+	return usages, nil
+}
+
+// defaultMaxWait is the max duration to queue for a provider token.
+const defaultMaxWait = 200 * time.Millisecond
 
-type baseChannel struct{}
+var (
+	_ Channel       = (*baseChannel)(nil)
+	_ UsageReporter = (*baseChannel)(nil)
+)
+
+// baseChannel sends the notification through the first provider with a token available.
+// The providers are in priority order.
+type baseChannel struct {
+	providers []provider.Provider
+	throttler provider.Throttler
+	maxWait   time.Duration
+	logger    *zap.Logger
+}
 
 func (b *baseChannel) Send(ctx context.Context, notification domain.Notification) (domain.SendResp, error) {
-	//TODO implement me
-	panic("implement me")
+	p, err := b.acquire(ctx)
+	if err != nil {
+		return domain.SendResp{}, err
+	}
+
+	// the notification has reached the provider, the caller should tell it from the failures before.
+	resp, err := p.Send(ctx, notification)
+	if err != nil {
+		return resp, fmt.Errorf("%w, cause of: %w", errs.ErrProviderSendFailed, err)
+	}
+	return resp, nil
+}
+
+// ProviderUsages returns the usages of the providers of the channel today.
+func (b *baseChannel) ProviderUsages(ctx context.Context) ([]domain.ProviderUsage, error) {
+	usages := make([]domain.ProviderUsage, 0, len(b.providers))
+	for _, p := range b.providers {
+		usage, err := b.throttler.Usage(ctx, p.Info())
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+// acquire routes to the first active provider with a token available.
+// If no token is available, it queues for the provider with the shortest wait, but not longer than maxWait.
+func (b *baseChannel) acquire(ctx context.Context) (provider.Provider, error) {
+	deadline := time.Now().Add(b.maxWait)
+
+	// the providers denied the request, each one is counted once however many times the request retries.
+	throttled := make(map[uint64]domain.Provider)
+	defer b.countThrottled(ctx, throttled)
+
+	for {
+		minWait := time.Duration(-1)
+		for _, p := range b.providers {
+			info := p.Info()
+			if info.Status != domain.ProviderStatusActive {
+				continue
+			}
+
+			res, err := b.throttler.TryAcquire(ctx, info)
+			if err != nil {
+				// fail open, the throttler should not make the channel unavailable.
+				b.logger.Warn("[jotice] failed to acquire provider token", zap.Uint64("provider_id", info.Id), zap.Error(err))
+				return p, nil
+			}
+
+			if res.Acquired {
+				return p, nil
+			}
+			throttled[info.Id] = info
+
+			if !res.DailyExhausted && (minWait < 0 || res.Wait < minWait) {
+				minWait = res.Wait
+			}
+		}
+
+		if minWait < 0 || time.Now().Add(minWait).After(deadline) {
+			return nil, errs.ErrNoAvailableProvider
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(minWait):
+		}
+	}
+}
+
+func (b *baseChannel) countThrottled(ctx context.Context, throttled map[uint64]domain.Provider) {
+	for _, info := range throttled {
+		if err := b.throttler.Throttled(context.WithoutCancel(ctx), info); err != nil {
+			b.logger.Warn("[jotice] failed to count throttled request", zap.Uint64("provider_id", info.Id), zap.Error(err))
+		}
+	}
 }
@@ -0,0 +1,236 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/service/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeProvider struct {
+	info domain.Provider
+	err  error
+}
+
+func (f *fakeProvider) Info() domain.Provider {
+	return f.info
+}
+
+func (f *fakeProvider) Send(_ context.Context, n domain.Notification) (domain.SendResp, error) {
+	if f.err != nil {
+		return domain.SendResp{}, f.err
+	}
+	return domain.SendResp{Result: domain.SendResult{NotificationId: n.Id, Status: domain.SendStatusSuccess}}, nil
+}
+
+// fakeThrottler returns the results of each provider in order, the last one is repeated.
+type fakeThrottler struct {
+	mu        sync.Mutex
+	results   map[uint64][]provider.ThrottleResult
+	err       error
+	attempts  map[uint64]int
+	throttled map[uint64]int64
+}
+
+func newFakeThrottler(results map[uint64][]provider.ThrottleResult) *fakeThrottler {
+	return &fakeThrottler{
+		results:   results,
+		attempts:  make(map[uint64]int),
+		throttled: make(map[uint64]int64),
+	}
+}
+
+func (f *fakeThrottler) TryAcquire(_ context.Context, p domain.Provider) (provider.ThrottleResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return provider.ThrottleResult{}, f.err
+	}
+
+	results := f.results[p.Id]
+	res := results[min(f.attempts[p.Id], len(results)-1)]
+	f.attempts[p.Id]++
+	return res, nil
+}
+
+func (f *fakeThrottler) Throttled(_ context.Context, p domain.Provider) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.throttled[p.Id]++
+	return nil
+}
+
+func (f *fakeThrottler) Usage(_ context.Context, p domain.Provider) (domain.ProviderUsage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return domain.ProviderUsage{
+		ProviderId:     p.Id,
+		QpsLimit:       p.QpsLimit,
+		DailyLimit:     p.DailyLimit,
+		DailyUsed:      int64(f.attempts[p.Id]),
+		DailyThrottled: f.throttled[p.Id],
+	}, nil
+}
+
+func newTestChannel(throttler provider.Throttler, providers ...domain.Provider) *baseChannel {
+	ps := make([]provider.Provider, 0, len(providers))
+	for _, p := range providers {
+		ps = append(ps, &fakeProvider{info: p})
+	}
+	return &baseChannel{
+		providers: ps,
+		throttler: throttler,
+		maxWait:   50 * time.Millisecond,
+		logger:    zap.NewNop(),
+	}
+}
+
+func TestBaseChannel_Acquire(t *testing.T) {
+	t.Parallel()
+
+	active := func(id uint64) domain.Provider {
+		return domain.Provider{Id: id, QpsLimit: 10, DailyLimit: 100, Status: domain.ProviderStatusActive}
+	}
+	acquired := provider.ThrottleResult{Acquired: true}
+	wait := provider.ThrottleResult{Wait: 10 * time.Millisecond}
+	exhausted := provider.ThrottleResult{DailyExhausted: true}
+
+	tcs := []struct {
+		name          string
+		providers     []domain.Provider
+		results       map[uint64][]provider.ThrottleResult
+		wantId        uint64
+		wantErr       error
+		wantThrottled map[uint64]int64
+	}{
+		{
+			name:          "first provider",
+			providers:     []domain.Provider{active(1), active(2)},
+			results:       map[uint64][]provider.ThrottleResult{1: {acquired}, 2: {acquired}},
+			wantId:        1,
+			wantThrottled: map[uint64]int64{},
+		}, {
+			name: "skip inactive provider",
+			providers: []domain.Provider{
+				{Id: 1, QpsLimit: 10, DailyLimit: 100, Status: domain.ProviderStatusInactive}, active(2),
+			},
+			results:       map[uint64][]provider.ThrottleResult{1: {acquired}, 2: {acquired}},
+			wantId:        2,
+			wantThrottled: map[uint64]int64{},
+		}, {
+			name:          "fall back to next provider",
+			providers:     []domain.Provider{active(1), active(2)},
+			results:       map[uint64][]provider.ThrottleResult{1: {exhausted}, 2: {acquired}},
+			wantId:        2,
+			wantThrottled: map[uint64]int64{1: 1},
+		}, {
+			// the request retries 3 times but is counted once
+			name:          "wait for token",
+			providers:     []domain.Provider{active(1)},
+			results:       map[uint64][]provider.ThrottleResult{1: {wait, wait, acquired}},
+			wantId:        1,
+			wantThrottled: map[uint64]int64{1: 1},
+		}, {
+			name:          "wait longer than max wait",
+			providers:     []domain.Provider{active(1)},
+			results:       map[uint64][]provider.ThrottleResult{1: {{Wait: time.Second}}},
+			wantErr:       errs.ErrNoAvailableProvider,
+			wantThrottled: map[uint64]int64{1: 1},
+		}, {
+			name:          "all exhausted",
+			providers:     []domain.Provider{active(1), active(2)},
+			results:       map[uint64][]provider.ThrottleResult{1: {exhausted}, 2: {exhausted}},
+			wantErr:       errs.ErrNoAvailableProvider,
+			wantThrottled: map[uint64]int64{1: 1, 2: 1},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			throttler := newFakeThrottler(tc.results)
+			ch := newTestChannel(throttler, tc.providers...)
+
+			p, err := ch.acquire(t.Context())
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.wantThrottled, throttler.throttled)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantId, p.Info().Id)
+		})
+	}
+}
+
+func TestBaseChannel_AcquireFailOpen(t *testing.T) {
+	t.Parallel()
+
+	throttler := newFakeThrottler(nil)
+	throttler.err = errors.New("connection refused")
+	ch := newTestChannel(throttler, domain.Provider{Id: 1, Status: domain.ProviderStatusActive})
+
+	p, err := ch.acquire(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), p.Info().Id)
+}
+
+func TestBaseChannel_Send(t *testing.T) {
+	t.Parallel()
+
+	throttler := newFakeThrottler(map[uint64][]provider.ThrottleResult{1: {{DailyExhausted: true}}})
+	ch := newTestChannel(throttler, domain.Provider{Id: 1, Status: domain.ProviderStatusActive})
+
+	// the failure before reaching the provider is told from the failure of the provider
+	_, err := ch.Send(t.Context(), domain.Notification{Id: 1})
+	assert.ErrorIs(t, err, errs.ErrNoAvailableProvider)
+	assert.NotErrorIs(t, err, errs.ErrProviderSendFailed)
+
+	throttler = newFakeThrottler(map[uint64][]provider.ThrottleResult{1: {{Acquired: true}}})
+	ch = newTestChannel(throttler, domain.Provider{Id: 1, Status: domain.ProviderStatusActive})
+	providerErr := errors.New("gateway timeout")
+	ch.providers[0].(*fakeProvider).err = providerErr
+
+	_, err = ch.Send(t.Context(), domain.Notification{Id: 1})
+	assert.ErrorIs(t, err, errs.ErrProviderSendFailed)
+	assert.ErrorIs(t, err, providerErr)
+}
+
+func TestDispatcher_ProviderUsages(t *testing.T) {
+	t.Parallel()
+
+	throttler := newFakeThrottler(map[uint64][]provider.ThrottleResult{
+		1: {{Wait: time.Second}},
+		2: {{Acquired: true}},
+	})
+	d := &Dispatcher{
+		channels: map[domain.Channel]Channel{
+			domain.ChannelSMS: newTestChannel(
+				throttler,
+				domain.Provider{Id: 1, QpsLimit: 10, DailyLimit: 100, Status: domain.ProviderStatusActive},
+				domain.Provider{Id: 2, QpsLimit: 20, DailyLimit: 200, Status: domain.ProviderStatusActive},
+			),
+		},
+	}
+
+	_, err := d.Send(t.Context(), domain.Notification{Id: 1, Channel: domain.ChannelSMS})
+	require.NoError(t, err)
+
+	usages, err := d.ProviderUsages(t.Context())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []domain.ProviderUsage{
+		{ProviderId: 1, QpsLimit: 10, DailyLimit: 100, DailyUsed: 1, DailyThrottled: 1},
+		{ProviderId: 2, QpsLimit: 20, DailyLimit: 200, DailyUsed: 1},
+	}, usages)
+}
@@ -1,15 +1,126 @@
 package config
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
 	"github.com/JrMarcco/jotice/internal/repository"
 )
 
-type Service interface{}
+type Service interface {
+	// GetById get biz config by biz id.
+	GetById(ctx context.Context, id uint64) (domain.BizConfig, error)
+	// Save creates or updates the biz config after validating it and all its sub configs.
+	// Every save creates an immutable revision of the biz config.
+	Save(ctx context.Context, bizConfig domain.BizConfig, author string) (domain.BizConfigRevision, error)
+	// Delete deletes the biz config by biz id.
+	Delete(ctx context.Context, id uint64) error
+
+	// ListRevisions lists the revisions of the biz config, the latest first.
+	ListRevisions(ctx context.Context, bizId uint64, offset, limit int) ([]domain.BizConfigRevision, error)
+	// Diff returns the changed fields from revision fromVersion to revision toVersion.
+	Diff(ctx context.Context, bizId uint64, fromVersion, toVersion int32) ([]domain.BizConfigDiff, error)
+	// Rollback saves the biz config of an earlier revision as a new revision.
+	Rollback(ctx context.Context, bizId uint64, version int32, author string) (domain.BizConfigRevision, error)
+}
+
+var _ Service = (*DefaultBizConfigService)(nil)
 
 type DefaultBizConfigService struct {
 	repo repository.BizConfigRepo
 }
 
+func (s *DefaultBizConfigService) GetById(ctx context.Context, id uint64) (domain.BizConfig, error) {
+	if id <= 0 {
+		return domain.BizConfig{}, fmt.Errorf("%w: biz id should not be negative or zero", errs.ErrInvalidParam)
+	}
+	return s.repo.GetById(ctx, id)
+}
+
+func (s *DefaultBizConfigService) Save(
+	ctx context.Context, bizConfig domain.BizConfig, author string,
+) (domain.BizConfigRevision, error) {
+	if err := bizConfig.Validate(); err != nil {
+		return domain.BizConfigRevision{}, err
+	}
+
+	revision, err := s.repo.Save(ctx, bizConfig, author)
+	if err != nil {
+		return domain.BizConfigRevision{}, fmt.Errorf("failed to save biz config, cause of: %w", err)
+	}
+	return revision, nil
+}
+
+func (s *DefaultBizConfigService) Delete(ctx context.Context, id uint64) error {
+	if id <= 0 {
+		return fmt.Errorf("%w: biz id should not be negative or zero", errs.ErrInvalidParam)
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete biz config, cause of: %w", err)
+	}
+	return nil
+}
+
+func (s *DefaultBizConfigService) ListRevisions(
+	ctx context.Context, bizId uint64, offset, limit int,
+) ([]domain.BizConfigRevision, error) {
+	if bizId <= 0 {
+		return nil, fmt.Errorf("%w: biz id should not be negative or zero", errs.ErrInvalidParam)
+	}
+
+	if offset < 0 || limit <= 0 {
+		return nil, fmt.Errorf("%w: offset should not be negative and limit should be greater than 0", errs.ErrInvalidParam)
+	}
+	return s.repo.ListRevisions(ctx, bizId, offset, limit)
+}
+
+func (s *DefaultBizConfigService) Diff(
+	ctx context.Context, bizId uint64, fromVersion, toVersion int32,
+) ([]domain.BizConfigDiff, error) {
+	if bizId <= 0 {
+		return nil, fmt.Errorf("%w: biz id should not be negative or zero", errs.ErrInvalidParam)
+	}
+
+	from, err := s.repo.GetRevision(ctx, bizId, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.repo.GetRevision(ctx, bizId, toVersion)
+	if err != nil {
+		return nil, err
+	}
+	return from.Config.Diff(to.Config), nil
+}
+
+func (s *DefaultBizConfigService) Rollback(
+	ctx context.Context, bizId uint64, version int32, author string,
+) (domain.BizConfigRevision, error) {
+	if bizId <= 0 {
+		return domain.BizConfigRevision{}, fmt.Errorf("%w: biz id should not be negative or zero", errs.ErrInvalidParam)
+	}
+
+	target, err := s.repo.GetRevision(ctx, bizId, version)
+	if err != nil {
+		return domain.BizConfigRevision{}, err
+	}
+
+	// the revision was valid when it was saved, but the validation rules may have changed since then.
+	if err = target.Config.Validate(); err != nil {
+		return domain.BizConfigRevision{}, fmt.Errorf("revision %d can not be restored: %w", version, err)
+	}
+
+	// the rollback is a normal save, so the history stays append-only and the caches get invalidated.
+	revision, err := s.repo.Save(ctx, target.Config, author)
+	if err != nil {
+		return domain.BizConfigRevision{}, fmt.Errorf("failed to rollback biz config, cause of: %w", err)
+	}
+	return revision, nil
+}
+
 func NewDefaultBizConfigService(repo repository.BizConfigRepo) *DefaultBizConfigService {
 	return &DefaultBizConfigService{
 		repo: repo,
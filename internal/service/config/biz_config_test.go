@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memRevisionRepo keeps the revisions of a biz config in memory.
+type memRevisionRepo struct {
+	repository.BizConfigRepo
+	revisions []domain.BizConfigRevision
+}
+
+func (m *memRevisionRepo) Save(_ context.Context, bizConfig domain.BizConfig, author string) (domain.BizConfigRevision, error) {
+	revision := domain.BizConfigRevision{
+		BizId:   bizConfig.Id,
+		Version: int32(len(m.revisions) + 1),
+		Config:  bizConfig,
+		Author:  author,
+	}
+	m.revisions = append(m.revisions, revision)
+	return revision, nil
+}
+
+func (m *memRevisionRepo) GetRevision(_ context.Context, _ uint64, version int32) (domain.BizConfigRevision, error) {
+	if version <= 0 || int(version) > len(m.revisions) {
+		return domain.BizConfigRevision{}, errs.ErrBizConfigRevisionNotFound
+	}
+	return m.revisions[version-1], nil
+}
+
+func TestDefaultBizConfigService_Rollback(t *testing.T) {
+	t.Parallel()
+
+	repo := &memRevisionRepo{
+		revisions: []domain.BizConfigRevision{
+			{
+				BizId:   1,
+				Version: 1,
+				Config:  domain.BizConfig{Id: 1, OwnerId: 1, OwnerType: "organization", RateLimit: 100},
+			}, {
+				// saved before the owner id was required
+				BizId:   1,
+				Version: 2,
+				Config:  domain.BizConfig{Id: 1, OwnerType: "organization", RateLimit: 200},
+			}, {
+				BizId:   1,
+				Version: 3,
+				Config:  domain.BizConfig{Id: 1, OwnerId: 1, OwnerType: "organization", RateLimit: 300},
+			},
+		},
+	}
+	svc := NewDefaultBizConfigService(repo)
+
+	revision, err := svc.Rollback(t.Context(), 1, 1, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), revision.Version)
+	assert.Equal(t, int32(100), revision.Config.RateLimit)
+	assert.Equal(t, "admin", revision.Author)
+
+	_, err = svc.Rollback(t.Context(), 1, 2, "admin")
+	assert.ErrorIs(t, err, errs.ErrInvalidParam)
+	assert.Len(t, repo.revisions, 4)
+
+	_, err = svc.Rollback(t.Context(), 1, 10, "admin")
+	assert.ErrorIs(t, err, errs.ErrBizConfigRevisionNotFound)
+}
+
+func TestDefaultBizConfigService_Diff(t *testing.T) {
+	t.Parallel()
+
+	repo := &memRevisionRepo{
+		revisions: []domain.BizConfigRevision{
+			{BizId: 1, Version: 1, Config: domain.BizConfig{Id: 1, OwnerId: 1, RateLimit: 100}},
+			{BizId: 1, Version: 2, Config: domain.BizConfig{Id: 1, OwnerId: 1, RateLimit: 200}},
+		},
+	}
+	svc := NewDefaultBizConfigService(repo)
+
+	diffs, err := svc.Diff(t.Context(), 1, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.BizConfigDiff{{Field: "rate_limit", Old: "100", New: "200"}}, diffs)
+
+	_, err = svc.Diff(t.Context(), 1, 1, 3)
+	assert.ErrorIs(t, err, errs.ErrBizConfigRevisionNotFound)
+}
@@ -0,0 +1,54 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ArchiveTask is a background task that moves the notifications in terminal status
+// older than the retention to the archive tables periodically.
+type ArchiveTask struct {
+	repo      repository.NotificationRepo
+	retention time.Duration
+	batchSize int
+	interval  time.Duration
+	logger    *zap.Logger
+}
+
+func (t *ArchiveTask) Start(ctx context.Context) {
+	go t.loop(ctx)
+}
+
+func (t *ArchiveTask) loop(ctx context.Context) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cnt, err := t.repo.Archive(ctx, time.Now().Add(-t.retention), t.batchSize)
+			if err != nil {
+				t.logger.Error("[jotice] failed to archive notifications", zap.Int64("archived", cnt), zap.Error(err))
+				continue
+			}
+			t.logger.Info("[jotice] notifications archived", zap.Int64("archived", cnt))
+		}
+	}
+}
+
+func NewArchiveTask(
+	repo repository.NotificationRepo, retention time.Duration, batchSize int, interval time.Duration, logger *zap.Logger,
+) *ArchiveTask {
+	return &ArchiveTask{
+		repo:      repo,
+		retention: retention,
+		batchSize: batchSize,
+		interval:  interval,
+		logger:    logger,
+	}
+}
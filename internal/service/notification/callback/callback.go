@@ -1,37 +1,332 @@
 package callback
 
 import (
-	"github.com/JrMarcco/easy-kit/xsync"
-	clientv1 "github.com/JrMarcco/jotice-api/api/client/v1"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
 	"github.com/JrMarcco/jotice/internal/domain"
-	innergrpc "github.com/JrMarcco/jotice/internal/pkg/grpc"
+	"github.com/JrMarcco/jotice/internal/errs"
 	"github.com/JrMarcco/jotice/internal/repository"
 	"github.com/JrMarcco/jotice/internal/service/config"
-	"google.golang.org/grpc"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
 var _ Service = (*DefaultCallbackService)(nil)
 
+var errCallbackNotHandled = errors.New("business did not handle the callback")
+
 type Service interface {
+	// SendCallbackByNotification sends the send result of the notification to the business.
+	SendCallbackByNotification(ctx context.Context, n domain.Notification) error
+	// SendCallbackByNotifications batch sends the send results of the notifications to the business.
+	SendCallbackByNotifications(ctx context.Context, ns []domain.Notification) error
+	// SendPendingCallbacks resends a batch of the pending callbacks which should be retried before startTime,
+	// and the stale init callbacks whose notifications have finished.
+	// Returns the start id of the next batch, zero means there are no more pending callbacks.
+	SendPendingCallbacks(ctx context.Context, startTime int64, startId uint64, batchSize int) (uint64, error)
+
+	// GetCallbackLog gets the callback log with the last error and attempt history.
+	GetCallbackLog(ctx context.Context, id uint64) (domain.CallbackLog, error)
+	// ListFailedCallbacks lists the callbacks of the business which are failed in [startTime, endTime).
+	ListFailedCallbacks(ctx context.Context, bizId uint64, startTime, endTime int64, offset, limit int) ([]domain.CallbackLog, error)
+	// Replay replays the failed callbacks through the retry task, returns the number of callbacks replayed.
+	Replay(ctx context.Context, ids ...uint64) (int64, error)
+	// ReplayFailed replays all the callbacks of the business which are failed in [startTime, endTime).
+	ReplayFailed(ctx context.Context, bizId uint64, startTime, endTime int64) (int64, error)
 }
 
 type DefaultCallbackService struct {
-	configSvc     config.Service
-	bizIdToConfig xsync.Map[uint64, *domain.CallbackConfig]
-	clients       *innergrpc.Clients[clientv1.CallbackServiceClient]
-	repo          repository.CallbackLogRepo
+	configSvc config.Service
+	repo      repository.CallbackLogRepo
+	notifRepo repository.NotificationRepo
+
+	grpcTransport    Transport
+	webhookTransport Transport
+
+	logger *zap.Logger
+}
+
+func (s *DefaultCallbackService) SendCallbackByNotification(ctx context.Context, n domain.Notification) error {
+	return s.SendCallbackByNotifications(ctx, []domain.Notification{n})
+}
+
+func (s *DefaultCallbackService) SendCallbackByNotifications(ctx context.Context, ns []domain.Notification) error {
+	notifMap := make(map[uint64]domain.Notification, len(ns))
+	for _, n := range ns {
+		if !s.needCallback(ctx, n) {
+			continue
+		}
+		notifMap[n.Id] = n
+	}
+
+	if len(notifMap) == 0 {
+		return nil
+	}
+
+	logs, err := s.prepareLogs(ctx, notifMap)
+	if err != nil {
+		return err
+	}
+
+	s.sendAndSetStatusConcurrently(ctx, logs)
+	return s.repo.BatchUpdate(ctx, logs)
+}
+
+func (s *DefaultCallbackService) SendPendingCallbacks(
+	ctx context.Context, startTime int64, startId uint64, batchSize int,
+) (uint64, error) {
+	logs, nextStartId, err := s.repo.ListPendingBatch(ctx, startTime, startId, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pending callback logs, cause of: %w", err)
+	}
+
+	if len(logs) == 0 {
+		return nextStartId, nil
+	}
+
+	if err = s.fillNotifications(ctx, logs); err != nil {
+		return 0, err
+	}
+
+	// the init callback logs created with the notifications wait until their notifications finish.
+	logs = slices.DeleteFunc(logs, func(log domain.CallbackLog) bool {
+		return log.Status == domain.CallbackStatusInit && !log.Notification.Status.IsTerminal()
+	})
+	if len(logs) == 0 {
+		return nextStartId, nil
+	}
+
+	s.sendAndSetStatusConcurrently(ctx, logs)
+	if err = s.repo.BatchUpdate(ctx, logs); err != nil {
+		return 0, fmt.Errorf("failed to update callback logs, cause of: %w", err)
+	}
+	return nextStartId, nil
+}
+
+func (s *DefaultCallbackService) GetCallbackLog(ctx context.Context, id uint64) (domain.CallbackLog, error) {
+	return s.repo.GetById(ctx, id)
+}
+
+func (s *DefaultCallbackService) ListFailedCallbacks(
+	ctx context.Context, bizId uint64, startTime, endTime int64, offset, limit int,
+) ([]domain.CallbackLog, error) {
+	if err := s.validateRange(bizId, startTime, endTime); err != nil {
+		return nil, err
+	}
+
+	if offset < 0 || limit <= 0 {
+		return nil, fmt.Errorf("%w: offset should not be negative and limit should be greater than 0", errs.ErrInvalidParam)
+	}
+	return s.repo.ListFailed(ctx, bizId, startTime, endTime, offset, limit)
+}
+
+func (s *DefaultCallbackService) Replay(ctx context.Context, ids ...uint64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("%w: callback log ids should not be empty", errs.ErrInvalidParam)
+	}
+	return s.repo.Replay(ctx, ids)
+}
+
+func (s *DefaultCallbackService) ReplayFailed(ctx context.Context, bizId uint64, startTime, endTime int64) (int64, error) {
+	if err := s.validateRange(bizId, startTime, endTime); err != nil {
+		return 0, err
+	}
+	return s.repo.ReplayFailed(ctx, bizId, startTime, endTime)
+}
+
+func (s *DefaultCallbackService) validateRange(bizId uint64, startTime, endTime int64) error {
+	if bizId <= 0 {
+		return fmt.Errorf("%w: biz id should not be negative or zero", errs.ErrInvalidParam)
+	}
+
+	if startTime >= endTime {
+		return fmt.Errorf("%w: start time should be before end time", errs.ErrInvalidParam)
+	}
+	return nil
+}
+
+// fillNotifications loads the notifications of the callback logs by their ids,
+// which routes to the shard of the notification however old it is, and falls back to the archive.
+// The callback log will be marked as failed if its notification does not exist anymore.
+func (s *DefaultCallbackService) fillNotifications(ctx context.Context, logs []domain.CallbackLog) error {
+	ids := make([]uint64, 0, len(logs))
+	for _, log := range logs {
+		ids = append(ids, log.Notification.Id)
+	}
+
+	notifMap, err := s.notifRepo.BatchGetByIds(ctx, ids...)
+	if err != nil {
+		return fmt.Errorf("failed to get notifications of callback logs, cause of: %w", err)
+	}
+
+	for i := range logs {
+		n, ok := notifMap[logs[i].Notification.Id]
+		if !ok {
+			logs[i].Status = domain.CallbackStatusFailed
+			continue
+		}
+		logs[i].Notification = n
+	}
+	return nil
+}
+
+func (s *DefaultCallbackService) sendAndSetStatusConcurrently(ctx context.Context, logs []domain.CallbackLog) {
+	var eg errgroup.Group
+	for i := range logs {
+		if logs[i].Status == domain.CallbackStatusFailed {
+			continue
+		}
+
+		eg.Go(func() error {
+			s.sendAndSetStatus(ctx, &logs[i])
+			return nil
+		})
+	}
+	_ = eg.Wait()
+}
+
+// needCallback checks if the notification is in terminal state and its business has configured the callback.
+func (s *DefaultCallbackService) needCallback(ctx context.Context, n domain.Notification) bool {
+	if !n.Status.IsTerminal() {
+		return false
+	}
+
+	cbConfig, err := s.getConfig(ctx, n.BizId)
+	if err != nil {
+		s.logger.Warn("[jotice] failed to get callback config",
+			zap.Uint64("biz_id", n.BizId),
+			zap.Error(err),
+		)
+		return false
+	}
+	return cbConfig != nil
+}
+
+// prepareLogs creates the callback logs in init status for the notifications without callback logs,
+// and returns the callback logs which are not finished yet.
+func (s *DefaultCallbackService) prepareLogs(
+	ctx context.Context, notifMap map[uint64]domain.Notification,
+) ([]domain.CallbackLog, error) {
+	ids := make([]uint64, 0, len(notifMap))
+	for id := range notifMap {
+		ids = append(ids, id)
+	}
+
+	existLogs, err := s.repo.ListByNotificationIds(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list callback logs, cause of: %w", err)
+	}
+
+	existIds := make(map[uint64]struct{}, len(existLogs))
+	for _, log := range existLogs {
+		existIds[log.Notification.Id] = struct{}{}
+	}
+
+	initLogs := make([]domain.CallbackLog, 0, len(notifMap)-len(existLogs))
+	for id, n := range notifMap {
+		if _, ok := existIds[id]; ok {
+			continue
+		}
+		initLogs = append(initLogs, domain.CallbackLog{
+			Notification: n,
+			Status:       domain.CallbackStatusInit,
+		})
+	}
+
+	if len(initLogs) > 0 {
+		if err = s.repo.BatchCreate(ctx, initLogs); err != nil {
+			return nil, fmt.Errorf("failed to create callback logs, cause of: %w", err)
+		}
+
+		// reload to get the ids of the created callback logs
+		if existLogs, err = s.repo.ListByNotificationIds(ctx, ids); err != nil {
+			return nil, fmt.Errorf("failed to list callback logs, cause of: %w", err)
+		}
+	}
+
+	logs := make([]domain.CallbackLog, 0, len(existLogs))
+	for _, log := range existLogs {
+		if log.Status == domain.CallbackStatusSucceed || log.Status == domain.CallbackStatusFailed {
+			continue
+		}
+		log.Notification = notifMap[log.Notification.Id]
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+// sendAndSetStatus sends the callback and sets the status of the callback log by the result.
+func (s *DefaultCallbackService) sendAndSetStatus(ctx context.Context, log *domain.CallbackLog) {
+	cbConfig, err := s.getConfig(ctx, log.Notification.BizId)
+	if err != nil {
+		// keep the callback log unchanged and wait for the next time
+		return
+	}
+
+	if cbConfig == nil {
+		// the business has removed the callback config
+		log.Status = domain.CallbackStatusFailed
+		return
+	}
+
+	ok, err := s.sendCallback(ctx, cbConfig, log.Notification)
+	if ok {
+		log.RecordAttempt(nil)
+		log.Status = domain.CallbackStatusSucceed
+		return
+	}
+
+	if err == nil {
+		err = errCallbackNotHandled
+	}
+	s.logger.Warn("[jotice] failed to send callback",
+		zap.Uint64("notification_id", log.Notification.Id),
+		zap.Error(err),
+	)
+
+	log.RecordAttempt(err)
+	log.SetNextRetryAtAndStatus(cbConfig)
+}
+
+func (s *DefaultCallbackService) sendCallback(
+	ctx context.Context, cbConfig *domain.CallbackConfig, n domain.Notification,
+) (bool, error) {
+	if cbConfig.IsWebhook() {
+		return s.webhookTransport.Send(ctx, cbConfig, n)
+	}
+	return s.grpcTransport.Send(ctx, cbConfig, n)
+}
+
+// getConfig gets the callback config of the business.
+// Returns nil if the business has not configured the callback.
+func (s *DefaultCallbackService) getConfig(ctx context.Context, bizId uint64) (*domain.CallbackConfig, error) {
+	bizConfig, err := s.configSvc.GetById(ctx, bizId)
+	if err != nil {
+		if errors.Is(err, errs.ErrBizConfigNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return bizConfig.CallbackConfig, nil
 }
 
 func NewDefaultCallbackService(
 	configSvc config.Service,
 	repo repository.CallbackLogRepo,
+	notifRepo repository.NotificationRepo,
+	logger *zap.Logger,
 ) *DefaultCallbackService {
 	return &DefaultCallbackService{
-		configSvc:     configSvc,
-		bizIdToConfig: xsync.Map[uint64, *domain.CallbackConfig]{},
-		repo:          repo,
-		clients: innergrpc.NewClients(func(conn *grpc.ClientConn) clientv1.CallbackServiceClient {
-			return clientv1.NewCallbackServiceClient(conn)
-		}),
+		configSvc: configSvc,
+		repo:      repo,
+		notifRepo: notifRepo,
+		logger:    logger,
+
+		grpcTransport:    NewGrpcTransport(),
+		webhookTransport: NewWebhookTransport(&http.Client{}),
 	}
 }
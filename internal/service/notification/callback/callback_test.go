@@ -0,0 +1,263 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/retry"
+	"github.com/JrMarcco/jotice/internal/repository"
+	"github.com/JrMarcco/jotice/internal/service/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var errFakeTransport = errors.New("fake transport")
+
+// fakeConfigSvc returns the biz configs in memory, the other methods are not used.
+type fakeConfigSvc struct {
+	config.Service
+	configs map[uint64]domain.BizConfig
+}
+
+func (f *fakeConfigSvc) GetById(_ context.Context, id uint64) (domain.BizConfig, error) {
+	bc, ok := f.configs[id]
+	if !ok {
+		return domain.BizConfig{}, errs.ErrBizConfigNotFound
+	}
+	return bc, nil
+}
+
+// fakeCbLogRepo keeps the callback logs in memory keyed by notification id.
+type fakeCbLogRepo struct {
+	repository.CallbackLogRepo
+
+	mu      sync.Mutex
+	nextId  uint64
+	logs    map[uint64]domain.CallbackLog
+	pending []domain.CallbackLog
+}
+
+func (f *fakeCbLogRepo) BatchCreate(_ context.Context, logs []domain.CallbackLog) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, log := range logs {
+		f.nextId++
+		log.Id = f.nextId
+		f.logs[log.Notification.Id] = log
+	}
+	return nil
+}
+
+func (f *fakeCbLogRepo) BatchUpdate(_ context.Context, logs []domain.CallbackLog) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, log := range logs {
+		f.logs[log.Notification.Id] = log
+	}
+	return nil
+}
+
+func (f *fakeCbLogRepo) ListByNotificationIds(_ context.Context, ids []uint64) ([]domain.CallbackLog, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	res := make([]domain.CallbackLog, 0, len(ids))
+	for _, id := range ids {
+		if log, ok := f.logs[id]; ok {
+			res = append(res, log)
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeCbLogRepo) Replay(_ context.Context, ids []uint64) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var replayed int64
+	for notifId, log := range f.logs {
+		if log.Status == domain.CallbackStatusFailed && slices.Contains(ids, log.Id) {
+			log.Status = domain.CallbackStatusPending
+			log.RetryTimes = 0
+			f.logs[notifId] = log
+			replayed++
+		}
+	}
+	return replayed, nil
+}
+
+func (f *fakeCbLogRepo) ListPendingBatch(
+	_ context.Context, _ int64, _ uint64, _ int,
+) ([]domain.CallbackLog, uint64, error) {
+	return f.pending, 0, nil
+}
+
+// fakeNotifRepo returns the notifications in memory, the other methods are not used.
+type fakeNotifRepo struct {
+	repository.NotificationRepo
+	ns []domain.Notification
+}
+
+func (f *fakeNotifRepo) BatchGetByIds(_ context.Context, ids ...uint64) (map[uint64]domain.Notification, error) {
+	res := make(map[uint64]domain.Notification, len(ids))
+	for _, n := range f.ns {
+		if slices.Contains(ids, n.Id) {
+			res[n.Id] = n
+		}
+	}
+	return res, nil
+}
+
+// fakeTransport records the notifications sent and fails the ones in fails.
+type fakeTransport struct {
+	mu    sync.Mutex
+	sent  []uint64
+	fails map[uint64]bool
+}
+
+func (f *fakeTransport) Send(_ context.Context, _ *domain.CallbackConfig, n domain.Notification) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sent = append(f.sent, n.Id)
+	if f.fails[n.Id] {
+		return false, errFakeTransport
+	}
+	return true, nil
+}
+
+func newTestCallbackService(transport *fakeTransport, notifRepo *fakeNotifRepo) (*DefaultCallbackService, *fakeCbLogRepo) {
+	repo := &fakeCbLogRepo{logs: make(map[uint64]domain.CallbackLog)}
+	configSvc := &fakeConfigSvc{
+		configs: map[uint64]domain.BizConfig{
+			1: {
+				Id: 1,
+				CallbackConfig: &domain.CallbackConfig{
+					ServiceName: "test_service",
+					RetryPolicy: &retry.Config{
+						Type:          retry.TypeFixedInterval,
+						FixedInterval: &retry.FixedIntervalConfig{Interval: time.Second, MaxTimes: 3},
+					},
+				},
+			},
+			// biz 2 has not configured the callback
+			2: {Id: 2},
+		},
+	}
+
+	svc := NewDefaultCallbackService(configSvc, repo, notifRepo, zap.NewNop())
+	svc.grpcTransport = transport
+	svc.webhookTransport = transport
+	return svc, repo
+}
+
+func TestDefaultCallbackService_SendCallbackByNotifications(t *testing.T) {
+	t.Parallel()
+
+	transport := &fakeTransport{fails: map[uint64]bool{3: true}}
+	svc, repo := newTestCallbackService(transport, &fakeNotifRepo{})
+
+	err := svc.SendCallbackByNotifications(t.Context(), []domain.Notification{
+		{Id: 1, BizId: 1, BizKey: "succeed", Status: domain.SendStatusSuccess},
+		{Id: 2, BizId: 1, BizKey: "canceled", Status: domain.SendStatusCanceled},
+		{Id: 3, BizId: 1, BizKey: "transport_failed", Status: domain.SendStatusFailed},
+		{Id: 4, BizId: 1, BizKey: "not_terminal", Status: domain.SendStatusPending},
+		{Id: 5, BizId: 2, BizKey: "no_callback_config", Status: domain.SendStatusSuccess},
+	})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []uint64{1, 2, 3}, transport.sent)
+	require.Len(t, repo.logs, 3)
+
+	assert.Equal(t, domain.CallbackStatusSucceed, repo.logs[1].Status)
+	assert.Equal(t, domain.CallbackStatusSucceed, repo.logs[2].Status)
+
+	failed := repo.logs[3]
+	assert.Equal(t, domain.CallbackStatusPending, failed.Status)
+	assert.Equal(t, int32(1), failed.RetryTimes)
+	assert.Positive(t, failed.NextRetryAt)
+	assert.Equal(t, errFakeTransport.Error(), failed.LastError)
+	require.Len(t, failed.Attempts, 1)
+	assert.False(t, failed.Attempts[0].Succeed)
+
+	// the finished callbacks are not sent again
+	transport.sent = nil
+	err = svc.SendCallbackByNotifications(t.Context(), []domain.Notification{
+		{Id: 1, BizId: 1, BizKey: "succeed", Status: domain.SendStatusSuccess},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, transport.sent)
+}
+
+func TestDefaultCallbackService_SendPendingCallbacks(t *testing.T) {
+	t.Parallel()
+
+	transport := &fakeTransport{}
+	svc, repo := newTestCallbackService(transport, &fakeNotifRepo{
+		ns: []domain.Notification{
+			{Id: 1, BizId: 1, BizKey: "exists", Status: domain.SendStatusSuccess},
+			{Id: 3, BizId: 1, BizKey: "sending", Status: domain.SendStatusSending},
+		},
+	})
+	repo.pending = []domain.CallbackLog{
+		{
+			Id:           1,
+			Notification: domain.Notification{Id: 1, BizId: 1, BizKey: "exists"},
+			Status:       domain.CallbackStatusPending,
+			RetryTimes:   1,
+		}, {
+			Id:           2,
+			Notification: domain.Notification{Id: 2, BizId: 1, BizKey: "removed"},
+			Status:       domain.CallbackStatusInit,
+		}, {
+			Id:           3,
+			Notification: domain.Notification{Id: 3, BizId: 1, BizKey: "sending"},
+			Status:       domain.CallbackStatusInit,
+		},
+	}
+
+	nextStartId, err := svc.SendPendingCallbacks(t.Context(), time.Now().UnixMilli(), 0, 10)
+	require.NoError(t, err)
+	assert.Zero(t, nextStartId)
+
+	assert.Equal(t, []uint64{1}, transport.sent)
+	assert.Equal(t, domain.CallbackStatusSucceed, repo.logs[1].Status)
+	assert.Equal(t, domain.SendStatusSuccess, repo.logs[1].Notification.Status)
+	// the notification of the callback log does not exist anymore
+	assert.Equal(t, domain.CallbackStatusFailed, repo.logs[2].Status)
+	// the init callback log waits until its notification finishes
+	assert.NotContains(t, repo.logs, uint64(3))
+}
+
+func TestDefaultCallbackService_Replay(t *testing.T) {
+	t.Parallel()
+
+	svc, repo := newTestCallbackService(&fakeTransport{}, &fakeNotifRepo{})
+	repo.logs[1] = domain.CallbackLog{Id: 1, Status: domain.CallbackStatusFailed, RetryTimes: 3}
+	repo.logs[2] = domain.CallbackLog{Id: 2, Status: domain.CallbackStatusSucceed}
+
+	replayed, err := svc.Replay(t.Context(), 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), replayed)
+	assert.Equal(t, domain.CallbackStatusPending, repo.logs[1].Status)
+	assert.Zero(t, repo.logs[1].RetryTimes)
+	assert.Equal(t, domain.CallbackStatusSucceed, repo.logs[2].Status)
+
+	_, err = svc.Replay(t.Context())
+	assert.ErrorIs(t, err, errs.ErrInvalidParam)
+
+	_, err = svc.ReplayFailed(t.Context(), 1, 2, 1)
+	assert.ErrorIs(t, err, errs.ErrInvalidParam)
+
+	_, err = svc.ListFailedCallbacks(t.Context(), 1, 1, 2, 0, 0)
+	assert.ErrorIs(t, err, errs.ErrInvalidParam)
+}
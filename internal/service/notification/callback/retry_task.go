@@ -0,0 +1,79 @@
+package callback
+
+import (
+	"context"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/pkg/batch"
+	"go.uber.org/zap"
+)
+
+// RetryTask is a background task that resends the pending callbacks.
+// The batch size is adjusted by the response time of each batch.
+type RetryTask struct {
+	svc      Service
+	adjuster batch.Adjuster
+
+	batchSize int
+	interval  time.Duration
+
+	logger *zap.Logger
+}
+
+func (t *RetryTask) Start(ctx context.Context) {
+	go t.loop(ctx)
+}
+
+func (t *RetryTask) loop(ctx context.Context) {
+	var startId uint64
+	var startTime int64
+	batchSize := t.batchSize
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// start a new round, the start time is fixed in the round for keyset pagination
+		if startId == 0 {
+			startTime = time.Now().UnixMilli()
+		}
+
+		begin := time.Now()
+		nextStartId, err := t.svc.SendPendingCallbacks(ctx, startTime, startId, batchSize)
+		if err != nil {
+			t.logger.Error("[jotice] failed to send pending callbacks", zap.Uint64("start_id", startId), zap.Error(err))
+			t.sleep(ctx)
+			continue
+		}
+
+		if size, err := t.adjuster.Adjust(ctx, time.Since(begin)); err == nil {
+			batchSize = size
+		}
+
+		startId = nextStartId
+		if startId == 0 {
+			// no more pending callbacks in this round
+			t.sleep(ctx)
+		}
+	}
+}
+
+func (t *RetryTask) sleep(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(t.interval):
+	}
+}
+
+func NewRetryTask(
+	svc Service, adjuster batch.Adjuster, batchSize int, interval time.Duration, logger *zap.Logger,
+) *RetryTask {
+	return &RetryTask{
+		svc:       svc,
+		adjuster:  adjuster,
+		batchSize: batchSize,
+		interval:  interval,
+		logger:    logger,
+	}
+}
@@ -0,0 +1,117 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type pendingCall struct {
+	startTime int64
+	startId   uint64
+	batchSize int
+}
+
+// fakePendingSvc returns the next start ids in order, then zero for the end of the round.
+type fakePendingSvc struct {
+	Service
+
+	mu      sync.Mutex
+	calls   []pendingCall
+	nextIds []uint64
+	errs    []error
+}
+
+func (f *fakePendingSvc) SendPendingCallbacks(
+	_ context.Context, startTime int64, startId uint64, batchSize int,
+) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, pendingCall{startTime: startTime, startId: startId, batchSize: batchSize})
+
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if len(f.nextIds) == 0 {
+		return 0, nil
+	}
+	next := f.nextIds[0]
+	f.nextIds = f.nextIds[1:]
+	return next, nil
+}
+
+func (f *fakePendingSvc) getCalls() []pendingCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]pendingCall(nil), f.calls...)
+}
+
+// fakeAdjuster increases the batch size by one for each batch.
+type fakeAdjuster struct {
+	size int
+}
+
+func (f *fakeAdjuster) Adjust(_ context.Context, _ time.Duration) (int, error) {
+	f.size++
+	return f.size, nil
+}
+
+func TestRetryTask_Start(t *testing.T) {
+	t.Parallel()
+
+	svc := &fakePendingSvc{nextIds: []uint64{10, 20, 0}}
+	task := NewRetryTask(svc, &fakeAdjuster{size: 100}, 100, time.Hour, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	task.Start(ctx)
+
+	// the round ends after the third batch, then the task sleeps for the interval
+	assert.Eventually(t, func() bool {
+		return len(svc.getCalls()) == 3
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+
+	calls := svc.getCalls()
+	assert.Equal(t, []uint64{0, 10, 20}, []uint64{calls[0].startId, calls[1].startId, calls[2].startId})
+	assert.Equal(t, []int{100, 101, 102}, []int{calls[0].batchSize, calls[1].batchSize, calls[2].batchSize})
+
+	// the start time is fixed in a round for the keyset pagination
+	assert.Equal(t, calls[0].startTime, calls[1].startTime)
+	assert.Equal(t, calls[0].startTime, calls[2].startTime)
+}
+
+func TestRetryTask_RetryFailedBatch(t *testing.T) {
+	t.Parallel()
+
+	svc := &fakePendingSvc{
+		nextIds: []uint64{10},
+		errs:    []error{nil, errors.New("fake error")},
+	}
+	task := NewRetryTask(svc, &fakeAdjuster{size: 100}, 100, 10*time.Millisecond, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	task.Start(ctx)
+
+	assert.Eventually(t, func() bool {
+		return len(svc.getCalls()) >= 3
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+
+	// the failed batch is retried from the same start id
+	calls := svc.getCalls()
+	assert.Equal(t, uint64(10), calls[1].startId)
+	assert.Equal(t, uint64(10), calls[2].startId)
+}
@@ -0,0 +1,92 @@
+package callback
+
+import (
+	"context"
+	"strconv"
+
+	clientv1 "github.com/JrMarcco/jotice-api/api/client/v1"
+	notificationv1 "github.com/JrMarcco/jotice-api/api/notification/v1"
+	"github.com/JrMarcco/jotice/internal/domain"
+	innergrpc "github.com/JrMarcco/jotice/internal/pkg/grpc"
+	"google.golang.org/grpc"
+)
+
+// Transport delivers the send result of the notification to the business.
+// Returns true if the business has handled the result, otherwise the callback should be retried.
+type Transport interface {
+	Send(ctx context.Context, cbConfig *domain.CallbackConfig, n domain.Notification) (bool, error)
+}
+
+var _ Transport = (*GrpcTransport)(nil)
+
+// GrpcTransport delivers the result through clientv1.CallbackServiceClient,
+// the client is looked up by the service name in the callback config.
+type GrpcTransport struct {
+	clients *innergrpc.Clients[clientv1.CallbackServiceClient]
+}
+
+func (t *GrpcTransport) Send(ctx context.Context, cbConfig *domain.CallbackConfig, n domain.Notification) (bool, error) {
+	client, err := t.clients.Get(cbConfig.ServiceName)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := client.HandleNotificationResult(ctx, t.buildRequest(n))
+	if err != nil {
+		return false, err
+	}
+	return resp.Success, nil
+}
+
+func (t *GrpcTransport) buildRequest(n domain.Notification) *clientv1.HandleNotificationResultRequest {
+	return &clientv1.HandleNotificationResultRequest{
+		NotificationId: n.Id,
+		OriginalRequest: &notificationv1.SendNotificationRequest{
+			Notification: &notificationv1.Notification{
+				Key:        n.BizKey,
+				Receivers:  n.Receivers,
+				Channel:    t.apiChannel(n.Channel),
+				TempId:     strconv.FormatUint(n.Template.Id, 10),
+				TempParams: n.Template.Params,
+			},
+		},
+		Result: &notificationv1.SendNotificationResponse{
+			NotificationId: n.Id,
+			Status:         t.apiStatus(n.Status),
+		},
+	}
+}
+
+func (t *GrpcTransport) apiChannel(channel domain.Channel) notificationv1.Channel {
+	switch channel {
+	case domain.ChannelSMS:
+		return notificationv1.Channel_SMS
+	case domain.ChannelEmail:
+		return notificationv1.Channel_EMAIL
+	case domain.ChannelApp:
+		return notificationv1.Channel_IN_APP
+	default:
+		return notificationv1.Channel_CHANNEL_UNSPECIFIED
+	}
+}
+
+func (t *GrpcTransport) apiStatus(status domain.SendStatus) notificationv1.SendStatus {
+	switch status {
+	case domain.SendStatusSuccess:
+		return notificationv1.SendStatus_SUCCEEDED
+	case domain.SendStatusFailed:
+		return notificationv1.SendStatus_FAILED
+	case domain.SendStatusCanceled:
+		return notificationv1.SendStatus_CANCELED
+	default:
+		return notificationv1.SendStatus_STATUS_UNSPECIFIED
+	}
+}
+
+func NewGrpcTransport() *GrpcTransport {
+	return &GrpcTransport{
+		clients: innergrpc.NewClients(func(conn *grpc.ClientConn) clientv1.CallbackServiceClient {
+			return clientv1.NewCallbackServiceClient(conn)
+		}),
+	}
+}
@@ -0,0 +1,42 @@
+package callback
+
+import (
+	"testing"
+
+	notificationv1 "github.com/JrMarcco/jotice-api/api/notification/v1"
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrpcTransport_BuildRequest(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name   string
+		status domain.SendStatus
+		want   notificationv1.SendStatus
+	}{
+		{name: "succeed", status: domain.SendStatusSuccess, want: notificationv1.SendStatus_SUCCEEDED},
+		{name: "failed", status: domain.SendStatusFailed, want: notificationv1.SendStatus_FAILED},
+		{name: "canceled", status: domain.SendStatusCanceled, want: notificationv1.SendStatus_CANCELED},
+		{name: "not terminal", status: domain.SendStatusSending, want: notificationv1.SendStatus_STATUS_UNSPECIFIED},
+	}
+
+	transport := NewGrpcTransport()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := transport.buildRequest(domain.Notification{
+				Id:      1,
+				BizKey:  "biz_key",
+				Channel: domain.ChannelSMS,
+				Status:  tc.status,
+			})
+			assert.Equal(t, uint64(1), req.NotificationId)
+			assert.Equal(t, uint64(1), req.Result.NotificationId)
+			assert.Equal(t, tc.want, req.Result.Status)
+			assert.Equal(t, notificationv1.Channel_SMS, req.OriginalRequest.Notification.Channel)
+		})
+	}
+}
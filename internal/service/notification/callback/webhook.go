@@ -0,0 +1,105 @@
+package callback
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+)
+
+const (
+	HeaderTimestamp = "X-Jotice-Timestamp"
+	HeaderSignature = "X-Jotice-Signature"
+
+	defaultWebhookTimeout = 3 * time.Second
+)
+
+// WebhookPayload is the json body posted to the webhook of the business.
+type WebhookPayload struct {
+	NotificationId uint64 `json:"notification_id"`
+	BizId          uint64 `json:"biz_id"`
+	BizKey         string `json:"biz_key"`
+	Channel        string `json:"channel"`
+	Status         string `json:"status"`
+}
+
+var _ Transport = (*WebhookTransport)(nil)
+
+// WebhookTransport delivers the result by posting a signed json to the webhook url in the callback config.
+//
+// The request carries two headers for the business to verify:
+//   - X-Jotice-Timestamp: milliseconds of the sending time, the business should reject the stale requests to prevent replay.
+//   - X-Jotice-Signature: hex encoded HMAC-SHA256 of "{timestamp}.{body}" signed with the webhook secret.
+//
+// Any 2xx response is treated as success, everything else is retryable.
+type WebhookTransport struct {
+	client *http.Client
+}
+
+func (t *WebhookTransport) Send(ctx context.Context, cbConfig *domain.CallbackConfig, n domain.Notification) (bool, error) {
+	webhook := cbConfig.Webhook
+
+	body, err := json.Marshal(WebhookPayload{
+		NotificationId: n.Id,
+		BizId:          n.BizId,
+		BizKey:         n.BizKey,
+		Channel:        n.Channel.String(),
+		Status:         n.Status.String(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal webhook payload, cause of: %w", err)
+	}
+
+	timeout := webhook.Timeout()
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook request, cause of: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return false, fmt.Errorf("webhook responded with status code %d", resp.StatusCode)
+	}
+	return true, nil
+}
+
+// Sign returns the signature of the webhook request.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func NewWebhookTransport(client *http.Client) *WebhookTransport {
+	return &WebhookTransport{
+		client: client,
+	}
+}
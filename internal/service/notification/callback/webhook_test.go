@@ -0,0 +1,116 @@
+package callback
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookTransport_Send(t *testing.T) {
+	t.Parallel()
+
+	const secret = "test_secret"
+
+	tcs := []struct {
+		name       string
+		statusCode int
+		wantOk     bool
+		wantErr    bool
+	}{
+		{
+			name:       "ok",
+			statusCode: http.StatusOK,
+			wantOk:     true,
+		}, {
+			name:       "no content",
+			statusCode: http.StatusNoContent,
+			wantOk:     true,
+		}, {
+			name:       "redirect",
+			statusCode: http.StatusFound,
+			wantOk:     false,
+			wantErr:    true,
+		}, {
+			name:       "server error",
+			statusCode: http.StatusInternalServerError,
+			wantOk:     false,
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				timestamp := r.Header.Get(HeaderTimestamp)
+				assert.NotEmpty(t, timestamp)
+				assert.Equal(t, Sign(secret, timestamp, body), r.Header.Get(HeaderSignature))
+
+				var payload WebhookPayload
+				require.NoError(t, json.Unmarshal(body, &payload))
+				assert.Equal(t, uint64(1), payload.NotificationId)
+				assert.Equal(t, domain.SendStatusSuccess.String(), payload.Status)
+
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer svr.Close()
+
+			transport := NewWebhookTransport(svr.Client())
+			ok, err := transport.Send(t.Context(), &domain.CallbackConfig{
+				Webhook: &domain.WebhookConfig{
+					Url:    svr.URL,
+					Secret: secret,
+				},
+			}, domain.Notification{
+				Id:      1,
+				BizId:   2025,
+				BizKey:  "test_biz_key",
+				Channel: domain.ChannelSMS,
+				Status:  domain.SendStatusSuccess,
+			})
+
+			assert.Equal(t, tc.wantOk, ok)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestWebhookTransport_Timeout(t *testing.T) {
+	t.Parallel()
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer svr.Close()
+
+	var webhook domain.WebhookConfig
+	require.NoError(t, json.Unmarshal(
+		[]byte(`{"url":"`+svr.URL+`","secret":"test_secret","timeout_millis":50}`), &webhook,
+	))
+	assert.Equal(t, 50*time.Millisecond, webhook.Timeout())
+
+	transport := NewWebhookTransport(svr.Client())
+	ok, err := transport.Send(t.Context(), &domain.CallbackConfig{Webhook: &webhook}, domain.Notification{Id: 1})
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -2,10 +2,14 @@ package notification
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/JrMarcco/jotice/internal/domain"
 	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
 	"github.com/JrMarcco/jotice/internal/repository"
 )
 
@@ -13,20 +17,47 @@ import (
 type Service interface {
 	// FindReadyNotifications find notifications that are ready to be schedule to send.
 	FindReadyNotifications(ctx context.Context, offset, limit int) ([]domain.Notification, error)
+	// GetById gets the notification by the id returned by the send apis.
+	GetById(ctx context.Context, id uint64) (domain.Notification, error)
+	// BatchGetByIds gets notifications by ids, the ids not found are absent in the result.
+	BatchGetByIds(ctx context.Context, ids ...uint64) (map[uint64]domain.Notification, error)
 	// GetByBizKeys get notifications by biz id and biz keys.
 	GetByBizKeys(ctx context.Context, BizId uint64, bizKeys ...string) ([]domain.Notification, error)
+	// Inspect decodes the notification id and fetches the notification from the shard it is routed to,
+	// the table is the one the notification is read from, which is the archive table if it has been archived.
+	Inspect(ctx context.Context, id uint64) (domain.IdInspection, error)
 }
 
 var _ Service = (*DefaultNotifService)(nil)
 
 type DefaultNotifService struct {
-	repo repository.NotificationRepo
+	repo             repository.NotificationRepo
+	shardingStrategy sharding.Strategy
 }
 
 func (d *DefaultNotifService) FindReadyNotifications(ctx context.Context, offset, limit int) ([]domain.Notification, error) {
 	return d.repo.FindDreadyNotifications(ctx, offset, limit)
 }
 
+func (d *DefaultNotifService) GetById(ctx context.Context, id uint64) (domain.Notification, error) {
+	if id == 0 {
+		return domain.Notification{}, fmt.Errorf("%w: notification id should not be zero", errs.ErrInvalidParam)
+	}
+	return d.repo.GetById(ctx, id)
+}
+
+func (d *DefaultNotifService) BatchGetByIds(ctx context.Context, ids ...uint64) (map[uint64]domain.Notification, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: notification ids should not be empty", errs.ErrInvalidParam)
+	}
+
+	notifications, err := d.repo.BatchGetByIds(ctx, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications by ids, cause of: %w", err)
+	}
+	return notifications, nil
+}
+
 func (d *DefaultNotifService) GetByBizKeys(ctx context.Context, BizId uint64, bizKeys ...string) ([]domain.Notification, error) {
 	if len(bizKeys) == 0 {
 		return nil, fmt.Errorf("%w: business keys should not be empty", errs.ErrInvalidParam)
@@ -39,8 +70,47 @@ func (d *DefaultNotifService) GetByBizKeys(ctx context.Context, BizId uint64, bi
 	return notifications, nil
 }
 
-func NewDefaultNotifService(repo repository.NotificationRepo) *DefaultNotifService {
+func (d *DefaultNotifService) Inspect(ctx context.Context, id uint64) (domain.IdInspection, error) {
+	if id == 0 {
+		return domain.IdInspection{}, fmt.Errorf("%w: notification id should not be zero", errs.ErrInvalidParam)
+	}
+
+	createdAt := snowflake.ExtractTimestamp(id)
+	if createdAt.After(time.Now().Add(time.Minute)) {
+		return domain.IdInspection{}, fmt.Errorf(
+			"%w: id %d is not generated by the snowflake generator, its time %s is in the future",
+			errs.ErrInvalidParam, id, createdAt.Format(time.RFC3339),
+		)
+	}
+
+	dst := d.shardingStrategy.ShardWithId(id)
+	res := domain.IdInspection{
+		Id:        id,
+		CreatedAt: createdAt,
+		Hash:      snowflake.ExtractHash(id),
+		WorkerId:  snowflake.ExtractWorkerId(id),
+		Sequence:  snowflake.ExtractSequence(id),
+		DB:        dst.DB,
+		Table:     dst.Table,
+	}
+
+	n, src, err := d.repo.LocateById(ctx, id)
+	switch {
+	case err == nil:
+		// the table may be the archive table of the shard.
+		res.DB, res.Table = src.DB, src.Table
+		res.Found = true
+		res.Notification = n
+	case errors.Is(err, errs.ErrNotificationNotFound):
+	default:
+		return domain.IdInspection{}, fmt.Errorf("failed to get notification from %s.%s, cause of: %w", dst.DB, dst.Table, err)
+	}
+	return res, nil
+}
+
+func NewDefaultNotifService(repo repository.NotificationRepo, shardingStrategy sharding.Strategy) *DefaultNotifService {
 	return &DefaultNotifService{
-		repo: repo,
+		repo:             repo,
+		shardingStrategy: shardingStrategy,
 	}
 }
@@ -2,14 +2,18 @@ package notification
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"golang.org/x/sync/errgroup"
 
 	"github.com/JrMarcco/jotice/internal/domain"
 	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/idempotent"
+	"github.com/JrMarcco/jotice/internal/repository"
+	"github.com/JrMarcco/jotice/internal/service/quota"
 	"github.com/JrMarcco/jotice/internal/service/sendstrategy"
-	"github.com/sony/sonyflake"
+	"go.uber.org/zap"
 )
 
 //go:generate mockgen -source=./types.go -destination=./mock/send_service.mock.go -package=notificationmock -type=SendService
@@ -20,12 +24,26 @@ type SendService interface {
 	BatchAsyncSend(ctx context.Context, ns []domain.Notification) (domain.BatchAsyncSendResp, error)
 }
 
+// IdGenerator generates the notification ids, e.g. snowflake.Generator.
+// The hash of the biz id and biz key should be embedded in the id, the notification is routed to its shard by the id.
+type IdGenerator interface {
+	NextId(bizId uint64, bizKey string) (uint64, error)
+}
+
 var _ SendService = (*DefaultSendService)(nil)
 
 type DefaultSendService struct {
-	idGenerator *sonyflake.Sonyflake
+	idGenerator IdGenerator
 
 	sendStrategy sendstrategy.SendStrategy
+	quotaSvc     quota.Service
+
+	// idempotent finds the retried sends of the same biz key fast,
+	// the unique constraint of (biz_id, biz_key) in db is the durable backstop.
+	idempotent idempotent.Strategy
+	repo       repository.NotificationRepo
+
+	logger *zap.Logger
 }
 
 // Send sync send notification immediately
@@ -40,15 +58,28 @@ func (s *DefaultSendService) Send(ctx context.Context, n domain.Notification) (d
 		return resp, err
 	}
 
-	id, err := s.idGenerator.NextID()
+	if existing, ok := s.findDuplicate(ctx, n); ok {
+		return existing, nil
+	}
+
+	id, err := s.idGenerator.NextId(n.BizId, n.BizKey)
 	if err != nil {
 		return resp, fmt.Errorf("failed to generate notification id, cause of: %w", err)
 	}
 
 	n.Id = id
 
+	quotaItems, err := s.quotaSvc.Deduct(ctx, n)
+	if err != nil {
+		return resp, err
+	}
+
 	sendResp, err := s.sendStrategy.Send(ctx, n)
 	if err != nil {
+		s.refundUnsent(ctx, quotaItems, err)
+		if errors.Is(err, errs.ErrDuplicateNotification) {
+			return s.getExisting(ctx, n)
+		}
 		return resp, fmt.Errorf("%w, cause of: %w", errs.ErrSendNotificationFailed, err)
 	}
 
@@ -61,7 +92,11 @@ func (s *DefaultSendService) AsyncSend(ctx context.Context, n domain.Notificatio
 		return domain.SendResp{}, err
 	}
 
-	id, err := s.idGenerator.NextID()
+	if existing, ok := s.findDuplicate(ctx, n); ok {
+		return existing, nil
+	}
+
+	id, err := s.idGenerator.NextId(n.BizId, n.BizKey)
 	if err != nil {
 		return domain.SendResp{}, fmt.Errorf("failed to generate notification id, cause of: %w", err)
 	}
@@ -71,7 +106,21 @@ func (s *DefaultSendService) AsyncSend(ctx context.Context, n domain.Notificatio
 	// if immediate strategy in async send method,
 	// replace strategy to deadline and set the deadline to 1 minute from now.
 	n.ReplaceAsyncImmediate()
-	return s.sendStrategy.Send(ctx, n)
+
+	quotaItems, err := s.quotaSvc.Deduct(ctx, n)
+	if err != nil {
+		return domain.SendResp{}, err
+	}
+
+	sendResp, err := s.sendStrategy.Send(ctx, n)
+	if err != nil {
+		s.refundUnsent(ctx, quotaItems, err)
+		if errors.Is(err, errs.ErrDuplicateNotification) {
+			return s.getExisting(ctx, n)
+		}
+		return domain.SendResp{}, err
+	}
+	return sendResp, nil
 }
 
 // BatchSend batch send notifications
@@ -82,28 +131,60 @@ func (s *DefaultSendService) BatchSend(ctx context.Context, ns []domain.Notifica
 		return resp, fmt.Errorf("%w: no notifications to send", errs.ErrInvalidParam)
 	}
 
-	for _, n := range ns {
-		if err := n.Validate(); err != nil {
+	for i := range ns {
+		if err := ns[i].Validate(); err != nil {
 			return resp, fmt.Errorf("%w: notification validation failed, cause of: %w", errs.ErrInvalidParam, err)
 		}
 
-		id, err := s.idGenerator.NextID()
+		id, err := s.idGenerator.NextId(ns[i].BizId, ns[i].BizKey)
 		if err != nil {
 			return resp, fmt.Errorf("failed to generate notification id, cause of: %w", err)
 		}
 
-		n.Id = id
+		ns[i].Id = id
+	}
+
+	quotaItems, err := s.quotaSvc.Deduct(ctx, ns...)
+	if err != nil {
+		return resp, err
 	}
 
 	results, err := s.sendStrategy.BatchSend(ctx, ns)
 	resp.Results = results.Results
+
+	// the batch may fail after some of the notifications are sent, which are reported in the results.
+	s.refundFailed(ctx, ns, results.Results, quotaItems)
 	if err != nil {
 		return resp, fmt.Errorf("%w, cause of: %w", errs.ErrSendNotificationFailed, err)
 	}
-
 	return resp, nil
 }
 
+// refundFailed refunds the quota of the notifications not reported as sent in the batch,
+// which are either failed or not sent at all.
+func (s *DefaultSendService) refundFailed(
+	ctx context.Context, ns []domain.Notification, results []domain.SendResult, quotaItems []domain.QuotaItem,
+) {
+	sentIds := make(map[uint64]struct{}, len(results))
+	for _, result := range results {
+		if result.Status != domain.SendStatusFailed {
+			sentIds[result.NotificationId] = struct{}{}
+		}
+	}
+
+	failed := make([]domain.Notification, 0, len(ns)-len(sentIds))
+	for _, n := range ns {
+		if _, ok := sentIds[n.Id]; !ok {
+			failed = append(failed, n)
+		}
+	}
+
+	if len(failed) == 0 {
+		return
+	}
+	s.refundQuota(ctx, domain.PartialQuotaItems(quotaItems, failed))
+}
+
 // BatchAsyncSend batch async send notifications
 func (s *DefaultSendService) BatchAsyncSend(ctx context.Context, ns []domain.Notification) (domain.BatchAsyncSendResp, error) {
 	if len(ns) == 0 {
@@ -111,19 +192,24 @@ func (s *DefaultSendService) BatchAsyncSend(ctx context.Context, ns []domain.Not
 	}
 
 	ids := make([]uint64, 0, len(ns))
-	for _, n := range ns {
-		if err := n.Validate(); err != nil {
+	for i := range ns {
+		if err := ns[i].Validate(); err != nil {
 			return domain.BatchAsyncSendResp{}, fmt.Errorf("%w: notification validation failed, cause of: %w", errs.ErrInvalidParam, err)
 		}
 
-		id, err := s.idGenerator.NextID()
+		id, err := s.idGenerator.NextId(ns[i].BizId, ns[i].BizKey)
 		if err != nil {
 			return domain.BatchAsyncSendResp{}, fmt.Errorf("failed to generate notification id, cause of: %w", err)
 		}
 
-		n.Id = id
+		ns[i].Id = id
 		ids = append(ids, id)
-		n.ReplaceAsyncImmediate()
+		ns[i].ReplaceAsyncImmediate()
+	}
+
+	quotaItems, err := s.quotaSvc.Deduct(ctx, ns...)
+	if err != nil {
+		return domain.BatchAsyncSendResp{}, err
 	}
 
 	// group notifications by strategy
@@ -133,13 +219,15 @@ func (s *DefaultSendService) BatchAsyncSend(ctx context.Context, ns []domain.Not
 		strategyGroups[strategy] = append(strategyGroups[strategy], n)
 	}
 
-	// Process each strategy group concurrently
-	eg, ctx := errgroup.WithContext(ctx)
+	// Process each strategy group concurrently,
+	// the groups are not canceled by the failure of the others, so only the failed groups are refunded.
+	var eg errgroup.Group
 	for _, groupNs := range strategyGroups {
 		notifications := groupNs
 		eg.Go(func() error {
 			_, err := s.sendStrategy.BatchSend(ctx, notifications)
 			if err != nil {
+				s.refundQuota(ctx, domain.PartialQuotaItems(quotaItems, notifications))
 				return fmt.Errorf("%w, cause of: %w", errs.ErrSendNotificationFailed, err)
 			}
 			return nil
@@ -155,8 +243,80 @@ func (s *DefaultSendService) BatchAsyncSend(ctx context.Context, ns []domain.Not
 	}, nil
 }
 
-func NewDefaultSendService(idGenerator *sonyflake.Sonyflake) *DefaultSendService {
+// findDuplicate returns the result of the existing notification if the biz key has been sent.
+//
+// The biz key is marked as seen even if the send fails before the notification is persisted,
+// so the send goes on if the existing notification is not found.
+//
+// The concurrent first attempts of the same biz key may all pass here,
+// only one of them is persisted by the unique constraint of (biz_id, biz_key) in db,
+// the others get errs.ErrDuplicateNotification from the send strategy and return the existing one by getExisting.
+func (s *DefaultSendService) findDuplicate(ctx context.Context, n domain.Notification) (domain.SendResp, bool) {
+	exists, err := s.idempotent.Exists(ctx, idempotent.Key(n.BizId, n.BizKey))
+	if err != nil {
+		s.logger.Warn(
+			"[jotice] failed to check idempotent key",
+			zap.Uint64("biz_id", n.BizId),
+			zap.String("biz_key", n.BizKey),
+			zap.Error(err),
+		)
+		return domain.SendResp{}, false
+	}
+
+	if !exists {
+		return domain.SendResp{}, false
+	}
+
+	resp, err := s.getExisting(ctx, n)
+	if err != nil {
+		return domain.SendResp{}, false
+	}
+	return resp, true
+}
+
+func (s *DefaultSendService) getExisting(ctx context.Context, n domain.Notification) (domain.SendResp, error) {
+	existing, err := s.repo.GetByBizKey(ctx, n.BizId, n.BizKey)
+	if err != nil {
+		return domain.SendResp{}, fmt.Errorf("failed to get existing notification, cause of: %w", err)
+	}
+
+	return domain.SendResp{
+		Result: domain.SendResult{
+			NotificationId: existing.Id,
+			Status:         existing.Status,
+		},
+	}, nil
+}
+
+// refundUnsent refunds the quota of the notification failed to send,
+// unless it has reached the provider, which takes the quota even if the provider fails.
+func (s *DefaultSendService) refundUnsent(ctx context.Context, items []domain.QuotaItem, err error) {
+	if errors.Is(err, errs.ErrProviderSendFailed) {
+		return
+	}
+	s.refundQuota(ctx, items)
+}
+
+// refundQuota refunds the quota of the notifications failed before reaching the provider.
+// The refund should not be skipped because the request is canceled, so it is not canceled with ctx.
+func (s *DefaultSendService) refundQuota(ctx context.Context, items []domain.QuotaItem) {
+	if err := s.quotaSvc.Refund(context.WithoutCancel(ctx), items); err != nil {
+		s.logger.Error("[jotice] failed to refund quota", zap.Error(err))
+	}
+}
+
+func NewDefaultSendService(
+	idGenerator IdGenerator,
+	quotaSvc quota.Service,
+	idempotent idempotent.Strategy,
+	repo repository.NotificationRepo,
+	logger *zap.Logger,
+) *DefaultSendService {
 	return &DefaultSendService{
 		idGenerator: idGenerator,
+		quotaSvc:    quotaSvc,
+		idempotent:  idempotent,
+		repo:        repo,
+		logger:      logger,
 	}
 }
@@ -0,0 +1,410 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/idempotent"
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"github.com/JrMarcco/jotice/internal/repository"
+	"github.com/JrMarcco/jotice/internal/repository/dao"
+	"github.com/JrMarcco/jotice/internal/service/quota"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// seqIdGenerator generates the ids in sequence from 1.
+type seqIdGenerator struct {
+	mu   sync.Mutex
+	next uint64
+}
+
+func (g *seqIdGenerator) NextId(_ uint64, _ string) (uint64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.next++
+	return g.next, nil
+}
+
+// fakeSendStrategy fails the notifications of which the biz key is in fails,
+// and fails the batches of the strategies in failStrategies.
+// On err, the batch reports the results of the notifications of which the biz key is in sent.
+type fakeSendStrategy struct {
+	fails          map[string]bool
+	failStrategies map[domain.SendStrategy]bool
+	sent           map[string]bool
+	err            error
+}
+
+func (f *fakeSendStrategy) Send(_ context.Context, n domain.Notification) (domain.SendResp, error) {
+	if f.err != nil {
+		return domain.SendResp{}, f.err
+	}
+	return domain.SendResp{Result: f.result(n)}, nil
+}
+
+func (f *fakeSendStrategy) BatchSend(_ context.Context, ns []domain.Notification) (domain.BatchSendResp, error) {
+	if f.failStrategies[ns[0].StrategyConfig.Type] {
+		return domain.BatchSendResp{}, errors.New("connection reset")
+	}
+
+	resp := domain.BatchSendResp{}
+	if f.err != nil {
+		for _, n := range ns {
+			if f.sent[n.BizKey] {
+				resp.Results = append(resp.Results, f.result(n))
+			}
+		}
+		return resp, f.err
+	}
+
+	for _, n := range ns {
+		resp.Results = append(resp.Results, f.result(n))
+	}
+	return resp, nil
+}
+
+func (f *fakeSendStrategy) result(n domain.Notification) domain.SendResult {
+	status := domain.SendStatusSuccess
+	if f.fails[n.BizKey] {
+		status = domain.SendStatusFailed
+	}
+	return domain.SendResult{NotificationId: n.Id, Status: status}
+}
+
+// fakeQuotaSvc deducts one item per biz and channel as the quota service does and records the refunded items.
+type fakeQuotaSvc struct {
+	quota.Service
+
+	mu       sync.Mutex
+	deducted int
+	refunded []domain.QuotaItem
+}
+
+func (f *fakeQuotaSvc) Deduct(_ context.Context, ns ...domain.Notification) ([]domain.QuotaItem, error) {
+	f.mu.Lock()
+	f.deducted++
+	f.mu.Unlock()
+
+	var items []domain.QuotaItem
+	for _, n := range ns {
+		idx := slices.IndexFunc(items, func(item domain.QuotaItem) bool {
+			return item.BizId == n.BizId && item.Channel == n.Channel
+		})
+		if idx < 0 {
+			items = append(items, domain.QuotaItem{BizId: n.BizId, Channel: n.Channel, Period: "d20250101", Limit: 100})
+			idx = len(items) - 1
+		}
+		items[idx].Amount += int64(len(n.Receivers))
+	}
+	return items, nil
+}
+
+func (f *fakeQuotaSvc) Refund(ctx context.Context, items []domain.QuotaItem) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// the refund is not canceled with the request
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	f.refunded = append(f.refunded, items...)
+	return nil
+}
+
+func (f *fakeQuotaSvc) getRefunded() []domain.QuotaItem {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]domain.QuotaItem(nil), f.refunded...)
+}
+
+// fakeIdempotent reports the keys in keys as seen.
+type fakeIdempotent struct {
+	keys map[string]bool
+	err  error
+}
+
+func (f *fakeIdempotent) Exists(_ context.Context, key string) (bool, error) {
+	return f.keys[key], f.err
+}
+
+func (f *fakeIdempotent) MultiExists(_ context.Context, keys []string) (map[string]bool, error) {
+	res := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		res[key] = f.keys[key]
+	}
+	return res, f.err
+}
+
+// fakeNotifRepo finds the notifications by id or biz key in memory, the other methods are not used.
+type fakeNotifRepo struct {
+	repository.NotificationRepo
+	ns  []domain.Notification
+	err error
+
+	strategy sharding.Strategy
+	archived map[uint64]struct{}
+}
+
+func (f *fakeNotifRepo) GetById(_ context.Context, id uint64) (domain.Notification, error) {
+	if f.err != nil {
+		return domain.Notification{}, f.err
+	}
+
+	for _, n := range f.ns {
+		if n.Id == id {
+			return n, nil
+		}
+	}
+	return domain.Notification{}, errs.ErrNotificationNotFound
+}
+
+// LocateById reads the notifications in archived from the archive table of their shards.
+func (f *fakeNotifRepo) LocateById(ctx context.Context, id uint64) (domain.Notification, sharding.Dst, error) {
+	n, err := f.GetById(ctx, id)
+	if err != nil {
+		return domain.Notification{}, sharding.Dst{}, err
+	}
+
+	dst := f.strategy.ShardWithId(id)
+	if _, ok := f.archived[id]; ok {
+		dst.Table = dao.ArchiveTable(dst)
+	}
+	return n, dst, nil
+}
+
+func (f *fakeNotifRepo) GetByBizKey(_ context.Context, bizId uint64, bizKey string) (domain.Notification, error) {
+	for _, n := range f.ns {
+		if n.BizId == bizId && n.BizKey == bizKey {
+			return n, nil
+		}
+	}
+	return domain.Notification{}, errs.ErrNotificationNotFound
+}
+
+func newTestNotification(bizKey string, receivers ...string) domain.Notification {
+	return domain.Notification{
+		BizId:     1,
+		BizKey:    bizKey,
+		Receivers: receivers,
+		Channel:   domain.ChannelSMS,
+		Template: domain.Template{
+			Id:        1,
+			VersionId: 1,
+			Params:    map[string]string{"code": "123456"},
+		},
+		StrategyConfig: domain.SendStrategyConfig{Type: domain.SendStrategyImmediate},
+	}
+}
+
+func newTestSendService(
+	strategy *fakeSendStrategy, quotaSvc *fakeQuotaSvc, idem *fakeIdempotent, repo *fakeNotifRepo,
+) *DefaultSendService {
+	svc := NewDefaultSendService(&seqIdGenerator{}, quotaSvc, idem, repo, zap.NewNop())
+	svc.sendStrategy = strategy
+	return svc
+}
+
+func TestDefaultSendService_BatchSend(t *testing.T) {
+	t.Parallel()
+
+	quotaSvc := &fakeQuotaSvc{}
+	svc := newTestSendService(
+		&fakeSendStrategy{fails: map[string]bool{"failed": true}}, quotaSvc, &fakeIdempotent{}, &fakeNotifRepo{},
+	)
+
+	resp, err := svc.BatchSend(t.Context(), []domain.Notification{
+		newTestNotification("succeed", "a"),
+		newTestNotification("failed", "b", "c"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SendResult{
+		{NotificationId: 1, Status: domain.SendStatusSuccess},
+		{NotificationId: 2, Status: domain.SendStatusFailed},
+	}, resp.Results)
+
+	// only the quota of the failed notification is refunded
+	assert.Equal(t, []domain.QuotaItem{
+		{BizId: 1, Channel: domain.ChannelSMS, Period: "d20250101", Limit: 100, Amount: 2},
+	}, quotaSvc.getRefunded())
+}
+
+func TestDefaultSendService_BatchSendPartialFailed(t *testing.T) {
+	t.Parallel()
+
+	quotaSvc := &fakeQuotaSvc{}
+	strategy := &fakeSendStrategy{sent: map[string]bool{"sent": true}, err: errors.New("connection reset")}
+	svc := newTestSendService(strategy, quotaSvc, &fakeIdempotent{}, &fakeNotifRepo{})
+
+	resp, err := svc.BatchSend(t.Context(), []domain.Notification{
+		newTestNotification("sent", "a"),
+		newTestNotification("unsent", "b", "c"),
+	})
+	assert.ErrorIs(t, err, errs.ErrSendNotificationFailed)
+	assert.Len(t, resp.Results, 1)
+
+	// the notification reported as sent keeps its quota
+	assert.Equal(t, []domain.QuotaItem{
+		{BizId: 1, Channel: domain.ChannelSMS, Period: "d20250101", Limit: 100, Amount: 2},
+	}, quotaSvc.getRefunded())
+}
+
+func TestDefaultSendService_BatchAsyncSendGroupFailed(t *testing.T) {
+	t.Parallel()
+
+	quotaSvc := &fakeQuotaSvc{}
+	strategy := &fakeSendStrategy{failStrategies: map[domain.SendStrategy]bool{domain.SendStrategyDelayed: true}}
+	svc := newTestSendService(strategy, quotaSvc, &fakeIdempotent{}, &fakeNotifRepo{})
+
+	delayed := newTestNotification("delayed", "b", "c")
+	delayed.StrategyConfig = domain.SendStrategyConfig{Type: domain.SendStrategyDelayed, Delay: time.Minute}
+
+	_, err := svc.BatchAsyncSend(t.Context(), []domain.Notification{newTestNotification("deadline", "a"), delayed})
+	assert.ErrorIs(t, err, errs.ErrSendNotificationFailed)
+
+	// only the quota of the failed strategy group is refunded
+	assert.Equal(t, []domain.QuotaItem{
+		{BizId: 1, Channel: domain.ChannelSMS, Period: "d20250101", Limit: 100, Amount: 2},
+	}, quotaSvc.getRefunded())
+}
+
+func TestDefaultSendService_SendProviderFailed(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name         string
+		err          error
+		wantRefunded int
+	}{
+		{
+			name:         "failed before provider",
+			err:          errs.ErrNoAvailableProvider,
+			wantRefunded: 1,
+		}, {
+			// the notification has reached the provider, so the quota is taken
+			name: "provider failed",
+			err:  fmt.Errorf("%w, cause of: gateway timeout", errs.ErrProviderSendFailed),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			quotaSvc := &fakeQuotaSvc{}
+			svc := newTestSendService(&fakeSendStrategy{err: tc.err}, quotaSvc, &fakeIdempotent{}, &fakeNotifRepo{})
+
+			_, err := svc.Send(t.Context(), newTestNotification("send", "a"))
+			assert.ErrorIs(t, err, tc.err)
+			_, err = svc.AsyncSend(t.Context(), newTestNotification("async_send", "a"))
+			assert.ErrorIs(t, err, tc.err)
+			assert.Len(t, quotaSvc.getRefunded(), 2*tc.wantRefunded)
+		})
+	}
+}
+
+func TestDefaultSendService_RefundWithCanceledContext(t *testing.T) {
+	t.Parallel()
+
+	quotaSvc := &fakeQuotaSvc{}
+	strategy := &fakeSendStrategy{err: context.Canceled}
+	svc := newTestSendService(strategy, quotaSvc, &fakeIdempotent{}, &fakeNotifRepo{})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err := svc.AsyncSend(ctx, newTestNotification("async", "a"))
+	assert.Error(t, err)
+	_, err = svc.BatchAsyncSend(ctx, []domain.Notification{newTestNotification("batch_async", "b")})
+	assert.Error(t, err)
+
+	// both refunds go through even though the request is canceled
+	assert.Len(t, quotaSvc.getRefunded(), 2)
+}
+
+func TestDefaultSendService_SendDuplicate(t *testing.T) {
+	t.Parallel()
+
+	existing := domain.Notification{Id: 100, BizId: 1, BizKey: "existing", Status: domain.SendStatusSuccess}
+	existingResp := domain.SendResp{Result: domain.SendResult{NotificationId: 100, Status: domain.SendStatusSuccess}}
+
+	tcs := []struct {
+		name         string
+		bizKey       string
+		idem         *fakeIdempotent
+		strategy     *fakeSendStrategy
+		wantResp     domain.SendResp
+		wantErr      error
+		wantDeducted int
+		wantRefunded int
+	}{
+		{
+			// the retry is found by the idempotent key without deducting the quota
+			name:     "retried send",
+			bizKey:   "existing",
+			idem:     &fakeIdempotent{keys: map[string]bool{idempotent.Key(1, "existing"): true}},
+			strategy: &fakeSendStrategy{},
+			wantResp: existingResp,
+		}, {
+			// the concurrent first attempts both pass the idempotent check, the db constraint rejects the latter
+			name:         "unique violation",
+			bizKey:       "existing",
+			idem:         &fakeIdempotent{},
+			strategy:     &fakeSendStrategy{err: fmt.Errorf("%w, cause of: unique violation", errs.ErrDuplicateNotification)},
+			wantResp:     existingResp,
+			wantDeducted: 1,
+			wantRefunded: 1,
+		}, {
+			// the key is seen but the first attempt failed before persisting, so the send goes on
+			name:         "seen but not persisted",
+			bizKey:       "failed_before",
+			idem:         &fakeIdempotent{keys: map[string]bool{idempotent.Key(1, "failed_before"): true}},
+			strategy:     &fakeSendStrategy{},
+			wantResp:     domain.SendResp{Result: domain.SendResult{NotificationId: 1, Status: domain.SendStatusSuccess}},
+			wantDeducted: 1,
+		}, {
+			name:         "idempotent unavailable",
+			bizKey:       "new",
+			idem:         &fakeIdempotent{err: errors.New("connection refused")},
+			strategy:     &fakeSendStrategy{},
+			wantResp:     domain.SendResp{Result: domain.SendResult{NotificationId: 1, Status: domain.SendStatusSuccess}},
+			wantDeducted: 1,
+		}, {
+			// the existing notification is not found after the unique violation
+			name:         "unique violation without existing",
+			bizKey:       "missing",
+			idem:         &fakeIdempotent{},
+			strategy:     &fakeSendStrategy{err: errs.ErrDuplicateNotification},
+			wantErr:      errs.ErrNotificationNotFound,
+			wantDeducted: 1,
+			wantRefunded: 1,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			quotaSvc := &fakeQuotaSvc{}
+			svc := newTestSendService(tc.strategy, quotaSvc, tc.idem, &fakeNotifRepo{ns: []domain.Notification{existing}})
+
+			resp, err := svc.Send(t.Context(), newTestNotification(tc.bizKey, "a"))
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.wantDeducted, quotaSvc.deducted)
+			assert.Len(t, quotaSvc.getRefunded(), tc.wantRefunded)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantResp, resp)
+		})
+	}
+}
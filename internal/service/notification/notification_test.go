@@ -0,0 +1,94 @@
+package notification
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/sharding"
+	"github.com/JrMarcco/jotice/internal/pkg/snowflake"
+	"github.com/JrMarcco/jotice/internal/repository/dao"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultNotifService_Inspect(t *testing.T) {
+	t.Parallel()
+
+	g, err := snowflake.NewWorkerGenerator(3, nil)
+	require.NoError(t, err)
+
+	before := time.Now().Truncate(time.Millisecond)
+	persisted, err := g.NextId(1, "persisted")
+	require.NoError(t, err)
+	missing, err := g.NextId(1, "missing")
+	require.NoError(t, err)
+	archived, err := g.NextId(1, "archived")
+	require.NoError(t, err)
+
+	n := newTestNotification("persisted")
+	n.Id = persisted
+	archivedN := newTestNotification("archived")
+	archivedN.Id = archived
+
+	strategy := sharding.NewHashStrategy("jotice", "notification", 2, 4)
+	errDB := errors.New("db down")
+
+	tcs := []struct {
+		name      string
+		id        uint64
+		repoErr   error
+		wantFound bool
+		wantTable func(dst sharding.Dst) string
+		wantErr   error
+	}{
+		{name: "found", id: persisted, wantFound: true},
+		{name: "archived", id: archived, wantFound: true, wantTable: dao.ArchiveTable},
+		{name: "not persisted", id: missing},
+		{name: "zero id", id: 0, wantErr: errs.ErrInvalidParam},
+		{name: "not a snowflake id", id: ^uint64(0) >> 1, wantErr: errs.ErrInvalidParam},
+		{name: "repo failed", id: persisted, repoErr: errDB, wantErr: errDB},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := &fakeNotifRepo{
+				ns:       []domain.Notification{n, archivedN},
+				err:      tc.repoErr,
+				strategy: strategy,
+				archived: map[uint64]struct{}{archived: {}},
+			}
+			svc := NewDefaultNotifService(repo, strategy)
+
+			res, err := svc.Inspect(t.Context(), tc.id)
+			assert.ErrorIs(t, err, tc.wantErr)
+			if err != nil {
+				return
+			}
+
+			// the id is decoded and routed by the strategy, the table is the one the notification is read from.
+			dst := strategy.ShardWithId(tc.id)
+			wantTable := dst.Table
+			if tc.wantTable != nil {
+				wantTable = tc.wantTable(dst)
+			}
+			assert.Equal(t, tc.id, res.Id)
+			assert.False(t, res.CreatedAt.Before(before))
+			assert.WithinDuration(t, time.Now(), res.CreatedAt, time.Second)
+			assert.Equal(t, snowflake.ExtractHash(tc.id), res.Hash)
+			assert.Equal(t, uint64(3), res.WorkerId)
+			assert.Equal(t, snowflake.ExtractSequence(tc.id), res.Sequence)
+			assert.Equal(t, dst.DB, res.DB)
+			assert.Equal(t, wantTable, res.Table)
+
+			assert.Equal(t, tc.wantFound, res.Found)
+			if tc.wantFound {
+				assert.Equal(t, tc.id, res.Notification.Id)
+			}
+		})
+	}
+}
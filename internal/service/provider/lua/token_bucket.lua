@@ -0,0 +1,32 @@
+-- Token bucket with daily limit of a provider.
+-- KEYS[1]: token bucket hash {tokens, ts}, KEYS[2]: daily usage hash {used, throttled}.
+-- The throttled count is left to the caller, so a request retrying the bucket is only counted once.
+-- ARGV[1]: rate (tokens per second), ARGV[2]: daily limit, ARGV[3]: expiration of daily usage in seconds.
+-- Returns {1, 0} if acquired, {0, wait_in_ms} if no token, {0, -1} if the daily limit is reached.
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local rate = tonumber(ARGV[1])
+local daily_limit = tonumber(ARGV[2])
+local daily_expiration = tonumber(ARGV[3])
+
+local used = tonumber(redis.call('HGET', KEYS[2], 'used') or '0')
+if daily_limit > 0 and used >= daily_limit then
+    return { 0, -1 }
+end
+
+-- the capacity is one second of tokens
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or rate
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(rate, tokens + math.max(now - ts, 0) * rate / 1000)
+
+if tokens < 1 then
+    return { 0, math.ceil((1 - tokens) * 1000 / rate) }
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens - 1), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], 2000)
+redis.call('HINCRBY', KEYS[2], 'used', 1)
+redis.call('EXPIRE', KEYS[2], daily_expiration)
+return { 1, 0 }
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// dailyUsageExpiration keeps the daily usage a little longer than a day, so the usage of yesterday is observable.
+const dailyUsageExpiration = 48 * time.Hour
+
+var (
+	//go:embed lua/token_bucket.lua
+	tokenBucketLua string
+
+	tokenBucketScript = redis.NewScript(tokenBucketLua)
+)
+
+// ThrottleResult is the result of acquiring a token of the provider.
+type ThrottleResult struct {
+	Acquired bool
+	// Wait is the duration to wait for the next token, only set if not acquired.
+	Wait time.Duration
+	// DailyExhausted is true if the daily limit of the provider is reached, waiting does not help.
+	DailyExhausted bool
+}
+
+// Throttler limits the calls to the providers to their contractual limits (Provider.QpsLimit and Provider.DailyLimit).
+// The limits are cluster-wide.
+type Throttler interface {
+	// TryAcquire acquires a token of the provider without waiting.
+	TryAcquire(ctx context.Context, p domain.Provider) (ThrottleResult, error)
+	// Throttled counts a request throttled by the provider, it should be called once per request
+	// no matter how many times the request tried to acquire.
+	Throttled(ctx context.Context, p domain.Provider) error
+	// Usage returns the token usage of the provider today.
+	Usage(ctx context.Context, p domain.Provider) (domain.ProviderUsage, error)
+}
+
+var _ Throttler = (*RedisThrottler)(nil)
+
+// RedisThrottler is a distributed token bucket per provider in redis.
+type RedisThrottler struct {
+	rdb redis.Cmdable
+}
+
+func (r *RedisThrottler) TryAcquire(ctx context.Context, p domain.Provider) (ThrottleResult, error) {
+	if p.QpsLimit <= 0 {
+		return ThrottleResult{}, fmt.Errorf("qps limit of provider %d should be greater than 0", p.Id)
+	}
+
+	keys := []string{bucketKey(p.Id), dailyUsageKey(p.Id, time.Now())}
+	res, err := tokenBucketScript.Run(
+		ctx, r.rdb, keys, p.QpsLimit, p.DailyLimit, int64(dailyUsageExpiration/time.Second),
+	).Int64Slice()
+	if err != nil {
+		return ThrottleResult{}, err
+	}
+
+	switch {
+	case res[0] == 1:
+		return ThrottleResult{Acquired: true}, nil
+	case res[1] < 0:
+		return ThrottleResult{DailyExhausted: true}, nil
+	default:
+		return ThrottleResult{Wait: time.Duration(res[1]) * time.Millisecond}, nil
+	}
+}
+
+func (r *RedisThrottler) Throttled(ctx context.Context, p domain.Provider) error {
+	key := dailyUsageKey(p.Id, time.Now())
+
+	pipe := r.rdb.TxPipeline()
+	pipe.HIncrBy(ctx, key, "throttled", 1)
+	pipe.Expire(ctx, key, dailyUsageExpiration)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisThrottler) Usage(ctx context.Context, p domain.Provider) (domain.ProviderUsage, error) {
+	vals, err := r.rdb.HMGet(ctx, dailyUsageKey(p.Id, time.Now()), "used", "throttled").Result()
+	if err != nil {
+		return domain.ProviderUsage{}, err
+	}
+
+	usage := domain.ProviderUsage{
+		ProviderId: p.Id,
+		QpsLimit:   p.QpsLimit,
+		DailyLimit: p.DailyLimit,
+	}
+	if usage.DailyUsed, err = parseCount(vals[0]); err != nil {
+		return domain.ProviderUsage{}, err
+	}
+	if usage.DailyThrottled, err = parseCount(vals[1]); err != nil {
+		return domain.ProviderUsage{}, err
+	}
+	return usage, nil
+}
+
+func parseCount(val any) (int64, error) {
+	str, ok := val.(string)
+	if !ok {
+		return 0, nil
+	}
+	return strconv.ParseInt(str, 10, 64)
+}
+
+// the provider id is used as hash tag, so the keys of a provider are in the same slot of a redis cluster.
+func bucketKey(providerId uint64) string {
+	return fmt.Sprintf("provider:{%d}:bucket", providerId)
+}
+
+func dailyUsageKey(providerId uint64, t time.Time) string {
+	return fmt.Sprintf("provider:{%d}:%s", providerId, t.Format("20060102"))
+}
+
+func NewRedisThrottler(rdb redis.Cmdable) *RedisThrottler {
+	return &RedisThrottler{
+		rdb: rdb,
+	}
+}
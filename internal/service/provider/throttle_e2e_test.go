@@ -0,0 +1,66 @@
+//go:build e2e
+
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisThrottler(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     "192.168.3.3:6379",
+		Password: "<passwd>",
+	})
+
+	p := domain.Provider{Id: 10086, QpsLimit: 2, DailyLimit: 3}
+	defer func() {
+		client.Del(ctx, bucketKey(p.Id), dailyUsageKey(p.Id, time.Now()))
+		_ = client.Close()
+	}()
+
+	throttler := NewRedisThrottler(client)
+
+	// the bucket holds one second of tokens
+	for range 2 {
+		res, err := throttler.TryAcquire(ctx, p)
+		require.NoError(t, err)
+		assert.True(t, res.Acquired)
+	}
+
+	res, err := throttler.TryAcquire(ctx, p)
+	require.NoError(t, err)
+	assert.False(t, res.Acquired)
+	assert.Positive(t, res.Wait)
+	require.NoError(t, throttler.Throttled(ctx, p))
+
+	time.Sleep(res.Wait)
+	res, err = throttler.TryAcquire(ctx, p)
+	require.NoError(t, err)
+	assert.True(t, res.Acquired)
+
+	// the daily limit is reached
+	time.Sleep(time.Second)
+	res, err = throttler.TryAcquire(ctx, p)
+	require.NoError(t, err)
+	assert.True(t, res.DailyExhausted)
+
+	usage, err := throttler.Usage(ctx, p)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderUsage{
+		ProviderId:     p.Id,
+		QpsLimit:       2,
+		DailyLimit:     3,
+		DailyUsed:      3,
+		DailyThrottled: 1,
+	}, usage)
+}
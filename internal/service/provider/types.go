@@ -0,0 +1,14 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+)
+
+// Provider sends notifications through a vendor.
+type Provider interface {
+	// Info returns the provider info, including its contractual limits.
+	Info() domain.Provider
+	Send(ctx context.Context, n domain.Notification) (domain.SendResp, error)
+}
@@ -0,0 +1,324 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/pkg/xmq"
+	"github.com/JrMarcco/jotice/internal/repository"
+	"github.com/JrMarcco/jotice/internal/service/config"
+	"go.uber.org/zap"
+)
+
+type Service interface {
+	// Deduct deducts one quota per receiver on the channel of each notification before dispatching.
+	// The notifications are deducted all or nothing, returns errs.ErrQuotaExhausted if any quota is exhausted.
+	// The returned items should be refunded if the notifications fail before reaching the provider.
+	Deduct(ctx context.Context, ns ...domain.Notification) ([]domain.QuotaItem, error)
+	// Refund gives back the quota deducted.
+	Refund(ctx context.Context, items []domain.QuotaItem) error
+
+	// GetReport returns the used and remaining daily and monthly quota of each channel of the biz.
+	GetReport(ctx context.Context, bizId uint64) ([]domain.QuotaReport, error)
+	// ListDailyUsages lists the daily usages of the biz in the month, for month-end reconciliation.
+	ListDailyUsages(ctx context.Context, bizId uint64, month time.Time) ([]domain.QuotaUsage, error)
+}
+
+// quotaChannels are the channels with quota.
+var quotaChannels = []domain.Channel{domain.ChannelSMS, domain.ChannelEmail}
+
+const alertTimeout = 3 * time.Second
+
+var _ Service = (*DefaultQuotaService)(nil)
+
+type DefaultQuotaService struct {
+	configSvc config.Service
+	repo      repository.QuotaRepo
+	producer  xmq.Producer[domain.QuotaAlertEvent]
+	logger    *zap.Logger
+}
+
+func (s *DefaultQuotaService) Deduct(ctx context.Context, ns ...domain.Notification) ([]domain.QuotaItem, error) {
+	items, quotaConfigs, err := s.buildItems(ctx, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	used, err := s.repo.Deduct(ctx, items)
+	if err != nil {
+		if errors.Is(err, errs.ErrQuotaExhausted) {
+			s.alertRejected(items, quotaConfigs)
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to deduct quota, cause of: %w", err)
+	}
+
+	s.alertDeducted(items, used, quotaConfigs)
+	return items, nil
+}
+
+// alertDeducted emits the alerts of the thresholds crossed by the deduction.
+func (s *DefaultQuotaService) alertDeducted(
+	items []domain.QuotaItem, used []int64, quotaConfigs map[uint64]*domain.QuotaConfig,
+) {
+	events := s.crossedEvents(items, quotaConfigs, func(i int) (int64, int64, int64) {
+		return used[i] - items[i].Amount, used[i], used[i]
+	})
+	if len(events) == 0 {
+		return
+	}
+
+	// the alert should not slow down the send.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+		defer cancel()
+		s.emit(ctx, events)
+	}()
+}
+
+// alertRejected emits the alerts of the thresholds the rejected deduction would cross,
+// e.g. the 100% alert when the remaining quota is not enough for the batch.
+func (s *DefaultQuotaService) alertRejected(items []domain.QuotaItem, quotaConfigs map[uint64]*domain.QuotaConfig) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+		defer cancel()
+
+		keys := make([]domain.QuotaUsage, 0, len(items))
+		for _, item := range items {
+			keys = append(keys, domain.QuotaUsage{BizId: item.BizId, Channel: item.Channel, Period: item.Period})
+		}
+
+		// the usages are in the same order as the items.
+		usages, err := s.repo.GetUsages(ctx, keys)
+		if err != nil {
+			s.logger.Error("[jotice] failed to get quota usages for alert", zap.Error(err))
+			return
+		}
+
+		// the rejected deduction is not taken, the used quota stays.
+		events := s.crossedEvents(items, quotaConfigs, func(i int) (int64, int64, int64) {
+			return usages[i].Used, usages[i].Used + items[i].Amount, usages[i].Used
+		})
+		s.emit(ctx, events)
+	}()
+}
+
+// crossedEvents builds the alert events of the thresholds crossed by the used quota of each item going from before to after,
+// the events carry the used quota of the item.
+func (s *DefaultQuotaService) crossedEvents(
+	items []domain.QuotaItem, quotaConfigs map[uint64]*domain.QuotaConfig, usedOf func(i int) (before, after, used int64),
+) []domain.QuotaAlertEvent {
+	now := time.Now().UnixMilli()
+
+	var events []domain.QuotaAlertEvent
+	for i, item := range items {
+		before, after, used := usedOf(i)
+		thresholds := quotaConfigs[item.BizId].Thresholds()
+		for _, threshold := range domain.CrossedThresholds(thresholds, item.Limit, before, after) {
+			events = append(events, domain.QuotaAlertEvent{
+				BizId:     item.BizId,
+				Channel:   item.Channel,
+				Period:    item.Period,
+				Threshold: threshold,
+				Limit:     item.Limit,
+				Used:      used,
+				AlertAt:   now,
+			})
+		}
+	}
+	return events
+}
+
+// emit emits the alerts not emitted yet in their periods.
+// The used quota crosses a threshold again after a refund, the claim in redis keeps the alert from repeating.
+func (s *DefaultQuotaService) emit(ctx context.Context, events []domain.QuotaAlertEvent) {
+	for _, event := range events {
+		logger := s.logger.With(
+			zap.Uint64("biz_id", event.BizId),
+			zap.String("channel", event.Channel.String()),
+			zap.String("period", event.Period),
+			zap.Int32("threshold", event.Threshold),
+		)
+
+		claimed, err := s.repo.ClaimAlert(ctx, event)
+		if err != nil {
+			// a duplicate alert is better than a missing one.
+			logger.Warn("[jotice] failed to claim quota alert", zap.Error(err))
+			claimed = true
+		}
+		if !claimed {
+			continue
+		}
+
+		if err = s.producer.Produce(ctx, event); err != nil {
+			logger.Error("[jotice] failed to emit quota alert", zap.Error(err))
+			if err = s.repo.ReleaseAlert(ctx, event); err != nil {
+				logger.Warn("[jotice] failed to release quota alert", zap.Error(err))
+			}
+		}
+	}
+}
+
+// buildItems sums the receivers of the notifications by biz and channel,
+// and builds the daily and monthly quota items of which the limit is configured.
+func (s *DefaultQuotaService) buildItems(
+	ctx context.Context, ns []domain.Notification,
+) ([]domain.QuotaItem, map[uint64]*domain.QuotaConfig, error) {
+	type groupKey struct {
+		bizId   uint64
+		channel domain.Channel
+	}
+
+	amounts := make(map[groupKey]int64)
+	keys := make([]groupKey, 0, len(ns))
+	for _, n := range ns {
+		key := groupKey{bizId: n.BizId, channel: n.Channel}
+		if _, ok := amounts[key]; !ok {
+			keys = append(keys, key)
+		}
+		amounts[key] += int64(len(n.Receivers))
+	}
+
+	now := time.Now()
+	quotaConfigs := make(map[uint64]*domain.QuotaConfig)
+
+	var items []domain.QuotaItem
+	for _, key := range keys {
+		quotaConfig, ok := quotaConfigs[key.bizId]
+		if !ok {
+			var err error
+			if quotaConfig, err = s.getConfig(ctx, key.bizId); err != nil {
+				return nil, nil, err
+			}
+			quotaConfigs[key.bizId] = quotaConfig
+		}
+
+		if quotaConfig == nil {
+			continue
+		}
+
+		daily, monthly := quotaConfig.Limits(key.channel)
+		if daily > 0 {
+			items = append(items, domain.QuotaItem{
+				BizId:   key.bizId,
+				Channel: key.channel,
+				Period:  domain.DailyQuotaPeriod(now),
+				Limit:   int64(daily),
+				Amount:  amounts[key],
+			})
+		}
+		if monthly > 0 {
+			items = append(items, domain.QuotaItem{
+				BizId:   key.bizId,
+				Channel: key.channel,
+				Period:  domain.MonthlyQuotaPeriod(now),
+				Limit:   int64(monthly),
+				Amount:  amounts[key],
+			})
+		}
+	}
+	return items, quotaConfigs, nil
+}
+
+// getConfig gets the quota config of the business.
+// Returns nil if the business has not configured the quota.
+func (s *DefaultQuotaService) getConfig(ctx context.Context, bizId uint64) (*domain.QuotaConfig, error) {
+	bizConfig, err := s.configSvc.GetById(ctx, bizId)
+	if err != nil {
+		if errors.Is(err, errs.ErrBizConfigNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return bizConfig.QuotaConfig, nil
+}
+
+func (s *DefaultQuotaService) Refund(ctx context.Context, items []domain.QuotaItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	if err := s.repo.Refund(ctx, items); err != nil {
+		return fmt.Errorf("failed to refund quota, cause of: %w", err)
+	}
+	return nil
+}
+
+func (s *DefaultQuotaService) GetReport(ctx context.Context, bizId uint64) ([]domain.QuotaReport, error) {
+	if bizId <= 0 {
+		return nil, fmt.Errorf("%w: biz id should not be negative or zero", errs.ErrInvalidParam)
+	}
+
+	quotaConfig, err := s.getConfig(ctx, bizId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	dailyPeriod, monthlyPeriod := domain.DailyQuotaPeriod(now), domain.MonthlyQuotaPeriod(now)
+
+	keys := make([]domain.QuotaUsage, 0, 2*len(quotaChannels))
+	for _, channel := range quotaChannels {
+		keys = append(keys,
+			domain.QuotaUsage{BizId: bizId, Channel: channel, Period: dailyPeriod},
+			domain.QuotaUsage{BizId: bizId, Channel: channel, Period: monthlyPeriod},
+		)
+	}
+
+	usages, err := s.repo.GetUsages(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quota usages, cause of: %w", err)
+	}
+
+	// the usages are in the same order as the keys: daily and monthly of each channel.
+	reports := make([]domain.QuotaReport, 0, len(quotaChannels))
+	for i, channel := range quotaChannels {
+		report := domain.QuotaReport{
+			BizId:       bizId,
+			Channel:     channel,
+			DailyUsed:   usages[2*i].Used,
+			MonthlyUsed: usages[2*i+1].Used,
+		}
+
+		if quotaConfig != nil {
+			daily, monthly := quotaConfig.Limits(channel)
+			report.DailyLimit, report.MonthlyLimit = int64(daily), int64(monthly)
+		}
+		report.DailyRemaining = max(report.DailyLimit-report.DailyUsed, 0)
+		report.MonthlyRemaining = max(report.MonthlyLimit-report.MonthlyUsed, 0)
+
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func (s *DefaultQuotaService) ListDailyUsages(
+	ctx context.Context, bizId uint64, month time.Time,
+) ([]domain.QuotaUsage, error) {
+	if bizId <= 0 {
+		return nil, fmt.Errorf("%w: biz id should not be negative or zero", errs.ErrInvalidParam)
+	}
+	return s.repo.ListDailyUsages(ctx, bizId, month)
+}
+
+func NewDefaultQuotaService(
+	configSvc config.Service,
+	repo repository.QuotaRepo,
+	producer xmq.Producer[domain.QuotaAlertEvent],
+	logger *zap.Logger,
+) *DefaultQuotaService {
+	return &DefaultQuotaService{
+		configSvc: configSvc,
+		repo:      repo,
+		producer:  producer,
+		logger:    logger,
+	}
+}
@@ -0,0 +1,291 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/errs"
+	"github.com/JrMarcco/jotice/internal/repository"
+	"github.com/JrMarcco/jotice/internal/service/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeConfigSvc returns the biz configs in memory, the other methods are not used.
+type fakeConfigSvc struct {
+	config.Service
+	configs map[uint64]domain.BizConfig
+}
+
+func (f *fakeConfigSvc) GetById(_ context.Context, id uint64) (domain.BizConfig, error) {
+	bc, ok := f.configs[id]
+	if !ok {
+		return domain.BizConfig{}, errs.ErrBizConfigNotFound
+	}
+	return bc, nil
+}
+
+// fakeQuotaRepo records the items deducted and refunded, and returns the used quota of the deduction.
+type fakeQuotaRepo struct {
+	repository.QuotaRepo
+
+	mu       sync.Mutex
+	deducted []domain.QuotaItem
+	refunded []domain.QuotaItem
+	used     []int64
+	err      error
+	usages   map[string]int64
+	syncs    int
+	alerts   map[string]bool
+}
+
+func (f *fakeQuotaRepo) Deduct(_ context.Context, items []domain.QuotaItem) ([]int64, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.deducted = append(f.deducted, items...)
+	return f.used, nil
+}
+
+func (f *fakeQuotaRepo) Refund(_ context.Context, items []domain.QuotaItem) error {
+	f.refunded = append(f.refunded, items...)
+	return nil
+}
+
+func (f *fakeQuotaRepo) GetUsages(_ context.Context, keys []domain.QuotaUsage) ([]domain.QuotaUsage, error) {
+	res := make([]domain.QuotaUsage, 0, len(keys))
+	for _, key := range keys {
+		key.Used = f.usages[key.Channel.String()+":"+key.Period]
+		res = append(res, key)
+	}
+	return res, nil
+}
+
+func (f *fakeQuotaRepo) Sync(_ context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.syncs++
+	return 0, nil
+}
+
+func (f *fakeQuotaRepo) ClaimAlert(_ context.Context, event domain.QuotaAlertEvent) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := fmt.Sprintf("%d:%s:%s:%d", event.BizId, event.Channel, event.Period, event.Threshold)
+	if f.alerts[key] {
+		return false, nil
+	}
+	f.alerts[key] = true
+	return true, nil
+}
+
+func (f *fakeQuotaRepo) ReleaseAlert(_ context.Context, event domain.QuotaAlertEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.alerts, fmt.Sprintf("%d:%s:%s:%d", event.BizId, event.Channel, event.Period, event.Threshold))
+	return nil
+}
+
+func (f *fakeQuotaRepo) getAlerts() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.alerts)
+}
+
+func (f *fakeQuotaRepo) getSyncs() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.syncs
+}
+
+// fakeProducer records the events produced.
+type fakeProducer struct {
+	mu     sync.Mutex
+	events []domain.QuotaAlertEvent
+	err    error
+}
+
+func (f *fakeProducer) Produce(_ context.Context, event domain.QuotaAlertEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+	f.events = append(f.events, event)
+	return nil
+}
+
+func (f *fakeProducer) getEvents() []domain.QuotaAlertEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]domain.QuotaAlertEvent(nil), f.events...)
+}
+
+func (f *fakeProducer) Close() {}
+
+func newTestConfigSvc() *fakeConfigSvc {
+	return &fakeConfigSvc{
+		configs: map[uint64]domain.BizConfig{
+			1: {
+				Id: 1,
+				QuotaConfig: &domain.QuotaConfig{
+					Daily:   &domain.DailyQuotaConfig{SMS: 10},
+					Monthly: &domain.MonthlyQuotaConfig{SMS: 100, Email: 100},
+				},
+			},
+			// biz 2 has not configured the quota
+			2: {Id: 2},
+		},
+	}
+}
+
+func TestDefaultQuotaService_Deduct(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	daily, monthly := domain.DailyQuotaPeriod(now), domain.MonthlyQuotaPeriod(now)
+
+	repo := &fakeQuotaRepo{used: []int64{3, 3, 1}, alerts: make(map[string]bool)}
+	svc := NewDefaultQuotaService(newTestConfigSvc(), repo, &fakeProducer{}, zap.NewNop())
+
+	items, err := svc.Deduct(t.Context(),
+		domain.Notification{BizId: 1, Channel: domain.ChannelSMS, Receivers: []string{"a", "b"}},
+		domain.Notification{BizId: 1, Channel: domain.ChannelSMS, Receivers: []string{"c"}},
+		domain.Notification{BizId: 1, Channel: domain.ChannelEmail, Receivers: []string{"d"}},
+		domain.Notification{BizId: 2, Channel: domain.ChannelSMS, Receivers: []string{"e"}},
+	)
+	require.NoError(t, err)
+
+	// one quota per receiver, the limits not configured are not deducted
+	want := []domain.QuotaItem{
+		{BizId: 1, Channel: domain.ChannelSMS, Period: daily, Limit: 10, Amount: 3},
+		{BizId: 1, Channel: domain.ChannelSMS, Period: monthly, Limit: 100, Amount: 3},
+		{BizId: 1, Channel: domain.ChannelEmail, Period: monthly, Limit: 100, Amount: 1},
+	}
+	assert.Equal(t, want, items)
+	assert.Equal(t, want, repo.deducted)
+
+	require.NoError(t, svc.Refund(t.Context(), items))
+	assert.Equal(t, want, repo.refunded)
+
+	// nothing to deduct without quota config
+	items, err = svc.Deduct(t.Context(), domain.Notification{BizId: 2, Channel: domain.ChannelSMS, Receivers: []string{"a"}})
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	repo.err = errs.ErrQuotaExhausted
+	_, err = svc.Deduct(t.Context(), domain.Notification{BizId: 1, Channel: domain.ChannelSMS, Receivers: []string{"a"}})
+	assert.ErrorIs(t, err, errs.ErrQuotaExhausted)
+}
+
+func TestDefaultQuotaService_GetReport(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	daily, monthly := domain.DailyQuotaPeriod(now), domain.MonthlyQuotaPeriod(now)
+
+	repo := &fakeQuotaRepo{
+		usages: map[string]int64{
+			"sms:" + daily:     12,
+			"sms:" + monthly:   40,
+			"email:" + monthly: 5,
+		},
+	}
+	svc := NewDefaultQuotaService(newTestConfigSvc(), repo, &fakeProducer{}, zap.NewNop())
+
+	reports, err := svc.GetReport(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.QuotaReport{
+		{
+			BizId:   1,
+			Channel: domain.ChannelSMS,
+			// the used quota may exceed the limit lowered later
+			DailyLimit: 10, DailyUsed: 12, DailyRemaining: 0,
+			MonthlyLimit: 100, MonthlyUsed: 40, MonthlyRemaining: 60,
+		}, {
+			BizId:      1,
+			Channel:    domain.ChannelEmail,
+			DailyLimit: 0, DailyUsed: 0, DailyRemaining: 0,
+			MonthlyLimit: 100, MonthlyUsed: 5, MonthlyRemaining: 95,
+		},
+	}, reports)
+
+	_, err = svc.GetReport(t.Context(), 0)
+	assert.ErrorIs(t, err, errs.ErrInvalidParam)
+}
+
+func TestDefaultQuotaService_Alert(t *testing.T) {
+	t.Parallel()
+
+	daily := domain.DailyQuotaPeriod(time.Now())
+	configSvc := &fakeConfigSvc{
+		configs: map[uint64]domain.BizConfig{
+			1: {Id: 1, QuotaConfig: &domain.QuotaConfig{Daily: &domain.DailyQuotaConfig{SMS: 10}}},
+		},
+	}
+
+	repo := &fakeQuotaRepo{used: []int64{8}, alerts: make(map[string]bool)}
+	producer := &fakeProducer{}
+	svc := NewDefaultQuotaService(configSvc, repo, producer, zap.NewNop())
+
+	n := domain.Notification{BizId: 1, Channel: domain.ChannelSMS, Receivers: []string{"a"}}
+
+	// 7 -> 8 crosses the 80% threshold
+	items, err := svc.Deduct(t.Context(), n)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(producer.getEvents()) == 1 }, time.Second, 10*time.Millisecond)
+
+	// crossing again after a refund does not alert again
+	require.NoError(t, svc.Refund(t.Context(), items))
+	_, err = svc.Deduct(t.Context(), n)
+	require.NoError(t, err)
+
+	// the rejected deduction 9 -> 11 alerts the 100% threshold, with the used quota not taken
+	repo.err = errs.ErrQuotaExhausted
+	repo.usages = map[string]int64{"sms:" + daily: 9}
+	_, err = svc.Deduct(t.Context(), domain.Notification{BizId: 1, Channel: domain.ChannelSMS, Receivers: []string{"a", "b"}})
+	require.ErrorIs(t, err, errs.ErrQuotaExhausted)
+
+	require.Eventually(t, func() bool { return len(producer.getEvents()) == 2 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	events := producer.getEvents()
+	require.Len(t, events, 2)
+	assert.Equal(t, int32(80), events[0].Threshold)
+	assert.Equal(t, int64(8), events[0].Used)
+	assert.Equal(t, int32(100), events[1].Threshold)
+	assert.Equal(t, int64(9), events[1].Used)
+	assert.Equal(t, daily, events[1].Period)
+}
+
+func TestDefaultQuotaService_AlertFailed(t *testing.T) {
+	t.Parallel()
+
+	configSvc := &fakeConfigSvc{
+		configs: map[uint64]domain.BizConfig{
+			1: {Id: 1, QuotaConfig: &domain.QuotaConfig{Daily: &domain.DailyQuotaConfig{SMS: 10}}},
+		},
+	}
+
+	repo := &fakeQuotaRepo{used: []int64{8}, alerts: make(map[string]bool)}
+	producer := &fakeProducer{err: errors.New("broker unavailable")}
+	svc := NewDefaultQuotaService(configSvc, repo, producer, zap.NewNop())
+
+	_, err := svc.Deduct(t.Context(), domain.Notification{BizId: 1, Channel: domain.ChannelSMS, Receivers: []string{"a"}})
+	require.NoError(t, err)
+
+	// the alert failed to emit is released, so the next crossing can emit it
+	time.Sleep(50 * time.Millisecond)
+	assert.Zero(t, repo.getAlerts())
+	assert.Empty(t, producer.getEvents())
+}
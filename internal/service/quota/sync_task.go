@@ -0,0 +1,45 @@
+package quota
+
+import (
+	"context"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/repository"
+	"go.uber.org/zap"
+)
+
+// SyncTask is a background task that persists the quota counters in redis to db periodically,
+// so the quota counters lost in redis can be recovered from db.
+type SyncTask struct {
+	repo     repository.QuotaRepo
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func (t *SyncTask) Start(ctx context.Context) {
+	go t.loop(ctx)
+}
+
+func (t *SyncTask) loop(ctx context.Context) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := t.repo.Sync(ctx); err != nil {
+				t.logger.Error("[jotice] failed to sync quota usages", zap.Error(err))
+			}
+		}
+	}
+}
+
+func NewSyncTask(repo repository.QuotaRepo, interval time.Duration, logger *zap.Logger) *SyncTask {
+	return &SyncTask{
+		repo:     repo,
+		interval: interval,
+		logger:   logger,
+	}
+}
@@ -0,0 +1,31 @@
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSyncTask_Start(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeQuotaRepo{}
+	task := NewSyncTask(repo, 10*time.Millisecond, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(t.Context())
+	task.Start(ctx)
+
+	assert.Eventually(t, func() bool {
+		return repo.getSyncs() >= 2
+	}, time.Second, 10*time.Millisecond)
+
+	// no more sync after the task is stopped
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	syncs := repo.getSyncs()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, syncs, repo.getSyncs())
+}
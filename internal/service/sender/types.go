@@ -2,12 +2,15 @@ package sender
 
 import (
 	"context"
+	"slices"
 
 	"github.com/JrMarcco/jotice/internal/domain"
 	"github.com/JrMarcco/jotice/internal/repository"
 	"github.com/JrMarcco/jotice/internal/service/channel"
 	"github.com/JrMarcco/jotice/internal/service/config"
 	"github.com/JrMarcco/jotice/internal/service/notification/callback"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
 //go:generate mockgen -source=./type.go -destination=./mock/sender.mock.go -package=sendermock -type=Sender
@@ -16,9 +19,100 @@ type Sender interface {
 	BatchSend(ctx context.Context, ns []domain.Notification) ([]domain.SendResp, error)
 }
 
+var _ Sender = (*DefaultSender)(nil)
+
+// DefaultSender sends the notifications through their channels and records the terminal statuses.
+// The results are delivered to the business by the callback service right after the statuses are recorded,
+// the callbacks failed here are retried by the callback retry task.
 type DefaultSender struct {
 	repo         repository.NotificationRepo
 	bizConfigSvc config.Service
 	callbackSvc  callback.Service
 	channel      channel.Channel
+
+	logger *zap.Logger
+}
+
+// Send returns the error of the channel with the failed result.
+func (d *DefaultSender) Send(ctx context.Context, n domain.Notification) (domain.SendResp, error) {
+	_, err := d.channel.Send(ctx, n)
+	n.Status = terminalStatus(err)
+
+	d.finish(ctx, n)
+	return domain.SendResp{
+		Result: domain.SendResult{
+			NotificationId: n.Id,
+			Status:         n.Status,
+		},
+	}, err
+}
+
+// BatchSend sends the notifications concurrently, the failures are reported in the results.
+func (d *DefaultSender) BatchSend(ctx context.Context, ns []domain.Notification) ([]domain.SendResp, error) {
+	// the statuses are set on a copy, which is still used by the callbacks in background.
+	ns = slices.Clone(ns)
+	resps := make([]domain.SendResp, len(ns))
+
+	var eg errgroup.Group
+	for i := range ns {
+		eg.Go(func() error {
+			_, err := d.channel.Send(ctx, ns[i])
+			if err != nil {
+				d.logger.Warn("[jotice] failed to send notification", zap.Uint64("notification_id", ns[i].Id), zap.Error(err))
+			}
+
+			ns[i].Status = terminalStatus(err)
+			resps[i] = domain.SendResp{
+				Result: domain.SendResult{
+					NotificationId: ns[i].Id,
+					Status:         ns[i].Status,
+				},
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	d.finish(ctx, ns...)
+	return resps, nil
+}
+
+// finish records the terminal statuses of the notifications, then sends their results to the business in background,
+// so the send is not delayed by the business.
+// The callbacks are not sent if the statuses are not recorded, the business would get a result the query does not show.
+func (d *DefaultSender) finish(ctx context.Context, ns ...domain.Notification) {
+	ctx = context.WithoutCancel(ctx)
+	if err := d.repo.BatchUpdateStatus(ctx, ns); err != nil {
+		d.logger.Error("[jotice] failed to update notification status", zap.Int("count", len(ns)), zap.Error(err))
+		return
+	}
+
+	go func() {
+		if err := d.callbackSvc.SendCallbackByNotifications(ctx, ns); err != nil {
+			d.logger.Warn("[jotice] failed to send callbacks", zap.Int("count", len(ns)), zap.Error(err))
+		}
+	}()
+}
+
+func terminalStatus(err error) domain.SendStatus {
+	if err != nil {
+		return domain.SendStatusFailed
+	}
+	return domain.SendStatusSuccess
+}
+
+func NewDefaultSender(
+	repo repository.NotificationRepo,
+	bizConfigSvc config.Service,
+	callbackSvc callback.Service,
+	channel channel.Channel,
+	logger *zap.Logger,
+) *DefaultSender {
+	return &DefaultSender{
+		repo:         repo,
+		bizConfigSvc: bizConfigSvc,
+		callbackSvc:  callbackSvc,
+		channel:      channel,
+		logger:       logger,
+	}
 }
@@ -0,0 +1,123 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/repository"
+	"github.com/JrMarcco/jotice/internal/service/notification/callback"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var errFakeChannel = errors.New("fake channel")
+
+// fakeChannel fails the notifications in fails.
+type fakeChannel struct {
+	fails map[uint64]bool
+}
+
+func (f *fakeChannel) Send(_ context.Context, n domain.Notification) (domain.SendResp, error) {
+	if f.fails[n.Id] {
+		return domain.SendResp{}, errFakeChannel
+	}
+	return domain.SendResp{Result: domain.SendResult{NotificationId: n.Id, Status: domain.SendStatusSuccess}}, nil
+}
+
+// fakeNotifRepo records the statuses updated, the other methods are not used.
+type fakeNotifRepo struct {
+	repository.NotificationRepo
+
+	mu       sync.Mutex
+	statuses map[uint64]domain.SendStatus
+	err      error
+}
+
+func (f *fakeNotifRepo) BatchUpdateStatus(_ context.Context, ns []domain.Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+	for _, n := range ns {
+		f.statuses[n.Id] = n.Status
+	}
+	return nil
+}
+
+// fakeCallbackSvc sends the notifications of the callbacks to sent, the other methods are not used.
+type fakeCallbackSvc struct {
+	callback.Service
+	sent chan []domain.Notification
+}
+
+func (f *fakeCallbackSvc) SendCallbackByNotifications(_ context.Context, ns []domain.Notification) error {
+	f.sent <- ns
+	return nil
+}
+
+func TestDefaultSender(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeNotifRepo{statuses: make(map[uint64]domain.SendStatus)}
+	cbSvc := &fakeCallbackSvc{sent: make(chan []domain.Notification, 2)}
+	sender := NewDefaultSender(repo, nil, cbSvc, &fakeChannel{fails: map[uint64]bool{2: true}}, zap.NewNop())
+
+	resp, err := sender.Send(t.Context(), domain.Notification{Id: 1, Status: domain.SendStatusSending})
+	require.NoError(t, err)
+	assert.Equal(t, domain.SendResult{NotificationId: 1, Status: domain.SendStatusSuccess}, resp.Result)
+
+	resps, err := sender.BatchSend(t.Context(), []domain.Notification{
+		{Id: 2, Status: domain.SendStatusSending},
+		{Id: 3, Status: domain.SendStatusSending},
+	})
+	require.NoError(t, err)
+	require.Len(t, resps, 2)
+	assert.Equal(t, domain.SendResult{NotificationId: 2, Status: domain.SendStatusFailed}, resps[0].Result)
+	assert.Equal(t, domain.SendResult{NotificationId: 3, Status: domain.SendStatusSuccess}, resps[1].Result)
+
+	assert.Equal(t, map[uint64]domain.SendStatus{
+		1: domain.SendStatusSuccess,
+		2: domain.SendStatusFailed,
+		3: domain.SendStatusSuccess,
+	}, repo.statuses)
+
+	// the callbacks are sent with the terminal statuses right after the send.
+	sent := make(map[uint64]domain.SendStatus)
+	for range 2 {
+		select {
+		case ns := <-cbSvc.sent:
+			for _, n := range ns {
+				sent[n.Id] = n.Status
+			}
+		case <-time.After(time.Second):
+			require.FailNow(t, "callbacks are not sent")
+		}
+	}
+	assert.Equal(t, repo.statuses, sent)
+}
+
+func TestDefaultSender_UpdateStatusFailed(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeNotifRepo{statuses: make(map[uint64]domain.SendStatus), err: errors.New("fake repo")}
+	cbSvc := &fakeCallbackSvc{sent: make(chan []domain.Notification, 1)}
+	sender := NewDefaultSender(repo, nil, cbSvc, &fakeChannel{fails: map[uint64]bool{1: true}}, zap.NewNop())
+
+	resp, err := sender.Send(t.Context(), domain.Notification{Id: 1, Status: domain.SendStatusSending})
+	assert.ErrorIs(t, err, errFakeChannel)
+	assert.Equal(t, domain.SendStatusFailed, resp.Result.Status)
+
+	// the callback is left to the retry task, which sends it once the status is terminal in db.
+	select {
+	case <-cbSvc.sent:
+		assert.Fail(t, "callback is sent without the status recorded")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -3,21 +3,79 @@ package sendstrategy
 import (
 	"context"
 
-	"github.com/JrMarcco/jotice/internal/repository"
-
 	"github.com/JrMarcco/jotice/internal/domain"
+	"github.com/JrMarcco/jotice/internal/repository"
+	"github.com/JrMarcco/jotice/internal/service/sender"
 )
 
 var _ SendStrategy = (*ImmediateSendStrategy)(nil)
 
+// ImmediateSendStrategy persists the notifications in sending status with their callback logs, then sends them at once.
 type ImmediateSendStrategy struct {
-	repo repository.NotificationRepo
+	repo   repository.NotificationRepo
+	sender sender.Sender
 }
 
+// Send returns errs.ErrDuplicateNotification if the biz key of the biz already exists.
 func (s *ImmediateSendStrategy) Send(ctx context.Context, n domain.Notification) (domain.SendResp, error) {
-	panic("not implemented")
+	n.SetSendTime()
+	n.Status = domain.SendStatusSending
+
+	created, err := s.repo.CreateWithCallbackLog(ctx, n)
+	if err != nil {
+		return domain.SendResp{}, err
+	}
+	return s.sender.Send(ctx, created)
 }
 
+// BatchSend does not send the notifications of which the biz key exists again, their current statuses are returned.
 func (s *ImmediateSendStrategy) BatchSend(ctx context.Context, ns []domain.Notification) (domain.BatchSendResp, error) {
-	panic("not implemented")
+	for i := range ns {
+		ns[i].SetSendTime()
+		ns[i].Status = domain.SendStatusSending
+	}
+
+	created, err := s.repo.BatchCreateWithCallbackLog(ctx, ns)
+	if err != nil {
+		return domain.BatchSendResp{}, err
+	}
+
+	// the existing ones are created by the previous try of the batch, they are not sent again.
+	toSend := make([]domain.Notification, 0, len(created))
+	for i, n := range created {
+		if n.Id == ns[i].Id {
+			toSend = append(toSend, n)
+		}
+	}
+
+	sent := make(map[uint64]domain.SendResult, len(toSend))
+	if len(toSend) > 0 {
+		resps, err := s.sender.BatchSend(ctx, toSend)
+		if err != nil {
+			return domain.BatchSendResp{}, err
+		}
+		for _, resp := range resps {
+			sent[resp.Result.NotificationId] = resp.Result
+		}
+	}
+
+	// the results are in the order requested, the existing ones are reported in their current statuses.
+	results := make([]domain.SendResult, 0, len(created))
+	for i, n := range created {
+		if n.Id != ns[i].Id {
+			results = append(results, domain.SendResult{NotificationId: n.Id, Status: n.Status})
+			continue
+		}
+		if result, ok := sent[n.Id]; ok {
+			results = append(results, result)
+		}
+	}
+	return domain.BatchSendResp{Results: results}, nil
+}
+
+func NewImmediateSendStrategy(repo repository.NotificationRepo, sender sender.Sender) *ImmediateSendStrategy {
+	return &ImmediateSendStrategy{
+		repo:   repo,
+		sender: sender,
+	}
 }
@@ -1,13 +1,19 @@
+-- 仅用于本地快速初始化，完整的表结构（含分库分表）由 jotice migrate 根据 internal/repository/schema/sql 创建和升级
+
 CREATE DATABASE jotice WITH ENCODING = 'UTF8';
 
-CREATE TYPE callback_status AS ENUM ('init', 'pending', 'succeeded', 'failed');
+CREATE TYPE callback_status AS ENUM ('init', 'pending', 'succeed', 'failed');
 CREATE TABLE callback_log
 (
-    id              BIGINT PRIMARY KEY,
+    id              BIGSERIAL PRIMARY KEY,
     notification_id BIGINT          NOT NULL UNIQUE,         -- 等待回调通知的 id
+    biz_id          BIGINT          NOT NULL,                -- 业务方 id
+    biz_key         VARCHAR(256)    NOT NULL,                -- 业务方唯一标识
     retry_times     SMALLINT        NOT NULL DEFAULT 0,      -- 重试次数
     next_retry_at   BIGINT          NOT NULL DEFAULT 0,      -- 下次重试时间戳（秒）
     status          callback_status NOT NULL DEFAULT 'init', -- 回调状态
+    last_error      TEXT            NOT NULL DEFAULT '',     -- 最近一次回调失败的原因
+    attempts        JSONB           NOT NULL DEFAULT '[]',   -- 最近的回调记录
     created_at      BIGINT,
     updated_at      BIGINT
 );
@@ -15,10 +21,109 @@ CREATE TABLE callback_log
 COMMENT
 ON COLUMN callback_log.notification_id IS '等待回调通知的 id';
 COMMENT
+ON COLUMN callback_log.biz_id IS '业务方 id';
+COMMENT
+ON COLUMN callback_log.biz_key IS '业务方唯一标识';
+COMMENT
 ON COLUMN callback_log.retry_times IS '重试次数';
 COMMENT
 ON COLUMN callback_log.next_retry_at IS '下次重试时间戳（秒）';
 COMMENT
 ON COLUMN callback_log.status IS '回调状态';
+COMMENT
+ON COLUMN callback_log.last_error IS '最近一次回调失败的原因';
+COMMENT
+ON COLUMN callback_log.attempts IS '最近的回调记录';
 
 CREATE INDEX idx_status_create_at ON callback_log(status, created_at);
+CREATE INDEX idx_biz_id_status_updated_at ON callback_log(biz_id, status, updated_at);
+
+CREATE TABLE biz_config
+(
+    id              BIGINT PRIMARY KEY,                 -- 业务方 id
+    owner_id        BIGINT      NOT NULL,               -- 业务方所属者 id
+    owner_type      VARCHAR(32) NOT NULL,               -- 业务方所属者类型
+    channel_config  JSONB,                              -- 渠道配置
+    tx_notif_config JSONB,                              -- 事务消息配置
+    rate_limit      INTEGER     NOT NULL DEFAULT 1000,  -- 每秒最大请求数
+    quota_config    JSONB,                              -- 配额配置
+    callback_config JSONB,                              -- 回调配置
+    created_at      BIGINT,
+    updated_at      BIGINT
+);
+
+COMMENT
+ON COLUMN biz_config.owner_id IS '业务方所属者 id';
+COMMENT
+ON COLUMN biz_config.owner_type IS '业务方所属者类型';
+COMMENT
+ON COLUMN biz_config.channel_config IS '渠道配置';
+COMMENT
+ON COLUMN biz_config.tx_notif_config IS '事务消息配置';
+COMMENT
+ON COLUMN biz_config.rate_limit IS '每秒最大请求数';
+COMMENT
+ON COLUMN biz_config.quota_config IS '配额配置';
+COMMENT
+ON COLUMN biz_config.callback_config IS '回调配置';
+
+CREATE TABLE biz_config_revision
+(
+    id              BIGSERIAL PRIMARY KEY,
+    biz_id          BIGINT       NOT NULL,              -- 业务方 id
+    version         INTEGER      NOT NULL,              -- 版本号
+    owner_id        BIGINT       NOT NULL,
+    owner_type      VARCHAR(32)  NOT NULL,
+    channel_config  JSONB,
+    tx_notif_config JSONB,
+    rate_limit      INTEGER      NOT NULL,
+    quota_config    JSONB,
+    callback_config JSONB,
+    author          VARCHAR(128) NOT NULL DEFAULT '',   -- 修改人
+    created_at      BIGINT
+);
+
+CREATE UNIQUE INDEX uk_biz_id_version ON biz_config_revision(biz_id, version);
+
+COMMENT
+ON COLUMN biz_config_revision.biz_id IS '业务方 id';
+COMMENT
+ON COLUMN biz_config_revision.version IS '版本号，同一业务方内递增';
+COMMENT
+ON COLUMN biz_config_revision.author IS '修改人';
+
+CREATE TABLE quota_usage
+(
+    id         BIGSERIAL PRIMARY KEY,
+    biz_id     BIGINT      NOT NULL,            -- 业务方 id
+    channel    VARCHAR(16) NOT NULL,            -- 渠道
+    period     VARCHAR(16) NOT NULL,            -- 周期
+    used       BIGINT      NOT NULL DEFAULT 0,  -- 已用配额
+    created_at BIGINT,
+    updated_at BIGINT
+);
+
+CREATE UNIQUE INDEX uk_biz_id_channel_period ON quota_usage(biz_id, channel, period);
+
+COMMENT
+ON COLUMN quota_usage.biz_id IS '业务方 id';
+COMMENT
+ON COLUMN quota_usage.channel IS '渠道';
+COMMENT
+ON COLUMN quota_usage.period IS '周期，d20250101 为日配额，m202501 为月配额';
+COMMENT
+ON COLUMN quota_usage.used IS '已用配额，由 redis 计数定期同步';
+
+-- 幂等键分表模板，分库分表规则与 notification 一致，表名为 idempotent_key_{n}
+CREATE TABLE idempotent_key
+(
+    biz_id     BIGINT       NOT NULL, -- 业务方 id
+    biz_key    VARCHAR(256) NOT NULL, -- 业务方唯一标识
+    created_at BIGINT,
+    PRIMARY KEY (biz_id, biz_key)
+);
+
+COMMENT
+ON COLUMN idempotent_key.biz_id IS '业务方 id';
+COMMENT
+ON COLUMN idempotent_key.biz_key IS '业务方唯一标识';